@@ -0,0 +1,47 @@
+// Package apitest містить спільні допоміжні функції для тестів сервісів:
+// базу SQLite у пам'яті з застосованими міграціями та виконання HTTP-запитів.
+package apitest
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+)
+
+// SQLite відкриває базу SQLite у пам'яті та застосовує всі міграції.
+// База закривається після завершення тесту.
+func SQLite(t testing.TB) (*sql.DB, dialect.Dialect) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// Кожне з'єднання з :memory: відкриває окрему порожню базу
+	db.SetMaxOpenConns(1)
+
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return db, d
+}
+
+// Do виконує запит з тілом body до обробника h та повертає записану відповідь
+func Do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rr
+}
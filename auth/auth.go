@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/user"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Service видає, оновлює та відкликає токени доступу
+type Service struct {
+	Users      *user.UserService
+	Tokens     TokenRepository
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// OnLogin, якщо задано, викликається після успішного входу, наприклад для
+	// перенесення анонімного кошика; помилка лише журналюється
+	OnLogin func(ctx context.Context, u user.User, input LoginInput) error
+}
+
+// LoginInput — тіло запиту /auth/login
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// CartID — необов'язковий анонімний кошик, який слід перенести користувачу
+	CartID string `json:"cart_id,omitempty"`
+}
+
+// RefreshInput — тіло запитів /auth/refresh та /auth/logout
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPair — відповідь з новими токенами
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// errUnauthorized повертається, якщо токен відсутній, невідомий, прострочений чи відкликаний
+var errUnauthorized = problem.New(http.StatusUnauthorized, "missing or invalid access token")
+
+// errInvalidRefresh повертається, якщо refresh-токен не можна використати
+var errInvalidRefresh = problem.New(http.StatusUnauthorized, "invalid or expired refresh token")
+
+// issue видає нову пару токенів у вказаній сім'ї
+func (s *Service) issue(ctx context.Context, userID int, familyID string) (TokenPair, error) {
+	now := time.Now().UTC()
+	access, err := s.create(ctx, userID, KindAccess, familyID, now.Add(s.AccessTTL))
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := s.create(ctx, userID, KindRefresh, familyID, now.Add(s.RefreshTTL))
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(s.AccessTTL / time.Second),
+		RefreshToken:     refresh,
+		RefreshExpiresIn: int(s.RefreshTTL / time.Second),
+	}, nil
+}
+
+// create зберігає хеш нового токена та повертає сам токен
+func (s *Service) create(ctx context.Context, userID int, kind, familyID string, expiresAt time.Time) (string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+	t := Token{UserID: userID, Kind: kind, Hash: hashSecret(secret), FamilyID: familyID, ExpiresAt: expiresAt}
+	if err := s.Tokens.Create(ctx, &t); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Login перевіряє email та пароль і видає нову пару токенів
+func (s *Service) Login(w http.ResponseWriter, r *http.Request) {
+	var input LoginInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.Required("email", input.Email)
+	v.Required("password", input.Password)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	u, err := s.Users.Authenticate(r.Context(), input.Email, input.Password)
+	if err != nil {
+		if err == user.ErrInvalidCredentials {
+			problem.Write(w, r, problem.New(http.StatusUnauthorized, "invalid email or password"))
+		} else {
+			log.Println("Error authenticating user:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	if s.OnLogin != nil {
+		if err := s.OnLogin(r.Context(), u, input); err != nil {
+			log.Println("Error running login hook:", err)
+		}
+	}
+
+	familyID, err := newFamilyID()
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	pair, err := s.issue(r.Context(), u.ID, familyID)
+	if err != nil {
+		log.Println("Error issuing tokens:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, pair)
+}
+
+// Refresh обмінює refresh-токен на нову пару токенів. Використаний токен
+// відкликається; його повторне пред'явлення вважається викраденням і
+// відкликає всю сім'ю токенів цієї сесії.
+func (s *Service) Refresh(w http.ResponseWriter, r *http.Request) {
+	var input RefreshInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	t, err := s.Tokens.GetByHash(ctx, hashSecret(input.RefreshToken))
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, errInvalidRefresh)
+		} else {
+			log.Println("Error querying refresh token:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	now := time.Now().UTC()
+	if t.Kind != KindRefresh || t.Expired(now) {
+		problem.Write(w, r, errInvalidRefresh)
+		return
+	}
+
+	rotated := false
+	if !t.Revoked() {
+		if rotated, err = s.Tokens.Revoke(ctx, t.ID, now); err != nil {
+			log.Println("Error revoking refresh token:", err)
+			problem.Error(w, r, err)
+			return
+		}
+	}
+	if !rotated {
+		// Токен уже використано: відкликаємо сесію цілком
+		if err := s.Tokens.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+			log.Println("Error revoking token family:", err)
+		}
+		problem.Write(w, r, errInvalidRefresh)
+		return
+	}
+
+	pair, err := s.issue(ctx, t.UserID, t.FamilyID)
+	if err != nil {
+		log.Println("Error issuing tokens:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, pair)
+}
+
+// Logout відкликає сесію, до якої належить refresh-токен. Невідомий чи
+// вже відкликаний токен не є помилкою.
+func (s *Service) Logout(w http.ResponseWriter, r *http.Request) {
+	var input RefreshInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	t, err := s.Tokens.GetByHash(ctx, hashSecret(input.RefreshToken))
+	if err != nil && err != ErrNotFound {
+		log.Println("Error querying refresh token:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	if err == nil && t.Kind == KindRefresh {
+		if err := s.Tokens.RevokeFamily(ctx, t.FamilyID, time.Now().UTC()); err != nil {
+			log.Println("Error revoking token family:", err)
+			problem.Error(w, r, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Middleware перевіряє токен із заголовка Authorization: Bearer та додає
+// користувача до контексту запиту. Запити без заголовка пропускаються без
+// користувача; недійсний токен завжди відхиляється з 401.
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret, ok := bearer(header)
+		if !ok {
+			Unauthorized(w, r)
+			return
+		}
+		u, err := s.authenticate(r.Context(), secret)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) || errors.Is(err, user.ErrNotFound) {
+				Unauthorized(w, r)
+			} else {
+				log.Println("Error authenticating token:", err)
+				problem.Error(w, r, err)
+			}
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), u)))
+	})
+}
+
+// authenticate повертає власника чинного access-токена або ErrNotFound
+func (s *Service) authenticate(ctx context.Context, secret string) (user.User, error) {
+	t, err := s.Tokens.GetByHash(ctx, hashSecret(secret))
+	if err != nil {
+		return user.User{}, err
+	}
+	if t.Kind != KindAccess || t.Revoked() || t.Expired(time.Now().UTC()) {
+		return user.User{}, ErrNotFound
+	}
+	return s.Users.Repo.Get(ctx, t.UserID)
+}
+
+// RequireUser відповідає 401, якщо Middleware не додав користувача до контексту
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := UserFrom(r.Context()); !ok {
+			Unauthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearer повертає токен із заголовка Authorization
+func bearer(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Unauthorized відповідає 401 з заголовком WWW-Authenticate
+func Unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="shop"`)
+	problem.Write(w, r, errUnauthorized)
+}
+
+type contextKey struct{}
+
+// WithUser повертає контекст з автентифікованим користувачем
+func WithUser(ctx context.Context, u user.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// UserFrom повертає автентифікованого користувача запиту
+func UserFrom(ctx context.Context) (user.User, bool) {
+	u, ok := ctx.Value(contextKey{}).(user.User)
+	return u, ok
+}
@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/user"
+)
+
+func newRouter(svc *auth.Service) chi.Router {
+	r := chi.NewRouter()
+	r.Use(svc.Middleware)
+	r.Post("/auth/login", svc.Login)
+	r.Post("/auth/refresh", svc.Refresh)
+	r.Post("/auth/logout", svc.Logout)
+	r.With(auth.RequireUser).Get("/me", func(w http.ResponseWriter, r *http.Request) {
+		u, _ := auth.UserFrom(r.Context())
+		w.Write([]byte(u.Email))
+	})
+	return r
+}
+
+func post(r http.Handler, path, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	return rr
+}
+
+func me(r http.Handler, token string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/me", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func tokens(t *testing.T, rr *httptest.ResponseRecorder) auth.TokenPair {
+	var pair auth.TokenPair
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pair))
+	return pair
+}
+
+func TestAuthFlowMemory(t *testing.T) {
+	testAuthFlow(t, user.NewMemoryRepository(), auth.NewMemoryRepository())
+}
+
+func TestAuthFlowSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	testAuthFlow(t, user.NewSQLRepository(db, d), auth.NewSQLRepository(db, d))
+}
+
+func testAuthFlow(t *testing.T, users user.UserRepository, repo auth.TokenRepository) {
+	hasher := user.Hasher{Cost: bcrypt.MinCost}
+	hash, err := hasher.Hash("secret123")
+	assert.NoError(t, err)
+	assert.NoError(t, users.Create(context.Background(), &user.User{FirstName: "John", LastName: "Doe", Email: "john@example.com", PasswordHash: hash}))
+
+	r := newRouter(&auth.Service{
+		Users:      &user.UserService{Repo: users, Hasher: hasher},
+		Tokens:     repo,
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+
+	// Без токена захищений маршрут недоступний, недійсний токен відхиляється
+	assert.Equal(t, http.StatusUnauthorized, me(r, "").Code)
+	rr := me(r, "bogus")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+
+	// Неправильний пароль
+	rr = post(r, "/auth/login", `{"email":"john@example.com","password":"wrong-password"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// Вхід видає пару токенів
+	rr = post(r, "/auth/login", `{"email":"john@example.com","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	first := tokens(t, rr)
+	assert.Equal(t, "Bearer", first.TokenType)
+	assert.Equal(t, 60, first.ExpiresIn)
+
+	rr = me(r, first.AccessToken)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "john@example.com", rr.Body.String())
+
+	// refresh-токен не приймається як access-токен
+	assert.Equal(t, http.StatusUnauthorized, me(r, first.RefreshToken).Code)
+
+	// Оновлення видає нову пару
+	rr = post(r, "/auth/refresh", `{"refresh_token":"`+first.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	second := tokens(t, rr)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, http.StatusOK, me(r, second.AccessToken).Code)
+
+	// Повторне використання старого refresh-токена відкликає всю сесію
+	rr = post(r, "/auth/refresh", `{"refresh_token":"`+first.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, http.StatusUnauthorized, me(r, second.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, post(r, "/auth/refresh", `{"refresh_token":"`+second.RefreshToken+`"}`).Code)
+
+	// Вихід відкликає токени сесії
+	third := tokens(t, post(r, "/auth/login", `{"email":"john@example.com","password":"secret123"}`))
+	assert.Equal(t, http.StatusOK, me(r, third.AccessToken).Code)
+	assert.Equal(t, http.StatusNoContent, post(r, "/auth/logout", `{"refresh_token":"`+third.RefreshToken+`"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, me(r, third.AccessToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, post(r, "/auth/refresh", `{"refresh_token":"`+third.RefreshToken+`"}`).Code)
+
+	// Повторний вихід не є помилкою
+	assert.Equal(t, http.StatusNoContent, post(r, "/auth/logout", `{"refresh_token":"`+third.RefreshToken+`"}`).Code)
+}
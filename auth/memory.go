@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRepository зберігає токени у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]Token
+	nextID int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{tokens: make(map[int]Token), nextID: 1}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, t *Token) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t.ID = m.nextID
+	t.CreatedAt = time.Now().UTC()
+	m.tokens[t.ID] = *t
+	m.nextID++
+	return nil
+}
+
+func (m *MemoryRepository) GetByHash(ctx context.Context, hash string) (Token, error) {
+	if err := ctx.Err(); err != nil {
+		return Token{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, t := range m.tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return Token{}, ErrNotFound
+}
+
+func (m *MemoryRepository) Revoke(ctx context.Context, id int, at time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tokens[id]
+	if !ok || t.Revoked() {
+		return false, nil
+	}
+	t.RevokedAt = &at
+	m.tokens[id] = t
+	return true, nil
+}
+
+func (m *MemoryRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, t := range m.tokens {
+		if t.FamilyID == familyID && !t.Revoked() {
+			t.RevokedAt = &at
+			m.tokens[id] = t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound повертається репозиторієм, якщо токена з таким хешем не існує
+var ErrNotFound = errors.New("auth: token not found")
+
+// TokenRepository описує сховище виданих токенів
+type TokenRepository interface {
+	// Create зберігає новий токен та заповнює ID і дату створення
+	Create(ctx context.Context, t *Token) error
+	// GetByHash повертає токен за хешем або ErrNotFound
+	GetByHash(ctx context.Context, hash string) (Token, error)
+	// Revoke відкликає токен за ID і повідомляє, чи саме цей виклик його відкликав;
+	// false означає, що токен уже було відкликано раніше
+	Revoke(ctx context.Context, id int, at time.Time) (bool, error)
+	// RevokeFamily відкликає всі ще чинні токени сім'ї
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+// SQLRepository зберігає токени у таблиці auth_tokens SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+func (m *SQLRepository) Create(ctx context.Context, t *Token) error {
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, m.DB, "INSERT INTO auth_tokens (user_id, kind, token_hash, family_id, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		t.UserID, t.Kind, t.Hash, t.FamilyID, t.ExpiresAt.UTC(), now)
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+	t.CreatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) GetByHash(ctx context.Context, hash string) (Token, error) {
+	var (
+		t         Token
+		revokedAt sql.NullTime
+	)
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT id, user_id, kind, token_hash, family_id, expires_at, revoked_at, created_at FROM auth_tokens WHERE token_hash=?"), hash).
+		Scan(&t.ID, &t.UserID, &t.Kind, &t.Hash, &t.FamilyID, &t.ExpiresAt, &revokedAt, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return Token{}, ErrNotFound
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return t, nil
+}
+
+func (m *SQLRepository) Revoke(ctx context.Context, id int, at time.Time) (bool, error) {
+	// Умова revoked_at IS NULL гарантує, що з двох одночасних ротацій виграє лише одна
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE auth_tokens SET revoked_at=? WHERE id=? AND revoked_at IS NULL"), at.UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (m *SQLRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE auth_tokens SET revoked_at=? WHERE family_id=? AND revoked_at IS NULL"), at.UTC(), familyID)
+	return err
+}
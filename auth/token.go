@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Види токенів
+const (
+	KindAccess  = "access"
+	KindRefresh = "refresh"
+)
+
+// Token — виданий користувачеві непрозорий токен. Сам токен клієнт отримує
+// лише один раз; у сховищі зберігається тільки його SHA-256 хеш.
+// Токени однієї сесії входу мають спільний FamilyID: ротація refresh-токена
+// продовжує сім'ю, а вихід чи повторне використання відкликає її цілком.
+type Token struct {
+	ID        int
+	UserID    int
+	Kind      string
+	Hash      string
+	FamilyID  string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Revoked повідомляє, чи токен відкликано
+func (t Token) Revoked() bool {
+	return t.RevokedAt != nil
+}
+
+// Expired повідомляє, чи строк дії токена минув на момент now
+func (t Token) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// newSecret генерує випадковий токен для клієнта
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newFamilyID генерує ідентифікатор сім'ї токенів
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecret повертає хеш токена, під яким він зберігається
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,421 @@
+// Package cart реалізує кошики покупців: анонімні та прив'язані до користувача.
+package cart
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Cart представляє кошик. ID випадковий, тож знання ID анонімного кошика
+// є правом доступу до нього; кошик користувача доступний лише власнику.
+type Cart struct {
+	ID        string      `json:"id"`
+	UserID    *int        `json:"userID,omitempty"`
+	Items     []Item      `json:"items"`
+	Total     money.Money `json:"total"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Item — позиція кошика. Зберігаються лише ProductID та Quantity; решта
+// полів щоразу обчислюється з поточних даних продукту.
+type Item struct {
+	ProductID int         `json:"productID"`
+	Quantity  int         `json:"quantity"`
+	Name      string      `json:"name"`
+	UnitPrice money.Money `json:"unitPrice"`
+	LineTotal money.Money `json:"lineTotal"`
+	// Available дорівнює false, якщо продукт видалено, його залишку не
+	// вистачає або ціна вказана в іншій валюті; така позиція не входить до суми
+	Available bool `json:"available"`
+}
+
+// ItemInput — тіло запиту на додавання чи зміну позиції
+type ItemInput struct {
+	ProductID int `json:"productID"`
+	Quantity  int `json:"quantity"`
+}
+
+// CartService надає методи для роботи з кошиками
+type CartService struct {
+	Repo     CartRepository
+	Products ProductReader
+}
+
+var errCartForbidden = problem.New(http.StatusForbidden, "cart belongs to another user")
+
+// errForbidden повертається Merge, якщо кошик належить іншому користувачу
+var errForbidden = errors.New("cart: belongs to another user")
+
+// newID генерує випадковий ID кошика
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// price заповнює позиції поточними даними продуктів та обчислює суму
+func (s *CartService) price(ctx context.Context, c *Cart) error {
+	currency := ""
+	for i := range c.Items {
+		item := &c.Items[i]
+		p, err := s.Products.Get(ctx, item.ProductID)
+		if err == products.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		item.Name = p.Name
+		item.UnitPrice = p.Price
+		item.LineTotal = p.Price.Mul(int64(item.Quantity))
+		if currency == "" {
+			currency = p.Price.Currency
+		}
+		item.Available = item.Quantity <= p.StockQuantity && p.Price.Currency == currency
+	}
+
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	c.Total = money.Zero(currency)
+	for _, item := range c.Items {
+		if !item.Available {
+			continue
+		}
+		total, err := c.Total.Add(item.LineTotal)
+		if err != nil {
+			return err
+		}
+		c.Total = total
+	}
+	return nil
+}
+
+// load повертає кошик з URL-параметра, якщо поточний користувач має до нього доступ.
+// У разі помилки відповідь уже надіслано.
+func (s *CartService) load(w http.ResponseWriter, r *http.Request) (Cart, bool) {
+	cartID := chi.URLParam(r, "id")
+	c, err := s.Repo.Get(r.Context(), cartID)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "cart %s not found", cartID))
+		} else {
+			log.Println("Error querying cart:", err)
+			problem.Error(w, r, err)
+		}
+		return Cart{}, false
+	}
+	if c.UserID != nil {
+		u, ok := auth.UserFrom(r.Context())
+		if !ok {
+			auth.Unauthorized(w, r)
+			return Cart{}, false
+		}
+		if u.ID != *c.UserID {
+			problem.Write(w, r, errCartForbidden)
+			return Cart{}, false
+		}
+	}
+	return c, true
+}
+
+// respond надсилає кошик з актуальними цінами
+func (s *CartService) respond(w http.ResponseWriter, r *http.Request, status int, cartID string) {
+	c, err := s.Repo.Get(r.Context(), cartID)
+	if err == nil {
+		err = s.price(r.Context(), &c)
+	}
+	if err != nil {
+		log.Println("Error querying cart:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, status, c)
+}
+
+// checkItem перевіряє, що продукт існує, його залишку вистачає на quantity
+// і його валюта збігається з валютою інших позицій кошика
+func (s *CartService) checkItem(ctx context.Context, c Cart, productID, quantity int) error {
+	p, err := s.Products.Get(ctx, productID)
+	if err == products.ErrNotFound {
+		return validate.Failed(problem.FieldError{Field: "productID", Message: "product does not exist"})
+	}
+	if err != nil {
+		return err
+	}
+	if quantity > p.StockQuantity {
+		return validate.Failed(problem.FieldError{Field: "quantity", Message: fmt.Sprintf("only %d in stock", p.StockQuantity)})
+	}
+
+	if err := s.price(ctx, &c); err != nil {
+		return err
+	}
+	for _, item := range c.Items {
+		if item.ProductID != productID && item.Available && item.UnitPrice.Currency != p.Price.Currency {
+			return validate.Failed(problem.FieldError{Field: "productID", Message: fmt.Sprintf("product is priced in %s, cart is in %s", p.Price.Currency, item.UnitPrice.Currency)})
+		}
+	}
+	return nil
+}
+
+// quantityOf повертає кількість продукту в кошику
+func (c Cart) quantityOf(productID int) (int, bool) {
+	for _, item := range c.Items {
+		if item.ProductID == productID {
+			return item.Quantity, true
+		}
+	}
+	return 0, false
+}
+
+// CreateCart створює кошик. Для автентифікованого користувача повертає його
+// наявний кошик або створює новий, прив'язаний до нього.
+// POST /carts
+func (s *CartService) CreateCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	c := Cart{Items: []Item{}}
+	if u, ok := auth.UserFrom(ctx); ok {
+		existing, err := s.Repo.GetByUser(ctx, u.ID)
+		if err == nil {
+			s.respond(w, r, http.StatusOK, existing.ID)
+			return
+		}
+		if err != ErrNotFound {
+			log.Println("Error querying cart:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		c.UserID = &u.ID
+	}
+
+	id, err := newID()
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	c.ID = id
+	if err := s.Repo.Create(ctx, &c); err != nil {
+		log.Println("Error inserting cart:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	s.respond(w, r, http.StatusCreated, c.ID)
+}
+
+// GetCart повертає кошик з актуальними цінами
+// GET /carts/{id}
+func (s *CartService) GetCart(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	s.respond(w, r, http.StatusOK, c.ID)
+}
+
+// AddItem додає продукт до кошика; кількість наявної позиції збільшується
+// POST /carts/{id}/items
+func (s *CartService) AddItem(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	var input ItemInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.Positive("productID", input.ProductID)
+	v.Positive("quantity", input.Quantity)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	current, _ := c.quantityOf(input.ProductID)
+	quantity := current + input.Quantity
+	if err := s.checkItem(r.Context(), c, input.ProductID, quantity); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.Repo.SetItem(r.Context(), c.ID, input.ProductID, quantity); err != nil {
+		log.Println("Error updating cart:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	s.respond(w, r, http.StatusOK, c.ID)
+}
+
+// UpdateItem встановлює кількість продукту в кошику; 0 видаляє позицію
+// PUT /carts/{id}/items/{productID}
+func (s *CartService) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	productID, ok := s.itemID(w, r, c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.NonNegative("quantity", float64(input.Quantity))
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if input.Quantity > 0 {
+		if err := s.checkItem(r.Context(), c, productID, input.Quantity); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+	}
+	if err := s.Repo.SetItem(r.Context(), c.ID, productID, input.Quantity); err != nil {
+		log.Println("Error updating cart:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	s.respond(w, r, http.StatusOK, c.ID)
+}
+
+// RemoveItem видаляє продукт з кошика
+// DELETE /carts/{id}/items/{productID}
+func (s *CartService) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	productID, ok := s.itemID(w, r, c)
+	if !ok {
+		return
+	}
+
+	if err := s.Repo.RemoveItem(r.Context(), c.ID, productID); err != nil && err != ErrItemNotFound {
+		log.Println("Error updating cart:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	s.respond(w, r, http.StatusOK, c.ID)
+}
+
+// itemID повертає ID продукту з URL-параметра, якщо він є в кошику.
+// У разі помилки відповідь уже надіслано.
+func (s *CartService) itemID(w http.ResponseWriter, r *http.Request, c Cart) (int, bool) {
+	productID, err := strconv.Atoi(chi.URLParam(r, "productID"))
+	if err != nil {
+		problem.Write(w, r, problem.New(http.StatusBadRequest, "invalid product ID"))
+		return 0, false
+	}
+	if _, ok := c.quantityOf(productID); !ok {
+		problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d is not in the cart", productID))
+		return 0, false
+	}
+	return productID, true
+}
+
+// MergeCart переносить анонімний кошик у кошик поточного користувача
+// POST /carts/{id}/merge
+func (s *CartService) MergeCart(w http.ResponseWriter, r *http.Request) {
+	u, ok := auth.UserFrom(r.Context())
+	if !ok {
+		auth.Unauthorized(w, r)
+		return
+	}
+
+	cartID := chi.URLParam(r, "id")
+	merged, err := s.Merge(r.Context(), cartID, u.ID)
+	if err != nil {
+		switch err {
+		case ErrNotFound:
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "cart %s not found", cartID))
+		case errForbidden:
+			problem.Write(w, r, errCartForbidden)
+		default:
+			log.Println("Error merging cart:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	s.respond(w, r, http.StatusOK, merged)
+}
+
+// Merge переносить позиції анонімного кошика cartID у кошик користувача та
+// повертає ID кошика користувача. Якщо кошика в користувача ще немає,
+// анонімний кошик просто прив'язується до нього. Кількості однакових
+// продуктів додаються, але не перевищують залишку.
+func (s *CartService) Merge(ctx context.Context, cartID string, userID int) (string, error) {
+	anon, err := s.Repo.Get(ctx, cartID)
+	if err != nil {
+		return "", err
+	}
+	if anon.UserID != nil {
+		if *anon.UserID == userID {
+			return anon.ID, nil
+		}
+		return "", errForbidden
+	}
+
+	own, err := s.Repo.GetByUser(ctx, userID)
+	if err == ErrNotFound {
+		if err := s.Repo.AssignUser(ctx, anon.ID, userID); err != nil {
+			return "", err
+		}
+		return anon.ID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, item := range anon.Items {
+		p, err := s.Products.Get(ctx, item.ProductID)
+		if err == products.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		current, _ := own.quantityOf(item.ProductID)
+		quantity := current + item.Quantity
+		if quantity > p.StockQuantity {
+			quantity = p.StockQuantity
+		}
+		if quantity <= current {
+			continue
+		}
+		if err := s.Repo.SetItem(ctx, own.ID, item.ProductID, quantity); err != nil {
+			return "", err
+		}
+	}
+	if err := s.Repo.Delete(ctx, anon.ID); err != nil {
+		return "", err
+	}
+	return own.ID, nil
+}
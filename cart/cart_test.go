@@ -0,0 +1,185 @@
+package cart_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/cart"
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/user"
+)
+
+// newRouter імітує auth.Middleware: користувач береться із заголовка X-User
+func newRouter(svc *cart.CartService) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id, err := strconv.Atoi(r.Header.Get("X-User")); err == nil {
+				r = r.WithContext(auth.WithUser(r.Context(), user.User{ID: id}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Post("/carts", svc.CreateCart)
+	r.Get("/carts/{id}", svc.GetCart)
+	r.Post("/carts/{id}/items", svc.AddItem)
+	r.Put("/carts/{id}/items/{productID}", svc.UpdateItem)
+	r.Delete("/carts/{id}/items/{productID}", svc.RemoveItem)
+	r.Post("/carts/{id}/merge", svc.MergeCart)
+	return r
+}
+
+func do(r http.Handler, method, path, userID, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", userID)
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func decode(t *testing.T, rr *httptest.ResponseRecorder) cart.Cart {
+	var c cart.Cart
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &c))
+	return c
+}
+
+// repositories повертає реалізації CartRepository, на яких проганяються тести
+func repositories(t *testing.T) map[string]cart.CartRepository {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	return map[string]cart.CartRepository{
+		"memory": cart.NewMemoryRepository(),
+		"sqlite": cart.NewSQLRepository(db, d),
+	}
+}
+
+func setup(t *testing.T, carts cart.CartRepository) (chi.Router, *products.MemoryRepository) {
+	ctx := context.Background()
+	cats := categories.NewMemoryRepository()
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+	repo := products.NewMemoryRepository(cats)
+	assert.NoError(t, repo.Create(ctx, &products.Product{Name: "Go", Price: money.MustParse("10.50", "UAH"), StockQuantity: 5, CategoryID: 1}))
+	assert.NoError(t, repo.Create(ctx, &products.Product{Name: "SQL", Price: money.MustParse("4.25", "UAH"), StockQuantity: 2, CategoryID: 1}))
+
+	return newRouter(&cart.CartService{Repo: carts, Products: repo}), repo
+}
+
+func TestAnonymousCart(t *testing.T) {
+	for name, carts := range repositories(t) {
+		t.Run(name, func(t *testing.T) { testAnonymousCart(t, carts) })
+	}
+}
+
+func testAnonymousCart(t *testing.T, carts cart.CartRepository) {
+	r, repo := setup(t, carts)
+
+	rr := do(r, "POST", "/carts", "", "")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	c := decode(t, rr)
+	assert.Nil(t, c.UserID)
+	assert.Equal(t, "0.00 UAH", c.Total.String())
+	path := "/carts/" + c.ID
+
+	// Додавання позицій; повторне додавання збільшує кількість
+	assert.Equal(t, http.StatusOK, do(r, "POST", path+"/items", "", `{"productID":1,"quantity":2}`).Code)
+	assert.Equal(t, http.StatusOK, do(r, "POST", path+"/items", "", `{"productID":1,"quantity":1}`).Code)
+	rr = do(r, "POST", path+"/items", "", `{"productID":2,"quantity":1}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	c = decode(t, rr)
+	assert.Len(t, c.Items, 2)
+	assert.Equal(t, 3, c.Items[0].Quantity)
+	assert.Equal(t, "31.50 UAH", c.Items[0].LineTotal.String())
+	assert.Equal(t, "35.75 UAH", c.Total.String())
+
+	// Кількість понад залишок та неіснуючий продукт відхиляються
+	rr = do(r, "POST", path+"/items", "", `{"productID":1,"quantity":3}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "only 5 in stock")
+	assert.Equal(t, http.StatusUnprocessableEntity, do(r, "POST", path+"/items", "", `{"productID":9,"quantity":1}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(r, "PUT", path+"/items/2", "", `{"quantity":3}`).Code)
+
+	// Сума перераховується з поточної ціни продукту
+	p, err := repo.Get(context.Background(), 2)
+	assert.NoError(t, err)
+	p.Price = money.MustParse("5.00", "UAH")
+	assert.NoError(t, repo.Update(context.Background(), 2, &p))
+	assert.Equal(t, "36.50 UAH", decode(t, do(r, "GET", path, "", "")).Total.String())
+
+	// Зміна та видалення позицій
+	c = decode(t, do(r, "PUT", path+"/items/1", "", `{"quantity":1}`))
+	assert.Equal(t, "15.50 UAH", c.Total.String())
+	c = decode(t, do(r, "PUT", path+"/items/1", "", `{"quantity":0}`))
+	assert.Len(t, c.Items, 1)
+	c = decode(t, do(r, "DELETE", path+"/items/2", "", ""))
+	assert.Empty(t, c.Items)
+	assert.Equal(t, http.StatusNotFound, do(r, "DELETE", path+"/items/2", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(r, "GET", "/carts/missing", "", "").Code)
+}
+
+func TestUserCartAndMerge(t *testing.T) {
+	for name, carts := range repositories(t) {
+		t.Run(name, func(t *testing.T) { testUserCartAndMerge(t, carts) })
+	}
+}
+
+func testUserCartAndMerge(t *testing.T, carts cart.CartRepository) {
+	r, _ := setup(t, carts)
+
+	// Кошик користувача створюється один раз і доступний лише власнику
+	rr := do(r, "POST", "/carts", "7", "")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	own := decode(t, rr)
+	assert.Equal(t, 7, *own.UserID)
+	rr = do(r, "POST", "/carts", "7", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, own.ID, decode(t, rr).ID)
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, "GET", "/carts/"+own.ID, "", "").Code)
+	assert.Equal(t, http.StatusForbidden, do(r, "GET", "/carts/"+own.ID, "8", "").Code)
+	assert.Equal(t, http.StatusOK, do(r, "POST", "/carts/"+own.ID+"/items", "7", `{"productID":1,"quantity":4}`).Code)
+
+	// Анонімний кошик зливається з кошиком користувача, кількість обмежена залишком
+	anon := decode(t, do(r, "POST", "/carts", "", ""))
+	do(r, "POST", "/carts/"+anon.ID+"/items", "", `{"productID":1,"quantity":3}`)
+	do(r, "POST", "/carts/"+anon.ID+"/items", "", `{"productID":2,"quantity":2}`)
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, "POST", "/carts/"+anon.ID+"/merge", "", "").Code)
+	rr = do(r, "POST", "/carts/"+anon.ID+"/merge", "7", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	merged := decode(t, rr)
+	assert.Equal(t, own.ID, merged.ID)
+	assert.Len(t, merged.Items, 2)
+	assert.Equal(t, 5, merged.Items[0].Quantity)
+	assert.Equal(t, 2, merged.Items[1].Quantity)
+	assert.Equal(t, http.StatusNotFound, do(r, "GET", "/carts/"+anon.ID, "", "").Code)
+
+	// Без власного кошика анонімний просто прив'язується до користувача
+	anon = decode(t, do(r, "POST", "/carts", "", ""))
+	merged = decode(t, do(r, "POST", "/carts/"+anon.ID+"/merge", "9", ""))
+	assert.Equal(t, anon.ID, merged.ID)
+	assert.Equal(t, 9, *merged.UserID)
+	assert.Equal(t, http.StatusForbidden, do(r, "POST", "/carts/"+anon.ID+"/merge", "7", "").Code)
+}
@@ -0,0 +1,164 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRepository зберігає кошики у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	carts map[string]Cart
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{carts: make(map[string]Cart)}
+}
+
+// copyCart повертає копію кошика, щоб виклики не ділили слайс позицій
+func copyCart(c Cart) Cart {
+	c.Items = append([]Item{}, c.Items...)
+	if c.UserID != nil {
+		id := *c.UserID
+		c.UserID = &id
+	}
+	return c
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, c *Cart) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	m.carts[c.ID] = copyCart(*c)
+	return nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id string) (Cart, error) {
+	if err := ctx.Err(); err != nil {
+		return Cart{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.carts[id]
+	if !ok {
+		return Cart{}, ErrNotFound
+	}
+	return copyCart(c), nil
+}
+
+func (m *MemoryRepository) GetByUser(ctx context.Context, userID int) (Cart, error) {
+	if err := ctx.Err(); err != nil {
+		return Cart{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.carts {
+		if c.UserID != nil && *c.UserID == userID {
+			return copyCart(c), nil
+		}
+	}
+	return Cart{}, ErrNotFound
+}
+
+func (m *MemoryRepository) SetItem(ctx context.Context, cartID string, productID, quantity int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.carts[cartID]
+	if !ok {
+		return ErrNotFound
+	}
+	items := c.Items[:0]
+	found := false
+	for _, item := range c.Items {
+		if item.ProductID == productID {
+			found = true
+			if quantity <= 0 {
+				continue
+			}
+			item.Quantity = quantity
+		}
+		items = append(items, item)
+	}
+	if !found && quantity > 0 {
+		items = append(items, Item{ProductID: productID, Quantity: quantity})
+	}
+	c.Items = items
+	c.UpdatedAt = time.Now()
+	m.carts[cartID] = c
+	return nil
+}
+
+func (m *MemoryRepository) RemoveItem(ctx context.Context, cartID string, productID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.carts[cartID]
+	if !ok {
+		return ErrNotFound
+	}
+	for i, item := range c.Items {
+		if item.ProductID == productID {
+			c.Items = append(c.Items[:i], c.Items[i+1:]...)
+			c.UpdatedAt = time.Now()
+			m.carts[cartID] = c
+			return nil
+		}
+	}
+	return ErrItemNotFound
+}
+
+func (m *MemoryRepository) AssignUser(ctx context.Context, cartID string, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.carts[cartID]
+	if !ok || c.UserID != nil {
+		return ErrNotFound
+	}
+	c.UserID = &userID
+	c.UpdatedAt = time.Now()
+	m.carts[cartID] = c
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.carts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.carts, id)
+	return nil
+}
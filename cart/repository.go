@@ -0,0 +1,37 @@
+package cart
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// ErrNotFound повертається репозиторієм, якщо кошика не існує
+var ErrNotFound = errors.New("cart: not found")
+
+// ErrItemNotFound повертається репозиторієм, якщо продукту немає в кошику
+var ErrItemNotFound = errors.New("cart: item not found")
+
+// CartRepository описує сховище кошиків, з яким працює CartService
+type CartRepository interface {
+	// Create зберігає новий кошик з уже заповненим ID та заповнює дати
+	Create(ctx context.Context, c *Cart) error
+	// Get повертає кошик разом з позиціями або ErrNotFound
+	Get(ctx context.Context, id string) (Cart, error)
+	// GetByUser повертає кошик користувача або ErrNotFound
+	GetByUser(ctx context.Context, userID int) (Cart, error)
+	// SetItem встановлює кількість продукту в кошику; 0 видаляє позицію
+	SetItem(ctx context.Context, cartID string, productID, quantity int) error
+	// RemoveItem видаляє позицію або повертає ErrItemNotFound
+	RemoveItem(ctx context.Context, cartID string, productID int) error
+	// AssignUser прив'язує анонімний кошик до користувача або повертає ErrNotFound
+	AssignUser(ctx context.Context, cartID string, userID int) error
+	// Delete видаляє кошик разом з позиціями
+	Delete(ctx context.Context, id string) error
+}
+
+// ProductReader надає поточні ціни та залишки продуктів
+type ProductReader interface {
+	Get(ctx context.Context, id int) (products.Product, error)
+}
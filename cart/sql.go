@@ -0,0 +1,190 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+// SQLRepository зберігає кошики у таблицях carts та cart_items SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+func (m *SQLRepository) Create(ctx context.Context, c *Cart) error {
+	now := time.Now()
+	_, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO carts (id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?)"),
+		c.ID, c.UserID, now, now)
+	if err != nil {
+		return err
+	}
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id string) (Cart, error) {
+	return m.get(ctx, "SELECT id, user_id, created_at, updated_at FROM carts WHERE id=?", id)
+}
+
+func (m *SQLRepository) GetByUser(ctx context.Context, userID int) (Cart, error) {
+	return m.get(ctx, "SELECT id, user_id, created_at, updated_at FROM carts WHERE user_id=?", userID)
+}
+
+func (m *SQLRepository) get(ctx context.Context, query string, arg interface{}) (Cart, error) {
+	var (
+		c      Cart
+		userID sql.NullInt64
+	)
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind(query), arg).Scan(&c.ID, &userID, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return Cart{}, ErrNotFound
+	}
+	if err != nil {
+		return Cart{}, err
+	}
+	if userID.Valid {
+		id := int(userID.Int64)
+		c.UserID = &id
+	}
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT product_id, quantity FROM cart_items WHERE cart_id=? ORDER BY added_at, product_id"), c.ID)
+	if err != nil {
+		return Cart{}, err
+	}
+	defer rows.Close()
+
+	c.Items = []Item{}
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return Cart{}, err
+		}
+		c.Items = append(c.Items, item)
+	}
+	return c, rows.Err()
+}
+
+func (m *SQLRepository) SetItem(ctx context.Context, cartID string, productID, quantity int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if quantity <= 0 {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM cart_items WHERE cart_id=? AND product_id=?"), cartID, productID); err != nil {
+			return err
+		}
+	} else {
+		result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE cart_items SET quantity=? WHERE cart_id=? AND product_id=?"), quantity, cartID, productID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			_, err = tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO cart_items (cart_id, product_id, quantity, added_at) VALUES (?, ?, ?, ?)"),
+				cartID, productID, quantity, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := m.touch(ctx, tx, cartID, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *SQLRepository) RemoveItem(ctx context.Context, cartID string, productID int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM cart_items WHERE cart_id=? AND product_id=?"), cartID, productID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	if err := m.touch(ctx, tx, cartID, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// touch оновлює дату зміни кошика або повертає ErrNotFound
+func (m *SQLRepository) touch(ctx context.Context, tx *sql.Tx, cartID string, now time.Time) error {
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE carts SET updated_at=? WHERE id=?"), now, cartID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *SQLRepository) AssignUser(ctx context.Context, cartID string, userID int) error {
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE carts SET user_id=?, updated_at=? WHERE id=? AND user_id IS NULL"), userID, time.Now(), cartID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id string) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM cart_items WHERE cart_id=?"), id); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM carts WHERE id=?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
@@ -1,18 +1,21 @@
 package categories
 
 import (
-	"database/sql"
-	"encoding/json"
 	"log"
 	"net/http"
 	s "strconv"
 	"time"
 
 	"github.com/go-chi/chi"
-	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/patch"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
 )
 
-// User представляє структуру користувача
+// Category представляє структуру категорії
 type Category struct {
 	ID          int       `json:"id"`
 	Name        string    `json:"name"`
@@ -21,234 +24,224 @@ type Category struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// Validate перевіряє поля категорії перед збереженням
+func (c Category) Validate() error {
+	v := validate.New()
+	v.Required("name", c.Name)
+	v.MaxLen("name", c.Name, 255)
+	v.MaxLen("description", c.Description, 10000)
+	return v.Err()
+}
+
+// CatSetvices надає методи для роботи з категоріями
 type CatSetvices struct {
-	DB *sql.DB
+	Repo CategoryRepository
 }
 
-func getQueryParamInt(r *http.Request, key string, defaultValue int) int {
-	value := r.URL.Query().Get(key)
-	if value == "" {
-		return defaultValue
-	}
+// errInvalidID повертається, якщо {id} не є додатним цілим числом
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
 
-	result, err := s.Atoi(value)
-	if err != nil {
-		return defaultValue
+// getURLParamID повертає числовий ID з URL-параметра {id}
+func getURLParamID(r *http.Request) (int, bool) {
+	id, err := s.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
 	}
-
-	return result
+	return id, true
 }
 
+// GetCats повертає сторінку категорій, впорядкованих за ID.
+// Сторінки гортаються курсорами page.next та page.prev, які також передаються
+// в заголовку Link; total=true додає загальну кількість категорій.
+// GET /cat?limit=10&cursor=...&total=true
 func (s *CatSetvices) GetCats(w http.ResponseWriter, r *http.Request) {
 	// Отримання значень параметрів пагінації
-	page := getQueryParamInt(r, "page", 1)
-	limit := getQueryParamInt(r, "limit", 10)
-
-	// Розрахунок зсуву (offset) для пагінації
-	offset := (page - 1) * limit
+	v := validate.New()
+	q := pagination.Parse(r.URL.Query(), v, "")
+	if err := v.QueryErr(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
 
-	// Вибірка користувачів з бази даних з пагінацією
-	rows, err := s.DB.Query("SELECT * FROM categories LIMIT ? OFFSET ?", limit, offset)
+	// Вибірка категорій зі сховища з пагінацією
+	cats, err := s.Repo.List(r.Context(), q)
 	if err != nil {
-		log.Println("Error querying database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error querying categories:", err)
+		problem.Error(w, r, err)
 		return
 	}
-	defer rows.Close()
-
-	// Створення слайсу для зберігання результатів
-	var cats []Category
+	var list pagination.List[Category]
+	list.Data, list.Page = pagination.Build(cats, q, func(c Category) pagination.Cursor {
+		return pagination.Cursor{ID: c.ID}
+	})
 
-	// Зчитування результатів запиту
-	for rows.Next() {
-		var cat Category
-		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
+	if q.Total {
+		total, err := s.Repo.Count(r.Context())
+		if err != nil {
+			log.Println("Error counting categories:", err)
+			problem.Error(w, r, err)
 			return
 		}
-		cats = append(cats, cat)
-	}
-
-	// Перевірка наявності помилок під час зчитування
-	if err := rows.Err(); err != nil {
-		log.Println("Error reading rows:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		list.Page.Total = &total
 	}
 
 	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(cats); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	pagination.SetLink(w, r, list.Page)
+	render.JSON(w, r, http.StatusOK, list)
 }
 
-// Get category
+// GetCat повертає категорію за ID
 func (s *CatSetvices) GetCat(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID користувача з URL-параметра
-	catID := chi.URLParam(r, "id")
-	if catID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	// Отримання ID категорії з URL-параметра
+	catID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Вибірка конкретного користувача з бази даних за ID (з параметром)
-	row := s.DB.QueryRow("SELECT * FROM categories WHERE id=?", catID)
-
-	// Створення змінної для зберігання результатів
-	var cat Category
-
-	// Зчитування результатів запиту
-	err := row.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt)
+	// Вибірка конкретної категорії зі сховища за ID
+	cat, err := s.Repo.Get(r.Context(), catID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusNotFound)
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "category %d not found", catID))
 		} else {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("Error querying category:", err)
+			problem.Error(w, r, err)
 		}
 		return
 	}
 
 	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(cat); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	render.JSON(w, r, http.StatusOK, cat)
 }
 
-// Create
+// CreateCat додає нову категорію
 func (s *CatSetvices) CreateCat(w http.ResponseWriter, r *http.Request) {
 	var newCat Category
-	if err := json.NewDecoder(r.Body).Decode(&newCat); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := validate.DecodeJSON(r, &newCat); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-
-	// Додавання нового користувача до бази даних
-	result, err := s.DB.Exec("INSERT INTO categories (name, description, created_at, modified_at) VALUES (?, ?, ?, ?, ?, ?)",
-		newCat.Name, newCat.Description, time.Now(), time.Now())
-	if err != nil {
-		log.Println("Error inserting user into database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	if err := newCat.Validate(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Отримання ID новоствореного користувача
-	catID, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Error getting last insert ID:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	// Додавання нової категорії до сховища
+	if err := s.Repo.Create(r.Context(), &newCat); err != nil {
+		log.Println("Error inserting category:", err)
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Отримання повнішої інформації про новоствореного користувача
-	newCat.ID = int(catID)
-	err = s.DB.QueryRow("SELECT * FROM categories WHERE id=?", catID).Scan(&newCat.ID, &newCat.Name, &newCat.Description, &newCat.CreatedAt, &newCat.UpdatedAt)
-	if err != nil {
-		log.Println("Error querying new user:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	// Відправлення відповіді у форматі JSON з повною інформацією про нову категорію
+	render.JSON(w, r, http.StatusCreated, newCat)
+}
+
+// UpdateCat оновлює категорію за ID
+func (s *CatSetvices) UpdateCat(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID категорії з URL-параметра
+	catID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Відправлення відповіді у форматі JSON з повною інформацією про нового користувача
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(newCat); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	// Отримання нових даних про категорію з тіла запиту (JSON)
+	var updatedCat Category
+	if err := validate.DecodeJSON(r, &updatedCat); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-}
-
-// UpdateUser
-func (s *CatSetvices) UpdateCat(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID користувача з URL-параметра
-	catID := chi.URLParam(r, "id")
-	if catID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := updatedCat.Validate(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Отримання старої інформації про користувача
-	var oldCat Category
-	err := s.DB.QueryRow("SELECT * FROM categories WHERE id=?", catID).Scan(&oldCat.ID, &oldCat.Name, &oldCat.Description, &oldCat.CreatedAt, &oldCat.UpdatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusNotFound)
+	// Оновлення інформації про категорію у сховищі
+	if err := s.Repo.Update(r.Context(), catID, &updatedCat); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "category %d not found", catID))
 		} else {
-			log.Println("Error querying user:", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("Error updating category:", err)
+			problem.Error(w, r, err)
 		}
 		return
 	}
 
-	// Отримання нових даних про користувача з тіла запиту (JSON)
-	var updatedCat Category
-	if err := json.NewDecoder(r.Body).Decode(&updatedCat); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
+	// Відправлення відповіді у форматі JSON з оновленою інформацією про категорію
+	render.JSON(w, r, http.StatusOK, updatedCat)
+}
+
+// PatchCat частково оновлює категорію: тіло запиту (merge patch або JSON Patch)
+// застосовується до збереженої категорії, тож поля, яких немає в патчі, не змінюються
+func (s *CatSetvices) PatchCat(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID категорії з URL-параметра
+	catID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Оновлення інформації про користувача в базі даних
-	_, err = s.DB.Exec("UPDATE categories SET name=?, description=?,  updated_at=? WHERE id=?",
-		updatedCat.Name, updatedCat.Description, time.Now(), catID)
+	current, err := s.Repo.Get(r.Context(), catID)
 	if err != nil {
-		log.Println("Error updating user in database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "category %d not found", catID))
+		} else {
+			log.Println("Error querying category:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
-	// Відправлення відповіді у форматі JSON з оновленою інформацією про користувача
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(updatedCat); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	var patched Category
+	if err := patch.Decode(r, current, &patched); err != nil {
+		patch.Error(w, r, err)
+		return
+	}
+	if err := patched.Validate(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-}
 
-// DeleteUser видаляє користувача за ID
-func (s *CatSetvices) DeleteCat(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID користувача з URL-параметра
-	catID := chi.URLParam(r, "id")
-	if catID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := s.Repo.Update(r.Context(), catID, &patched); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "category %d not found", catID))
+		} else {
+			log.Println("Error updating category:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
-	// Видалення користувача з бази даних за ID
-	result, err := s.DB.Exec("DELETE FROM users WHERE id=?", catID)
+	// Повертається збережений стан, а не декодований патч
+	stored, err := s.Repo.Get(r.Context(), catID)
 	if err != nil {
-		log.Println("Error deleting user from database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error querying category:", err)
+		problem.Error(w, r, err)
 		return
 	}
+	render.JSON(w, r, http.StatusOK, stored)
+}
 
-	// Перевірка, чи існує користувач за вказаним ID
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+// DeleteCat видаляє категорію за ID
+func (s *CatSetvices) DeleteCat(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID категорії з URL-параметра
+	catID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
-	if rowsAffected == 0 {
-		// Якщо немає відповідного користувача, відправити HTTP статус 404 (Not Found)
-		w.WriteHeader(http.StatusNotFound)
+
+	// Видалення категорії зі сховища за ID
+	if err := s.Repo.Delete(r.Context(), catID); err != nil {
+		if err == ErrNotFound {
+			// Якщо немає відповідної категорії, відправити HTTP статус 404 (Not Found)
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "category %d not found", catID))
+		} else {
+			log.Println("Error deleting category:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
@@ -0,0 +1,160 @@
+package categories_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/patch"
+)
+
+func TestGetCats(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	for name, repo := range map[string]categories.CategoryRepository{
+		"memory": categories.NewMemoryRepository(),
+		"sqlite": categories.NewSQLRepository(db, d),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, name := range []string{"A", "B", "C", "D", "E"} {
+				assert.NoError(t, repo.Create(context.Background(), &categories.Category{Name: name}))
+			}
+			r := chi.NewRouter()
+			r.Get("/categories", (&categories.CatSetvices{Repo: repo}).GetCats)
+
+			get := func(query string) (pagination.List[categories.Category], http.Header) {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest("GET", "/categories?"+query, nil))
+				assert.Equal(t, http.StatusOK, rr.Code, query)
+				var list pagination.List[categories.Category]
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+				return list, rr.Header()
+			}
+			ids := func(list pagination.List[categories.Category]) []int {
+				got := []int{}
+				for _, c := range list.Data {
+					got = append(got, c.ID)
+				}
+				return got
+			}
+
+			// Завеликий limit обрізається до pagination.MaxLimit
+			all, _ := get("limit=1000")
+			assert.Equal(t, pagination.MaxLimit, all.Page.Limit)
+			assert.Equal(t, []int{1, 2, 3, 4, 5}, ids(all))
+			assert.Empty(t, all.Page.Next)
+
+			first, header := get("limit=2&total=true")
+			assert.Equal(t, []int{1, 2}, ids(first))
+			assert.Equal(t, 5, *first.Page.Total)
+			assert.Empty(t, first.Page.Prev)
+			link := header.Get("Link")
+			assert.Contains(t, link, `rel="first"`)
+			assert.Contains(t, link, "cursor="+first.Page.Next)
+			assert.NotContains(t, link, `rel="prev"`)
+			// Інші параметри запиту зберігаються в посиланнях
+			assert.True(t, strings.HasPrefix(link, "</categories?limit=2&total=true>"))
+
+			second, _ := get("limit=2&cursor=" + first.Page.Next)
+			assert.Equal(t, []int{3, 4}, ids(second))
+			assert.Nil(t, second.Page.Total)
+
+			last, header := get("limit=2&cursor=" + second.Page.Next)
+			assert.Equal(t, []int{5}, ids(last))
+			assert.Empty(t, last.Page.Next)
+			assert.NotContains(t, header.Get("Link"), `rel="next"`)
+
+			back, _ := get("limit=2&cursor=" + last.Page.Prev)
+			assert.Equal(t, []int{3, 4}, ids(back))
+			back, _ = get("limit=2&cursor=" + back.Page.Prev)
+			assert.Equal(t, []int{1, 2}, ids(back))
+			assert.Empty(t, back.Page.Prev)
+			assert.NotEmpty(t, back.Page.Next)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest("GET", "/categories?cursor=bogus", nil))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestPatchCat(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	for name, repo := range map[string]categories.CategoryRepository{
+		"memory": categories.NewMemoryRepository(),
+		"sqlite": categories.NewSQLRepository(db, d),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, repo.Create(context.Background(), &categories.Category{Name: "Books", Description: "Paper and e-books"}))
+			r := chi.NewRouter()
+			r.Patch("/cat/{id}", (&categories.CatSetvices{Repo: repo}).PatchCat)
+
+			do := func(path, body, contentType string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest("PATCH", path, strings.NewReader(body))
+				req.Header.Set("Content-Type", contentType)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+				return rr
+			}
+
+			rr := do("/cat/1", `{"name":"Literature","id":42}`, patch.MergePatchType)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			var cat categories.Category
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &cat))
+			assert.Equal(t, 1, cat.ID)
+			assert.Equal(t, "Literature", cat.Name)
+			assert.Equal(t, "Paper and e-books", cat.Description)
+			assert.False(t, cat.CreatedAt.IsZero())
+
+			rr = do("/cat/1", `[{"op":"remove","path":"/description"}]`, patch.JSONPatchType+"; charset=utf-8")
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			stored, err := repo.Get(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Literature", stored.Name)
+			assert.Empty(t, stored.Description)
+
+			// Невдала операція test нічого не змінює
+			rr = do("/cat/1", `[{"op":"test","path":"/name","value":"Books"},{"op":"replace","path":"/name","value":"Comics"}]`, patch.JSONPatchType)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			stored, err = repo.Get(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Literature", stored.Name)
+
+			rr = do("/cat/1", `{"name":""}`, "application/json")
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"name"`)
+			rr = do("/cat/1", `<name/>`, "application/xml")
+			assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+			assert.Equal(t, patch.Accept, rr.Header().Get("Accept-Patch"))
+			rr = do("/cat/2", `{}`, patch.MergePatchType)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package categories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// MemoryRepository зберігає категорії у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	cats   map[int]Category
+	nextID int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{cats: make(map[int]Category), nextID: 1}
+}
+
+func (m *MemoryRepository) List(ctx context.Context, q pagination.Query) ([]Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]int, 0, len(m.cats))
+	for id := range m.cats {
+		ids = append(ids, id)
+	}
+
+	cats := []Category{}
+	for _, id := range pagination.SelectIDs(ids, q) {
+		cats = append(cats, m.cats[id])
+	}
+	return cats, nil
+}
+
+func (m *MemoryRepository) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.cats), nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Category, error) {
+	if err := ctx.Err(); err != nil {
+		return Category{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cat, ok := m.cats[id]
+	if !ok {
+		return Category{}, ErrNotFound
+	}
+	return cat, nil
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, c *Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	c.ID = m.nextID
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	m.cats[c.ID] = *c
+	m.nextID++
+	return nil
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, id int, c *Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.cats[id]
+	if !ok {
+		return ErrNotFound
+	}
+	c.ID = id
+	c.CreatedAt = old.CreatedAt
+	c.UpdatedAt = time.Now()
+	m.cats[id] = *c
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.cats[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.cats, id)
+	return nil
+}
@@ -0,0 +1,28 @@
+package categories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// ErrNotFound повертається репозиторієм, якщо категорії з таким ID не існує
+var ErrNotFound = errors.New("categories: not found")
+
+// CategoryRepository описує сховище категорій, з яким працює CatSetvices
+type CategoryRepository interface {
+	// List повертає до q.Limit+1 категорій після межі курсора, впорядкованих
+	// за ID (для зворотного курсора — перед межею, за спаданням ID)
+	List(ctx context.Context, q pagination.Query) ([]Category, error)
+	// Count повертає кількість усіх категорій
+	Count(ctx context.Context) (int, error)
+	// Get повертає категорію за ID або ErrNotFound
+	Get(ctx context.Context, id int) (Category, error)
+	// Create зберігає нову категорію та заповнює ID і дати
+	Create(ctx context.Context, c *Category) error
+	// Update перезаписує дані категорії за ID або повертає ErrNotFound
+	Update(ctx context.Context, id int, c *Category) error
+	// Delete видаляє категорію за ID або повертає ErrNotFound
+	Delete(ctx context.Context, id int) error
+}
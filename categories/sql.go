@@ -0,0 +1,115 @@
+package categories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// SQLRepository зберігає категорії у таблиці categories SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+func (m *SQLRepository) List(ctx context.Context, q pagination.Query) ([]Category, error) {
+	key := []pagination.Column{{Expr: "id"}}
+	query := "SELECT * FROM categories"
+	var args []interface{}
+	if q.Cursor != nil {
+		var where string
+		where, args = pagination.Keyset(key, []interface{}{q.Cursor.ID}, q.Backward())
+		query += " WHERE " + where
+	}
+	query += " ORDER BY " + pagination.OrderBy(key, q.Backward()) + " LIMIT ?"
+	args = append(args, q.Limit+1)
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cats := []Category{}
+	for rows.Next() {
+		var cat Category
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+			return nil, err
+		}
+		cats = append(cats, cat)
+	}
+	return cats, rows.Err()
+}
+
+func (m *SQLRepository) Count(ctx context.Context) (int, error) {
+	var n int
+	err := m.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM categories").Scan(&n)
+	return n, err
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (Category, error) {
+	var cat Category
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT * FROM categories WHERE id=?"), id).Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return Category{}, ErrNotFound
+	}
+	return cat, err
+}
+
+func (m *SQLRepository) Create(ctx context.Context, c *Category) error {
+	now := time.Now()
+	catID, err := m.Dialect.InsertID(ctx, m.DB, "INSERT INTO categories (name, description, created_at, updated_at) VALUES (?, ?, ?, ?)",
+		c.Name, c.Description, now, now)
+	if err != nil {
+		return err
+	}
+
+	// Отримання повнішої інформації про новостворену категорію
+	created, err := m.Get(ctx, int(catID))
+	if err != nil {
+		return err
+	}
+	*c = created
+	return nil
+}
+
+func (m *SQLRepository) Update(ctx context.Context, id int, c *Category) error {
+	// Перевірка, чи існує категорія за вказаним ID
+	if _, err := m.Get(ctx, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE categories SET name=?, description=?, updated_at=? WHERE id=?"),
+		c.Name, c.Description, now, id)
+	if err != nil {
+		return err
+	}
+	c.ID = id
+	c.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id int) error {
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM categories WHERE id=?"), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Package config описує типізовану конфігурацію сервісу та її завантаження
+// з YAML-файлу, змінних оточення і прапорців командного рядка.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// Config містить усі налаштування сервісу
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Log      LogConfig      `yaml:"log"`
+	Health   HealthConfig   `yaml:"health"`
+	Shop     ShopConfig     `yaml:"shop"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Payments PaymentsConfig `yaml:"payments"`
+	Tax      TaxConfig      `yaml:"tax"`
+	Images   ImagesConfig   `yaml:"images"`
+	Features FeaturesConfig `yaml:"features"`
+}
+
+// ServerConfig налаштовує HTTP-сервер
+type ServerConfig struct {
+	Addr         string        `yaml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// MaxBodyBytes обмежує розмір тіла запиту; більші запити отримують 413
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+	// ShutdownTimeout обмежує час очікування завершення запитів під час зупинки
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// DatabaseConfig налаштовує підключення та пул з'єднань до бази даних
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver"`
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// QueryTimeout обмежує час роботи з базою даних в межах одного HTTP-запиту
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// RouteQueryTimeouts перевизначає QueryTimeout для груп маршрутів, наприклад "/products"
+	RouteQueryTimeouts map[string]time.Duration `yaml:"route_query_timeouts"`
+}
+
+// QueryTimeoutFor повертає граничний час запитів до бази даних для групи маршрутів
+func (c DatabaseConfig) QueryTimeoutFor(route string) time.Duration {
+	if d, ok := c.RouteQueryTimeouts[route]; ok {
+		return d
+	}
+	return c.QueryTimeout
+}
+
+// HealthConfig налаштовує перевірки готовності /readyz
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout"`
+	// DiskPath — каталог, вільне місце в якому перевіряється; порожній вимикає перевірку
+	DiskPath     string `yaml:"disk_path"`
+	MinFreeBytes uint64 `yaml:"min_free_bytes"`
+}
+
+// ShopConfig містить налаштування магазину
+type ShopConfig struct {
+	// Currency — валюта ISO 4217 для цін, у запитах яких валюту не вказано
+	Currency string `yaml:"currency"`
+}
+
+// AuthConfig налаштовує зберігання паролів та автентифікацію
+type AuthConfig struct {
+	// BcryptCost — вартість bcrypt; після зміни паролі перехешовуються під час входу
+	BcryptCost int `yaml:"bcrypt_cost"`
+	// AccessTokenTTL — строк дії access-токена
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl"`
+	// RefreshTokenTTL — строк дії refresh-токена; кожне оновлення видає новий
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+// PaymentsConfig налаштовує прийом оплат
+type PaymentsConfig struct {
+	// Provider — платіжний провайдер; наразі підтримується лише fake
+	Provider string `yaml:"provider"`
+	// WebhookSecret — секрет підпису вебхуків провайдера; порожній відхиляє всі вебхуки
+	WebhookSecret string `yaml:"webhook_secret"`
+}
+
+// TaxConfig налаштовує розрахунок податків
+type TaxConfig struct {
+	// PricesIncludeTax — ціни каталогу вказано з податком; інакше податок додається до них
+	PricesIncludeTax bool `yaml:"prices_include_tax"`
+}
+
+// ImagesConfig — зберігання та обробка зображень продуктів
+type ImagesConfig struct {
+	// Dir — каталог локального сховища зображень
+	Dir string `yaml:"dir"`
+	// BaseURL — префікс URL, за яким віддаються файли сховища
+	BaseURL string `yaml:"base_url"`
+	// MaxUploadBytes — найбільший розмір завантажуваного файлу
+	MaxUploadBytes int64 `yaml:"max_upload_bytes"`
+	// MaxPixels — найбільша кількість пікселів зображення (ширина × висота)
+	MaxPixels int `yaml:"max_pixels"`
+	// Thumbnails — розміри мініатюр, що створюються під час завантаження
+	Thumbnails []ThumbnailConfig `yaml:"thumbnails"`
+}
+
+// ThumbnailConfig — мініатюра, вписана в рамку Width × Height зі збереженням пропорцій
+type ThumbnailConfig struct {
+	Name   string `yaml:"name"`
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height"`
+}
+
+// LogConfig налаштовує журналювання
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+// FeaturesConfig містить перемикачі можливостей
+type FeaturesConfig struct {
+	RequestLogging bool `yaml:"request_logging"`
+}
+
+// Default повертає конфігурацію за замовчуванням
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            ":7000",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			MaxBodyBytes:    1 << 20,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          "mysql",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			QueryTimeout:    5 * time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Auth: AuthConfig{
+			BcryptCost:      12,
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Shop: ShopConfig{
+			Currency: "UAH",
+		},
+		Payments: PaymentsConfig{
+			Provider: "fake",
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			MinFreeBytes: 100 << 20,
+		},
+		Images: ImagesConfig{
+			Dir:            "uploads",
+			BaseURL:        "/media/",
+			MaxUploadBytes: 10 << 20,
+			MaxPixels:      50_000_000,
+			Thumbnails: []ThumbnailConfig{
+				{Name: "small", Width: 150, Height: 150},
+				{Name: "medium", Width: 600, Height: 600},
+			},
+		},
+		Features: FeaturesConfig{
+			RequestLogging: true,
+		},
+	}
+}
+
+// Load будує конфігурацію з урахуванням пріоритетів:
+// значення за замовчуванням < файл < змінні оточення < прапорці.
+// Шлях до файлу задається прапорцем -config або змінною SHOP_CONFIG.
+// Повертає аргументи, що залишилися після прапорців (наприклад, підкоманду).
+func Load(args []string, getenv func(string) string) (Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("shop_go", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", getenv("SHOP_CONFIG"), "path to YAML config file")
+	addr := fs.String("addr", "", "listen address, e.g. :7000")
+	dsn := fs.String("dsn", "", "database DSN")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn, error")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, fmt.Errorf("config: %w", err)
+	}
+
+	if *configPath != "" {
+		if err := loadFile(&cfg, *configPath); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	if err := applyEnv(&cfg, getenv); err != nil {
+		return Config{}, nil, err
+	}
+
+	// Прапорці мають найвищий пріоритет, але лише якщо їх явно задано
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Server.Addr = *addr
+		case "dsn":
+			cfg.Database.DSN = *dsn
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// loadFile накладає значення з YAML-файлу на cfg; невідомі ключі є помилкою
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv накладає значення зі змінних оточення SHOP_*.
+// PORT підтримується для сумісності з попередньою поведінкою getPort.
+func applyEnv(cfg *Config, getenv func(string) string) error {
+	if port := getenv("PORT"); port != "" {
+		cfg.Server.Addr = ":" + port
+	}
+
+	stringVars := map[string]*string{
+		"SHOP_SERVER_ADDR":             &cfg.Server.Addr,
+		"SHOP_DATABASE_DRIVER":         &cfg.Database.Driver,
+		"SHOP_DATABASE_DSN":            &cfg.Database.DSN,
+		"SHOP_LOG_LEVEL":               &cfg.Log.Level,
+		"SHOP_HEALTH_DISK_PATH":        &cfg.Health.DiskPath,
+		"SHOP_SHOP_CURRENCY":           &cfg.Shop.Currency,
+		"SHOP_PAYMENTS_PROVIDER":       &cfg.Payments.Provider,
+		"SHOP_PAYMENTS_WEBHOOK_SECRET": &cfg.Payments.WebhookSecret,
+		"SHOP_IMAGES_DIR":              &cfg.Images.Dir,
+		"SHOP_IMAGES_BASE_URL":         &cfg.Images.BaseURL,
+	}
+	for key, target := range stringVars {
+		if value := getenv(key); value != "" {
+			*target = value
+		}
+	}
+
+	intVars := map[string]*int{
+		"SHOP_DATABASE_MAX_OPEN_CONNS": &cfg.Database.MaxOpenConns,
+		"SHOP_DATABASE_MAX_IDLE_CONNS": &cfg.Database.MaxIdleConns,
+		"SHOP_AUTH_BCRYPT_COST":        &cfg.Auth.BcryptCost,
+		"SHOP_IMAGES_MAX_PIXELS":       &cfg.Images.MaxPixels,
+	}
+	for key, target := range intVars {
+		if value := getenv(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: invalid integer %q", key, value)
+			}
+			*target = n
+		}
+	}
+
+	durationVars := map[string]*time.Duration{
+		"SHOP_SERVER_READ_TIMEOUT":        &cfg.Server.ReadTimeout,
+		"SHOP_SERVER_WRITE_TIMEOUT":       &cfg.Server.WriteTimeout,
+		"SHOP_SERVER_IDLE_TIMEOUT":        &cfg.Server.IdleTimeout,
+		"SHOP_SERVER_SHUTDOWN_TIMEOUT":    &cfg.Server.ShutdownTimeout,
+		"SHOP_DATABASE_CONN_MAX_LIFETIME": &cfg.Database.ConnMaxLifetime,
+		"SHOP_DATABASE_QUERY_TIMEOUT":     &cfg.Database.QueryTimeout,
+		"SHOP_HEALTH_CHECK_TIMEOUT":       &cfg.Health.CheckTimeout,
+		"SHOP_AUTH_ACCESS_TOKEN_TTL":      &cfg.Auth.AccessTokenTTL,
+		"SHOP_AUTH_REFRESH_TOKEN_TTL":     &cfg.Auth.RefreshTokenTTL,
+	}
+	for key, target := range durationVars {
+		if value := getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: invalid duration %q", key, value)
+			}
+			*target = d
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"SHOP_FEATURES_REQUEST_LOGGING": &cfg.Features.RequestLogging,
+		"SHOP_TAX_PRICES_INCLUDE_TAX":   &cfg.Tax.PricesIncludeTax,
+	}
+	for key, target := range boolVars {
+		if value := getenv(key); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: invalid boolean %q", key, value)
+			}
+			*target = b
+		}
+	}
+	return nil
+}
+
+// thumbnailName — допустима назва мініатюри; вона стає частиною імені файлу
+var thumbnailName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Validate перевіряє узгодженість конфігурації та повертає всі знайдені проблеми разом
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("config: "+format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr must not be empty")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	check(c.Server.MaxBodyBytes > 0, "server.max_body_bytes must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	_, err := dialect.ByName(c.Database.Driver)
+	check(err == nil, "database.driver %q must be one of mysql, postgres, sqlite", c.Database.Driver)
+	check(c.Database.DSN != "", "database.dsn must be set (config file, SHOP_DATABASE_DSN or -dsn)")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.QueryTimeout >= 0, "database.query_timeout must not be negative")
+	for route, d := range c.Database.RouteQueryTimeouts {
+		check(d >= 0, "database.route_query_timeouts[%q] must not be negative", route)
+	}
+
+	check(money.ValidCurrency(c.Shop.Currency), "shop.currency %q must be a three-letter ISO 4217 code", c.Shop.Currency)
+	check(c.Auth.BcryptCost >= 4 && c.Auth.BcryptCost <= 31, "auth.bcrypt_cost must be between 4 and 31")
+	check(c.Auth.AccessTokenTTL > 0, "auth.access_token_ttl must be positive")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "auth.refresh_token_ttl must be longer than auth.access_token_ttl")
+	check(c.Payments.Provider == "fake", "payments.provider %q must be fake", c.Payments.Provider)
+	check(c.Health.CheckTimeout >= 0, "health.check_timeout must not be negative")
+
+	check(c.Images.Dir != "", "images.dir must not be empty")
+	check(strings.HasSuffix(c.Images.BaseURL, "/"), "images.base_url %q must end with /", c.Images.BaseURL)
+	check(c.Images.MaxUploadBytes > 0, "images.max_upload_bytes must be positive")
+	check(c.Images.MaxPixels > 0, "images.max_pixels must be positive")
+	thumbnails := make(map[string]bool)
+	for i, t := range c.Images.Thumbnails {
+		check(thumbnailName.MatchString(t.Name) && t.Name != "original", "images.thumbnails[%d].name %q must consist of a-z, 0-9, - or _ and must not be \"original\"", i, t.Name)
+		check(!thumbnails[t.Name], "images.thumbnails[%d].name %q must be unique", i, t.Name)
+		thumbnails[t.Name] = true
+		check(t.Width > 0 && t.Height > 0, "images.thumbnails[%d] width and height must be positive", i)
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level %q must be one of debug, info, warn, error", c.Log.Level)
+	}
+
+	return errors.Join(errs...)
+}
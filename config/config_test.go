@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func envFrom(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shop.yaml")
+	err := os.WriteFile(path, []byte(`
+server:
+  addr: ":8000"
+  read_timeout: 3s
+database:
+  dsn: "file-dsn"
+  max_open_conns: 10
+  max_idle_conns: 5
+  route_query_timeouts:
+    /products: 2s
+log:
+  level: debug
+`), 0o600)
+	assert.NoError(t, err)
+
+	env := envFrom(map[string]string{
+		"SHOP_CONFIG":                  path,
+		"SHOP_DATABASE_DSN":            "env-dsn",
+		"SHOP_DATABASE_MAX_IDLE_CONNS": "2",
+	})
+	cfg, rest, err := Load([]string{"-addr", ":9000", "migrate", "up"}, env)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.Server.Addr)              // прапорець
+	assert.Equal(t, "env-dsn", cfg.Database.DSN)           // змінна оточення
+	assert.Equal(t, 2, cfg.Database.MaxIdleConns)          // змінна оточення
+	assert.Equal(t, 10, cfg.Database.MaxOpenConns)         // файл
+	assert.Equal(t, 3*time.Second, cfg.Server.ReadTimeout) // файл
+	assert.Equal(t, "debug", cfg.Log.Level)                // файл
+	assert.Equal(t, 2*time.Second, cfg.Database.QueryTimeoutFor("/products"))
+	assert.Equal(t, Default().Database.QueryTimeout, cfg.Database.QueryTimeoutFor("/users"))
+	assert.Equal(t, []string{"migrate", "up"}, rest)
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shop.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("database:\n  dns: typo\n"), 0o600))
+
+	_, _, err := Load([]string{"-config", path}, envFrom(nil))
+	assert.Error(t, err)
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := Default()
+	cfg.Database.MaxOpenConns = 2
+	cfg.Database.MaxIdleConns = 5
+	cfg.Log.Level = "verbose"
+	cfg.Database.Driver = "oracle"
+	cfg.Images.Thumbnails = append(cfg.Images.Thumbnails, ThumbnailConfig{Name: "small", Width: 10, Height: 10}, ThumbnailConfig{Name: "../x", Width: 0, Height: 10})
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "database.dsn")
+	assert.ErrorContains(t, err, "max_idle_conns")
+	assert.ErrorContains(t, err, "log.level")
+	assert.ErrorContains(t, err, "database.driver")
+	assert.ErrorContains(t, err, `images.thumbnails[2].name "small" must be unique`)
+	assert.ErrorContains(t, err, `images.thumbnails[3].name "../x"`)
+	assert.ErrorContains(t, err, "images.thumbnails[3] width and height")
+}
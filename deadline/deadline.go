@@ -0,0 +1,39 @@
+// Package deadline обмежує час виконання запитів до бази даних
+// та перетворює помилки контексту на HTTP-статуси.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Middleware додає до контексту запиту граничний час d. Усі запити до бази даних,
+// виконані з r.Context(), скасовуються після його спливу. d <= 0 вимикає обмеження.
+func Middleware(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Status повертає HTTP-статус для помилки сховища: 504 (Gateway Timeout), якщо
+// сплив граничний час запиту, 503 (Service Unavailable), якщо запит скасовано
+// (наприклад, клієнт від'єднався), та 500 для решти помилок.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
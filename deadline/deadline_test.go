@@ -0,0 +1,31 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareSetsDeadline(t *testing.T) {
+	var got time.Time
+	var ok bool
+	h := Middleware(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = r.Context().Deadline()
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), got, 100*time.Millisecond)
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, http.StatusGatewayTimeout, Status(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.Equal(t, http.StatusServiceUnavailable, Status(context.Canceled))
+	assert.Equal(t, http.StatusInternalServerError, Status(errors.New("syntax error")))
+}
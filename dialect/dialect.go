@@ -0,0 +1,131 @@
+// Package dialect приховує відмінності між SQL-базами даних,
+// з якими може працювати сервіс: MySQL, PostgreSQL та SQLite.
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Execer — спільна частина *sql.DB та *sql.Tx, потрібна для InsertID
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Dialect описує особливості конкретної бази даних
+type Dialect interface {
+	// Name повертає назву діалекту, яка також є назвою набору міграцій
+	Name() string
+	// Driver повертає назву драйвера для sql.Open
+	Driver() string
+	// Rebind переписує заповнювачі ? у формат, який розуміє база даних
+	Rebind(query string) string
+	// InsertID виконує INSERT і повертає ID нового рядка
+	InsertID(ctx context.Context, db Execer, query string, args ...interface{}) (int64, error)
+	// TransactionalDDL повідомляє, чи можна відкотити CREATE/ALTER/DROP разом
+	// з транзакцією; MySQL неявно фіксує транзакцію перед кожним таким оператором
+	TransactionalDDL() bool
+	// IsUniqueViolation повідомляє, чи є err порушенням унікального індексу
+	IsUniqueViolation(err error) bool
+}
+
+// MySQL повертає діалект MySQL
+func MySQL() Dialect { return lastInsertIDDialect{name: "mysql", driver: "mysql"} }
+
+// SQLite повертає діалект SQLite (драйвер github.com/mattn/go-sqlite3)
+func SQLite() Dialect {
+	return lastInsertIDDialect{name: "sqlite", driver: "sqlite3", transactionalDDL: true}
+}
+
+// Postgres повертає діалект PostgreSQL (драйвер github.com/lib/pq)
+func Postgres() Dialect { return postgres{} }
+
+// ByName повертає діалект за назвою з конфігурації
+func ByName(name string) (Dialect, error) {
+	switch name {
+	case "mysql":
+		return MySQL(), nil
+	case "sqlite", "sqlite3":
+		return SQLite(), nil
+	case "postgres", "postgresql":
+		return Postgres(), nil
+	}
+	return nil, fmt.Errorf("dialect: unknown database driver %q", name)
+}
+
+// lastInsertIDDialect використовує ? та LastInsertId, як MySQL і SQLite
+type lastInsertIDDialect struct {
+	name             string
+	driver           string
+	transactionalDDL bool
+}
+
+func (d lastInsertIDDialect) Name() string           { return d.name }
+func (d lastInsertIDDialect) Driver() string         { return d.driver }
+func (d lastInsertIDDialect) TransactionalDDL() bool { return d.transactionalDDL }
+
+func (d lastInsertIDDialect) Rebind(query string) string { return query }
+
+func (d lastInsertIDDialect) IsUniqueViolation(err error) bool {
+	if d.name == "mysql" {
+		var mysqlErr *mysql.MySQLError
+		// 1062 — ER_DUP_ENTRY
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+	}
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func (d lastInsertIDDialect) InsertID(ctx context.Context, db Execer, query string, args ...interface{}) (int64, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// postgres використовує нумеровані заповнювачі $1, $2… та RETURNING id,
+// оскільки PostgreSQL не підтримує LastInsertId
+type postgres struct{}
+
+func (postgres) Name() string           { return "postgres" }
+func (postgres) Driver() string         { return "postgres" }
+func (postgres) TransactionalDDL() bool { return true }
+
+func (postgres) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(query[i])
+	}
+	return b.String()
+}
+
+func (postgres) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	// 23505 — unique_violation
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (d postgres) InsertID(ctx context.Context, db Execer, query string, args ...interface{}) (int64, error) {
+	var id int64
+	query = strings.TrimRight(strings.TrimSpace(query), ";") + " RETURNING id"
+	err := db.QueryRowContext(ctx, d.Rebind(query), args...).Scan(&id)
+	return id, err
+}
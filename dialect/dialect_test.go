@@ -0,0 +1,44 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM products WHERE id=? AND category_id=? LIMIT ?"
+
+	assert.Equal(t, query, MySQL().Rebind(query))
+	assert.Equal(t, query, SQLite().Rebind(query))
+	assert.Equal(t, "SELECT * FROM products WHERE id=$1 AND category_id=$2 LIMIT $3", Postgres().Rebind(query))
+}
+
+func TestTransactionalDDL(t *testing.T) {
+	assert.False(t, MySQL().TransactionalDDL())
+	assert.True(t, SQLite().TransactionalDDL())
+	assert.True(t, Postgres().TransactionalDDL())
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	assert.True(t, MySQL().IsUniqueViolation(fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062})))
+	assert.False(t, MySQL().IsUniqueViolation(&mysql.MySQLError{Number: 1452}))
+	assert.True(t, Postgres().IsUniqueViolation(&pq.Error{Code: "23505"}))
+	assert.False(t, Postgres().IsUniqueViolation(errors.New("23505")))
+	assert.False(t, SQLite().IsUniqueViolation(&mysql.MySQLError{Number: 1062}))
+}
+
+func TestByName(t *testing.T) {
+	for name, want := range map[string]string{"mysql": "mysql", "sqlite3": "sqlite", "postgresql": "postgres"} {
+		d, err := ByName(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, d.Name())
+	}
+
+	_, err := ByName("oracle")
+	assert.Error(t, err)
+}
@@ -0,0 +1,408 @@
+// Package discounts реалізує купони та правила знижок: відсоткові та фіксовані
+// знижки, безкоштовну доставку, «купи X — отримай Y» та знижки на категорії.
+package discounts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Типи купонів
+const (
+	KindPercentage   = "percentage"
+	KindFixed        = "fixed"
+	KindFreeShipping = "free_shipping"
+	KindBuyXGetY     = "buy_x_get_y"
+)
+
+// Kinds — усі відомі типи купонів
+var Kinds = []string{KindPercentage, KindFixed, KindFreeShipping, KindBuyXGetY}
+
+// Coupon — код знижки та правило, яке він вмикає
+type Coupon struct {
+	ID int `json:"id"`
+	// Code зберігається у верхньому регістрі; покупці вводять його без урахування регістру
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	// Percent — відсоток знижки для percentage
+	Percent decimal.Decimal `json:"percent"`
+	// Amount — сума знижки для fixed
+	Amount *money.Money `json:"amount,omitempty"`
+	// BuyQuantity та GetQuantity для buy_x_get_y: з кожних Buy+Get одиниць продукту Get безкоштовні
+	BuyQuantity int `json:"buyQuantity"`
+	GetQuantity int `json:"getQuantity"`
+	// CategoryID обмежує знижку продуктами категорії; nil — увесь кошик
+	CategoryID *int `json:"categoryID,omitempty"`
+	// MinTotal — мінімальна сума кошика без знижок
+	MinTotal *money.Money `json:"minTotal,omitempty"`
+	// MaxUses та MaxUsesPerUser обмежують кількість використань; 0 — без обмежень
+	MaxUses        int `json:"maxUses"`
+	MaxUsesPerUser int `json:"maxUsesPerUser"`
+	// Uses — кількість використань; змінюється лише через Redeem
+	Uses int `json:"uses"`
+	// StartsAt та EndsAt задають період дії: StartsAt включно, EndsAt — ні
+	StartsAt  *time.Time `json:"startsAt,omitempty"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CategoryReader — частина categories.CategoryRepository, потрібна для перевірки купонів
+type CategoryReader interface {
+	Get(ctx context.Context, id int) (categories.Category, error)
+}
+
+// DiscountService надає методи для керування купонами та оцінки знижок
+type DiscountService struct {
+	Repo     CouponRepository
+	Products ProductReader
+	// Categories використовується для перевірки існування категорії; nil вимикає перевірку
+	Categories CategoryReader
+	// Now повертає поточний час; nil — time.Now
+	Now func() time.Time
+}
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+// normalizeCode приводить код до вигляду, в якому він зберігається
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// check додає до v правила для полів купона
+func (c Coupon) check(v *validate.Validator) {
+	v.Required("code", c.Code)
+	v.MaxLen("code", c.Code, 64)
+	v.Check(c.Code == "" || codePattern.MatchString(c.Code), "code", "must contain only letters, digits, '-' and '_'")
+	v.MaxLen("description", c.Description, 255)
+
+	switch c.Kind {
+	case KindPercentage:
+		v.Check(c.Percent.IsPositive() && c.Percent.LessThanOrEqual(decimal.NewFromInt(100)), "percent", "must be greater than 0 and at most 100")
+	case KindFixed:
+		v.Check(c.Amount != nil && c.Amount.Amount.IsPositive(), "amount", "must be greater than zero")
+	case KindBuyXGetY:
+		v.Positive("buyQuantity", c.BuyQuantity)
+		v.Positive("getQuantity", c.GetQuantity)
+	case KindFreeShipping:
+	default:
+		v.Check(false, "kind", "must be one of "+strings.Join(Kinds, ", "))
+	}
+	if c.Kind != KindPercentage {
+		v.Check(c.Percent.IsZero(), "percent", "is only allowed for percentage coupons")
+	}
+	if c.Kind != KindFixed {
+		v.Check(c.Amount == nil, "amount", "is only allowed for fixed coupons")
+	}
+	if c.Kind != KindBuyXGetY {
+		v.Check(c.BuyQuantity == 0 && c.GetQuantity == 0, "buyQuantity", "is only allowed for buy_x_get_y coupons")
+	}
+	if c.Amount != nil {
+		v.Check(money.ValidCurrency(c.Amount.Currency), "amount.currency", "must be a three-letter ISO 4217 code")
+	}
+	if c.MinTotal != nil {
+		v.Check(!c.MinTotal.IsNegative(), "minTotal", "must not be negative")
+		v.Check(money.ValidCurrency(c.MinTotal.Currency), "minTotal.currency", "must be a three-letter ISO 4217 code")
+		v.Check(c.Amount == nil || c.Amount.Currency == c.MinTotal.Currency, "minTotal.currency", "must match amount.currency")
+	}
+	if c.CategoryID != nil {
+		v.Positive("categoryID", *c.CategoryID)
+	}
+	v.NonNegative("maxUses", float64(c.MaxUses))
+	v.NonNegative("maxUsesPerUser", float64(c.MaxUsesPerUser))
+	v.Check(c.StartsAt == nil || c.EndsAt == nil || c.EndsAt.After(*c.StartsAt), "endsAt", "must be after startsAt")
+}
+
+// validate нормалізує код та перевіряє поля купона і існування його категорії
+func (s *DiscountService) validate(ctx context.Context, c *Coupon) error {
+	c.Code = normalizeCode(c.Code)
+	v := validate.New()
+	c.check(v)
+	if s.Categories != nil && c.CategoryID != nil && *c.CategoryID > 0 {
+		_, err := s.Categories.Get(ctx, *c.CategoryID)
+		if err == categories.ErrNotFound {
+			v.Check(false, "categoryID", "category does not exist")
+		} else if err != nil {
+			log.Println("Error querying category:", err)
+			return err
+		}
+	}
+	return v.Err()
+}
+
+func (s *DiscountService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
+
+var errCodeTaken = validate.Failed(problem.FieldError{Field: "code", Message: "coupon with this code already exists"})
+
+// couponID повертає числовий ID з URL-параметра {id}
+func couponID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// GetCoupons повертає сторінку купонів
+// GET /discounts/coupons?limit=10&cursor=...&total=true
+func (s *DiscountService) GetCoupons(w http.ResponseWriter, r *http.Request) {
+	v := validate.New()
+	q := pagination.Parse(r.URL.Query(), v, "")
+	if err := v.QueryErr(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	coupons, err := s.Repo.List(r.Context(), q)
+	if err != nil {
+		log.Println("Error querying coupons:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	var list pagination.List[Coupon]
+	list.Data, list.Page = pagination.Build(coupons, q, func(c Coupon) pagination.Cursor {
+		return pagination.Cursor{ID: c.ID}
+	})
+
+	if q.Total {
+		total, err := s.Repo.Count(r.Context())
+		if err != nil {
+			log.Println("Error counting coupons:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		list.Page.Total = &total
+	}
+
+	pagination.SetLink(w, r, list.Page)
+	render.JSON(w, r, http.StatusOK, list)
+}
+
+// GetCoupon повертає купон за ID
+// GET /discounts/coupons/{id}
+func (s *DiscountService) GetCoupon(w http.ResponseWriter, r *http.Request) {
+	id, ok := couponID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	c, err := s.Repo.Get(r.Context(), id)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "coupon %d not found", id))
+		} else {
+			log.Println("Error querying coupon:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, c)
+}
+
+// CreateCoupon додає новий купон
+// POST /discounts/coupons
+func (s *DiscountService) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	var c Coupon
+	if err := validate.DecodeJSON(r, &c); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), &c); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.Create(r.Context(), &c); err != nil {
+		if err == ErrCodeTaken {
+			problem.Write(w, r, errCodeTaken)
+		} else {
+			log.Println("Error inserting coupon:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, c)
+}
+
+// UpdateCoupon замінює купон за ID
+// PUT /discounts/coupons/{id}
+func (s *DiscountService) UpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	id, ok := couponID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var c Coupon
+	if err := validate.DecodeJSON(r, &c); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), &c); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.Update(r.Context(), id, &c); err != nil {
+		switch err {
+		case ErrNotFound:
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "coupon %d not found", id))
+		case ErrCodeTaken:
+			problem.Write(w, r, errCodeTaken)
+		default:
+			log.Println("Error updating coupon:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, c)
+}
+
+// DeleteCoupon видаляє купон за ID
+// DELETE /discounts/coupons/{id}
+func (s *DiscountService) DeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	id, ok := couponID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := s.Repo.Delete(r.Context(), id); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "coupon %d not found", id))
+		} else {
+			log.Println("Error deleting coupon:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Line — продукт і кількість у запиті на оцінку знижок
+type Line struct {
+	ProductID int `json:"productID"`
+	Quantity  int `json:"quantity"`
+}
+
+// EvaluateInput — тіло запиту на оцінку знижок
+type EvaluateInput struct {
+	Items []Line   `json:"items"`
+	Codes []string `json:"codes"`
+}
+
+// Evaluate обчислює знижки для переліку продуктів та введених кодів і пояснює,
+// які правила застосовано, а які коди відхилено і чому. Ліміти на користувача
+// перевіряються для автентифікованого покупця.
+// POST /discounts/evaluate
+func (s *DiscountService) Evaluate(w http.ResponseWriter, r *http.Request) {
+	var input EvaluateInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.Check(len(input.Items) > 0, "items", "must contain at least one item")
+	for i, l := range input.Items {
+		field := "items[" + strconv.Itoa(i) + "]"
+		v.Positive(field+".productID", l.ProductID)
+		v.Positive(field+".quantity", l.Quantity)
+	}
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	lines := make([]CartLine, 0, len(input.Items))
+	for i, l := range input.Items {
+		p, err := s.Products.Get(r.Context(), l.ProductID)
+		if err == products.ErrNotFound {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "product does not exist"}))
+			return
+		}
+		if err != nil {
+			log.Println("Error querying product:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		if len(lines) > 0 && p.Price.Currency != lines[0].UnitPrice.Currency {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "all products must be priced in the same currency"}))
+			return
+		}
+		lines = append(lines, CartLine{ProductID: p.ID, CategoryID: p.CategoryID, Quantity: l.Quantity, UnitPrice: p.Price})
+	}
+
+	principal, _ := rbac.FromContext(r.Context())
+	candidates, unknown, err := s.Candidates(r.Context(), input.Codes, principal.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	e := Evaluate(lines, candidates, principal.UserID, s.now())
+	e.Rejected = append(append([]Rejected{}, unknown...), e.Rejected...)
+	render.JSON(w, r, http.StatusOK, e)
+}
+
+// Candidates знаходить купони за введеними кодами разом з їхніми
+// використаннями користувачем userID. Порожні коди та повтори пропускаються,
+// а невідомі коди повертаються як відхилені.
+func (s *DiscountService) Candidates(ctx context.Context, codes []string, userID int) ([]Candidate, []Rejected, error) {
+	var (
+		candidates []Candidate
+		unknown    []Rejected
+		seen       = make(map[string]bool)
+	)
+	for _, code := range codes {
+		code = normalizeCode(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		c, err := s.Repo.GetByCode(ctx, code)
+		if err == ErrNotFound {
+			unknown = append(unknown, Rejected{Code: code, Reason: "code does not exist"})
+			continue
+		}
+		if err != nil {
+			log.Println("Error querying coupon:", err)
+			return nil, nil, err
+		}
+		usage, err := s.Repo.Usage(ctx, c.ID, userID)
+		if err != nil {
+			log.Println("Error querying coupon usage:", err)
+			return nil, nil, err
+		}
+		candidates = append(candidates, Candidate{Coupon: c, Usage: usage})
+	}
+	return candidates, unknown, nil
+}
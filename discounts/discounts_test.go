@@ -0,0 +1,255 @@
+package discounts_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+)
+
+func intPtr(n int) *int { return &n }
+
+func moneyPtr(amount string) *money.Money {
+	m := money.MustParse(amount, "UAH")
+	return &m
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	lines := []discounts.CartLine{
+		{ProductID: 1, CategoryID: 1, Quantity: 3, UnitPrice: money.MustParse("10.00", "UAH")},
+		{ProductID: 2, CategoryID: 2, Quantity: 2, UnitPrice: money.MustParse("5.00", "UAH")},
+	}
+	candidates := []discounts.Candidate{
+		{Coupon: discounts.Coupon{Code: "FIXED5", Kind: discounts.KindFixed, Amount: moneyPtr("5.00"), Active: true}},
+		{Coupon: discounts.Coupon{Code: "BOOKS10", Kind: discounts.KindPercentage, Percent: decimal.NewFromInt(10), CategoryID: intPtr(1), Active: true}},
+		{Coupon: discounts.Coupon{Code: "B2G1", Kind: discounts.KindBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Active: true}},
+		{Coupon: discounts.Coupon{Code: "SHIP", Kind: discounts.KindFreeShipping, Active: true}},
+		{Coupon: discounts.Coupon{Code: "OLD", Kind: discounts.KindFreeShipping, EndsAt: &yesterday, Active: true}},
+		{Coupon: discounts.Coupon{Code: "BIG", Kind: discounts.KindFreeShipping, MinTotal: moneyPtr("100.00"), Active: true}},
+		{Coupon: discounts.Coupon{Code: "ONCE", Kind: discounts.KindFreeShipping, MaxUsesPerUser: 1, Active: true}},
+		{Coupon: discounts.Coupon{Code: "TOYS", Kind: discounts.KindPercentage, Percent: decimal.NewFromInt(50), CategoryID: intPtr(9), Active: true}},
+		{Coupon: discounts.Coupon{Code: "GONE", Kind: discounts.KindFreeShipping, MaxUses: 3, Active: true}, Usage: discounts.Usage{Total: 3}},
+	}
+
+	e := discounts.Evaluate(lines, candidates, 0, now)
+
+	// Знижки на позиції застосовуються раніше за знижки на замовлення, незалежно від порядку кодів
+	assert.Equal(t, "40.00 UAH", e.Subtotal.String())
+	assert.Equal(t, "13.00 UAH", e.LineDiscount.String())
+	assert.Equal(t, "5.00 UAH", e.OrderDiscount.String())
+	assert.Equal(t, "18.00 UAH", e.Discount.String())
+	assert.Equal(t, "22.00 UAH", e.Total.String())
+	assert.True(t, e.FreeShipping)
+
+	assert.Equal(t, "17.00 UAH", e.Lines[0].Total.String())
+	assert.Len(t, e.Lines[0].Applied, 2)
+	assert.Equal(t, "10% off on category 1", e.Lines[0].Applied[0].Description)
+	assert.Equal(t, "buy 2 get 1 free", e.Lines[0].Applied[1].Description)
+	assert.Empty(t, e.Lines[1].Applied)
+
+	var applied []string
+	for _, a := range e.Applied {
+		applied = append(applied, a.Code)
+	}
+	assert.Equal(t, []string{"BOOKS10", "B2G1", "FIXED5", "SHIP"}, applied)
+
+	reasons := map[string]string{}
+	for _, r := range e.Rejected {
+		reasons[r.Code] = r.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"OLD":  "code expired at 2024-02-29T12:00:00Z",
+		"BIG":  "cart total must be at least 100.00 UAH",
+		"ONCE": "sign in to use this code",
+		"TOYS": "cart has no products from category 9",
+		"GONE": "code usage limit reached",
+	}, reasons)
+
+	// Фіксована знижка не робить суму від'ємною
+	e = discounts.Evaluate(lines[1:], []discounts.Candidate{
+		{Coupon: discounts.Coupon{Code: "BIGFIX", Kind: discounts.KindFixed, Amount: moneyPtr("500.00"), Active: true}},
+	}, 7, now)
+	assert.Equal(t, "0.00 UAH", e.Total.String())
+	assert.Equal(t, "10.00 UAH", e.Discount.String())
+}
+
+// newRouter імітує auth middleware: користувач береться із заголовка X-User
+func newRouter(svc *discounts.DiscountService) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.Atoi(r.Header.Get("X-User"))
+			p := rbac.Principal{UserID: id, Permissions: map[string]bool{}}
+			next.ServeHTTP(w, r.WithContext(rbac.WithPrincipal(r.Context(), p)))
+		})
+	})
+	r.Post("/discounts/evaluate", svc.Evaluate)
+	r.Get("/discounts/coupons", svc.GetCoupons)
+	r.Get("/discounts/coupons/{id}", svc.GetCoupon)
+	r.Post("/discounts/coupons", svc.CreateCoupon)
+	r.Put("/discounts/coupons/{id}", svc.UpdateCoupon)
+	r.Delete("/discounts/coupons/{id}", svc.DeleteCoupon)
+	return r
+}
+
+func do(r http.Handler, method, path, userID, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", userID)
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+// services повертає сервіси на репозиторіях у пам'яті та на SQLite з продуктом категорії 1
+func services(t *testing.T) map[string]*discounts.DiscountService {
+	ctx := context.Background()
+	build := func(cats categories.CategoryRepository, p products.ProductRepository, repo discounts.CouponRepository) *discounts.DiscountService {
+		assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+		assert.NoError(t, p.Create(ctx, &products.Product{Name: "Go", Price: money.MustParse("20.00", "UAH"), StockQuantity: 5, CategoryID: 1}))
+		return &discounts.DiscountService{Repo: repo, Products: p, Categories: cats}
+	}
+
+	memCats := categories.NewMemoryRepository()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	return map[string]*discounts.DiscountService{
+		"memory": build(memCats, products.NewMemoryRepository(memCats), discounts.NewMemoryRepository()),
+		"sqlite": build(sqlCats, products.NewSQLRepository(db, d), discounts.NewSQLRepository(db, d)),
+	}
+}
+
+func TestCoupons(t *testing.T) {
+	for name, svc := range services(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(svc)
+
+			rr := do(r, "POST", "/discounts/coupons", "1", `{"code":" spring-10 ","kind":"percentage","percent":"10","categoryID":1,"minTotal":"15.00","startsAt":"2024-03-01T00:00:00Z","active":true}`)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var c discounts.Coupon
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &c))
+			assert.Equal(t, "SPRING-10", c.Code)
+
+			got, err := svc.Repo.Get(context.Background(), c.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "10", got.Percent.String())
+			assert.Equal(t, "15.00 UAH", got.MinTotal.String())
+			assert.Equal(t, 1, *got.CategoryID)
+			assert.True(t, got.StartsAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+			assert.Nil(t, got.Amount)
+			assert.True(t, got.Active)
+
+			// Код унікальний без урахування регістру; правило перевіряється відповідно до типу
+			rr = do(r, "POST", "/discounts/coupons", "1", `{"code":"Spring-10","kind":"free_shipping"}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), "already exists")
+			rr = do(r, "POST", "/discounts/coupons", "1", `{"code":"BAD CODE","kind":"fixed","percent":"5","categoryID":7}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			for _, field := range []string{"code", "amount", "percent", "categoryID"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+
+			rr = do(r, "PUT", "/discounts/coupons/"+strconv.Itoa(c.ID), "1", `{"code":"SPRING-20","kind":"fixed","amount":"20.00","maxUsesPerUser":1,"active":true}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, http.StatusOK, do(r, "GET", "/discounts/coupons/"+strconv.Itoa(c.ID), "1", "").Code)
+			assert.Equal(t, http.StatusNotFound, do(r, "PUT", "/discounts/coupons/99", "1", `{"code":"X","kind":"free_shipping"}`).Code)
+
+			rr = do(r, "GET", "/discounts/coupons?limit=500&total=true", "1", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var list pagination.List[discounts.Coupon]
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			assert.Len(t, list.Data, 1)
+			assert.Equal(t, "SPRING-20", list.Data[0].Code)
+			assert.Equal(t, pagination.MaxLimit, list.Page.Limit)
+			assert.Equal(t, 1, *list.Page.Total)
+			assert.Contains(t, rr.Header().Get("Link"), `rel="first"`)
+			assert.Equal(t, http.StatusBadRequest, do(r, "GET", "/discounts/coupons?cursor=bogus", "1", "").Code)
+
+			// Оцінка знижок з урахуванням лімітів на користувача
+			evaluate := func(user string) discounts.Evaluation {
+				rr := do(r, "POST", "/discounts/evaluate", user, `{"items":[{"productID":1,"quantity":2}],"codes":["spring-20","nope"]}`)
+				assert.Equal(t, http.StatusOK, rr.Code)
+				var e discounts.Evaluation
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &e))
+				return e
+			}
+			e := evaluate("7")
+			assert.Equal(t, "20.00 UAH", e.Total.String())
+			assert.Equal(t, []discounts.Rejected{{Code: "NOPE", Reason: "code does not exist"}}, e.Rejected)
+			assert.Equal(t, "20.00 UAH off", e.Applied[0].Description)
+
+			assert.NoError(t, svc.Repo.Redeem(context.Background(), []int{c.ID}, 7, 1))
+			assert.Equal(t, discounts.ErrLimitReached, svc.Repo.Redeem(context.Background(), []int{c.ID}, 7, 2))
+			e = evaluate("7")
+			assert.Equal(t, "40.00 UAH", e.Total.String())
+			assert.Len(t, e.Rejected, 2)
+			assert.Equal(t, "20.00 UAH", evaluate("8").Total.String())
+
+			got, err = svc.Repo.Get(context.Background(), c.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, got.Uses)
+
+			rr = do(r, "POST", "/discounts/evaluate", "7", `{"items":[{"productID":9,"quantity":1}]}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"items[0].productID"`)
+
+			assert.Equal(t, http.StatusNoContent, do(r, "DELETE", "/discounts/coupons/"+strconv.Itoa(c.ID), "1", "").Code)
+			assert.Equal(t, http.StatusNotFound, do(r, "GET", "/discounts/coupons/"+strconv.Itoa(c.ID), "1", "").Code)
+		})
+	}
+}
+
+func TestRedeemRespectsTotalLimit(t *testing.T) {
+	for name, svc := range services(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := discounts.Coupon{Code: "LAUNCH", Kind: discounts.KindFreeShipping, MaxUses: 2, Active: true}
+			assert.NoError(t, svc.Repo.Create(ctx, &c))
+			assert.NoError(t, svc.Repo.Redeem(ctx, []int{c.ID}, 1, 1))
+			assert.NoError(t, svc.Repo.Redeem(ctx, []int{c.ID}, 2, 2))
+			assert.Equal(t, discounts.ErrLimitReached, svc.Repo.Redeem(ctx, []int{c.ID}, 3, 3))
+			assert.Equal(t, discounts.ErrNotFound, svc.Repo.Redeem(ctx, []int{99}, 3, 3))
+
+			u, err := svc.Repo.Usage(ctx, c.ID, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, discounts.Usage{Total: 2, ByUser: 1}, u)
+
+			// Якщо ліміт одного з купонів вичерпано, інші теж не списуються
+			other := discounts.Coupon{Code: "WELCOME", Kind: discounts.KindFreeShipping, Active: true}
+			assert.NoError(t, svc.Repo.Create(ctx, &other))
+			assert.Equal(t, discounts.ErrLimitReached, svc.Repo.Redeem(ctx, []int{other.ID, c.ID}, 3, 4))
+			u, err = svc.Repo.Usage(ctx, other.ID, 3)
+			assert.NoError(t, err)
+			assert.Equal(t, discounts.Usage{}, u)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package discounts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// CartLine — позиція кошика з ціною та категорією продукту
+type CartLine struct {
+	ProductID  int
+	CategoryID int
+	Quantity   int
+	UnitPrice  money.Money
+}
+
+// Candidate — купон, введений покупцем, разом з кількістю його використань
+type Candidate struct {
+	Coupon Coupon
+	Usage  Usage
+}
+
+// Applied пояснює, яке правило спрацювало і на яку суму
+type Applied struct {
+	Code        string      `json:"code"`
+	Kind        string      `json:"kind"`
+	Amount      money.Money `json:"amount"`
+	Description string      `json:"description"`
+}
+
+// Rejected — введений купон, що не застосовано, з причиною
+type Rejected struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// LineResult — позиція кошика зі знижками, що застосовано саме до неї
+type LineResult struct {
+	ProductID int         `json:"productID"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unitPrice"`
+	Subtotal  money.Money `json:"subtotal"`
+	Discount  money.Money `json:"discount"`
+	Total     money.Money `json:"total"`
+	Applied   []Applied   `json:"applied"`
+}
+
+// Evaluation — результат оцінки знижок для кошика
+type Evaluation struct {
+	Lines []LineResult `json:"lines"`
+	// Subtotal — сума без знижок
+	Subtotal money.Money `json:"subtotal"`
+	// LineDiscount — сума знижок на окремі позиції
+	LineDiscount money.Money `json:"lineDiscount"`
+	// OrderDiscount — сума знижок на замовлення загалом
+	OrderDiscount money.Money `json:"orderDiscount"`
+	Discount      money.Money `json:"discount"`
+	Total         money.Money `json:"total"`
+	FreeShipping  bool        `json:"freeShipping"`
+	// Applied — застосовані купони у порядку застосування із загальною сумою кожного
+	Applied  []Applied  `json:"applied"`
+	Rejected []Rejected `json:"rejected"`
+}
+
+// Evaluate обчислює знижки для позицій однієї валюти. Спочатку застосовуються
+// знижки на позиції (відсоток на категорію, «купи X — отримай Y»), потім знижки
+// на замовлення (відсоток, фіксована сума, безкоштовна доставка). Кожна знижка
+// рахується від залишку після попередніх, тож сума ніколи не стає від'ємною.
+// userID дорівнює 0 для анонімного покупця.
+func Evaluate(lines []CartLine, candidates []Candidate, userID int, now time.Time) Evaluation {
+	currency := money.DefaultCurrency
+	if len(lines) > 0 {
+		currency = lines[0].UnitPrice.Currency
+	}
+	zero := money.Zero(currency)
+
+	e := Evaluation{Lines: make([]LineResult, len(lines)), Subtotal: zero, Applied: []Applied{}, Rejected: []Rejected{}}
+	for i, l := range lines {
+		subtotal := l.UnitPrice.Mul(int64(l.Quantity))
+		e.Lines[i] = LineResult{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			UnitPrice: l.UnitPrice,
+			Subtotal:  subtotal,
+			Discount:  zero,
+			Total:     subtotal,
+			Applied:   []Applied{},
+		}
+		e.Subtotal = plus(e.Subtotal, subtotal)
+	}
+
+	var eligible []Coupon
+	for _, c := range candidates {
+		if reason := c.Coupon.reject(lines, e.Subtotal, c.Usage, userID, now); reason != "" {
+			e.Rejected = append(e.Rejected, Rejected{Code: c.Coupon.Code, Reason: reason})
+			continue
+		}
+		eligible = append(eligible, c.Coupon)
+	}
+
+	for _, c := range eligible {
+		if c.lineLevel() {
+			e.applyToLines(c, lines)
+		}
+	}
+	e.LineDiscount = zero
+	for _, l := range e.Lines {
+		e.LineDiscount = plus(e.LineDiscount, l.Discount)
+	}
+
+	e.OrderDiscount = zero
+	e.Total = minus(e.Subtotal, e.LineDiscount)
+	for _, c := range eligible {
+		if !c.lineLevel() {
+			e.applyToOrder(c, lines)
+		}
+	}
+	e.Discount = plus(e.LineDiscount, e.OrderDiscount)
+	return e
+}
+
+// applyToLines застосовує знижку до кожної позиції, на яку поширюється купон
+func (e *Evaluation) applyToLines(c Coupon, lines []CartLine) {
+	total := money.Zero(e.Subtotal.Currency)
+	for i, l := range lines {
+		if !c.covers(l) {
+			continue
+		}
+		line := &e.Lines[i]
+		var d money.Money
+		switch c.Kind {
+		case KindPercentage:
+			d = line.Total.Percent(c.Percent)
+		case KindBuyXGetY:
+			free := l.Quantity / (c.BuyQuantity + c.GetQuantity) * c.GetQuantity
+			d = l.UnitPrice.Mul(int64(free))
+		}
+		d = capAt(d, line.Total)
+		if d.IsZero() {
+			continue
+		}
+		line.Discount = plus(line.Discount, d)
+		line.Total = minus(line.Total, d)
+		line.Applied = append(line.Applied, Applied{Code: c.Code, Kind: c.Kind, Amount: d, Description: c.explain()})
+		total = plus(total, d)
+	}
+	if total.IsZero() {
+		e.Rejected = append(e.Rejected, Rejected{Code: c.Code, Reason: "code does not reduce this cart"})
+		return
+	}
+	e.Applied = append(e.Applied, Applied{Code: c.Code, Kind: c.Kind, Amount: total, Description: c.explain()})
+}
+
+// applyToOrder застосовує знижку до залишку суми замовлення; фіксована
+// знижка на категорію не перевищує залишок позицій цієї категорії
+func (e *Evaluation) applyToOrder(c Coupon, lines []CartLine) {
+	d := money.Zero(e.Subtotal.Currency)
+	switch c.Kind {
+	case KindPercentage:
+		d = e.Total.Percent(c.Percent)
+	case KindFixed:
+		limit := e.Total
+		if c.CategoryID != nil {
+			limit = money.Zero(e.Subtotal.Currency)
+			for i, l := range lines {
+				if c.covers(l) {
+					limit = plus(limit, e.Lines[i].Total)
+				}
+			}
+		}
+		d = capAt(*c.Amount, limit)
+	case KindFreeShipping:
+		e.FreeShipping = true
+	}
+	d = capAt(d, e.Total)
+	if d.IsZero() && c.Kind != KindFreeShipping {
+		e.Rejected = append(e.Rejected, Rejected{Code: c.Code, Reason: "code does not reduce this cart"})
+		return
+	}
+	e.OrderDiscount = plus(e.OrderDiscount, d)
+	e.Total = minus(e.Total, d)
+	e.Applied = append(e.Applied, Applied{Code: c.Code, Kind: c.Kind, Amount: d, Description: c.explain()})
+}
+
+// reject повертає причину, з якої купон не можна застосувати, або порожній рядок
+func (c Coupon) reject(lines []CartLine, subtotal money.Money, usage Usage, userID int, now time.Time) string {
+	switch {
+	case !c.Active:
+		return "code is not active"
+	case c.StartsAt != nil && now.Before(*c.StartsAt):
+		return "code is not valid until " + c.StartsAt.UTC().Format(time.RFC3339)
+	case c.EndsAt != nil && !now.Before(*c.EndsAt):
+		return "code expired at " + c.EndsAt.UTC().Format(time.RFC3339)
+	case c.MaxUses > 0 && usage.Total >= c.MaxUses:
+		return "code usage limit reached"
+	case c.MaxUsesPerUser > 0 && userID == 0:
+		return "sign in to use this code"
+	case c.MaxUsesPerUser > 0 && usage.ByUser >= c.MaxUsesPerUser:
+		return "you have already used this code the maximum number of times"
+	case c.Amount != nil && c.Amount.Currency != subtotal.Currency,
+		c.MinTotal != nil && c.MinTotal.Currency != subtotal.Currency:
+		return "code does not apply to prices in " + subtotal.Currency
+	case c.MinTotal != nil && subtotal.Amount.LessThan(c.MinTotal.Amount):
+		return "cart total must be at least " + c.MinTotal.String()
+	}
+	if c.CategoryID != nil {
+		for _, l := range lines {
+			if c.covers(l) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("cart has no products from category %d", *c.CategoryID)
+	}
+	return ""
+}
+
+// lineLevel повідомляє, чи застосовується знижка до окремих позицій
+func (c Coupon) lineLevel() bool {
+	return c.Kind == KindBuyXGetY || c.Kind == KindPercentage && c.CategoryID != nil
+}
+
+// covers повідомляє, чи поширюється купон на позицію
+func (c Coupon) covers(l CartLine) bool {
+	return c.CategoryID == nil || *c.CategoryID == l.CategoryID
+}
+
+// explain описує правило купона для відповіді evaluate
+func (c Coupon) explain() string {
+	var s string
+	switch c.Kind {
+	case KindPercentage:
+		s = c.Percent.String() + "% off"
+	case KindFixed:
+		s = c.Amount.String() + " off"
+	case KindFreeShipping:
+		s = "free shipping"
+	case KindBuyXGetY:
+		s = fmt.Sprintf("buy %d get %d free", c.BuyQuantity, c.GetQuantity)
+	}
+	if c.CategoryID != nil {
+		s += fmt.Sprintf(" on category %d", *c.CategoryID)
+	}
+	return s
+}
+
+// capAt повертає меншу з сум d та limit
+func capAt(d, limit money.Money) money.Money {
+	if d.Amount.GreaterThan(limit.Amount) {
+		return limit
+	}
+	return d
+}
+
+// plus та minus працюють із сумами, валюту яких уже перевірено
+func plus(a, b money.Money) money.Money {
+	return money.Money{Amount: a.Amount.Add(b.Amount), Currency: a.Currency}
+}
+
+func minus(a, b money.Money) money.Money {
+	return money.Money{Amount: a.Amount.Sub(b.Amount), Currency: a.Currency}
+}
@@ -0,0 +1,217 @@
+package discounts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+type redemption struct {
+	couponID, userID, orderID int
+}
+
+// MemoryRepository зберігає купони у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки.
+type MemoryRepository struct {
+	mu          sync.Mutex
+	coupons     map[int]Coupon
+	redemptions []redemption
+	nextID      int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{coupons: make(map[int]Coupon), nextID: 1}
+}
+
+func (m *MemoryRepository) List(ctx context.Context, q pagination.Query) ([]Coupon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]int, 0, len(m.coupons))
+	for id := range m.coupons {
+		ids = append(ids, id)
+	}
+
+	coupons := []Coupon{}
+	for _, id := range pagination.SelectIDs(ids, q) {
+		coupons = append(coupons, m.coupons[id])
+	}
+	return coupons, nil
+}
+
+func (m *MemoryRepository) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.coupons), nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Coupon, error) {
+	if err := ctx.Err(); err != nil {
+		return Coupon{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.coupons[id]
+	if !ok {
+		return Coupon{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryRepository) GetByCode(ctx context.Context, code string) (Coupon, error) {
+	if err := ctx.Err(); err != nil {
+		return Coupon{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	code = normalizeCode(code)
+	for _, c := range m.coupons {
+		if c.Code == code {
+			return c, nil
+		}
+	}
+	return Coupon{}, ErrNotFound
+}
+
+// codeTaken повідомляє, чи використано код іншим купоном; викликається під m.mu
+func (m *MemoryRepository) codeTaken(code string, exceptID int) bool {
+	for id, c := range m.coupons {
+		if c.Code == code && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, c *Coupon) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.codeTaken(c.Code, 0) {
+		return ErrCodeTaken
+	}
+	now := time.Now()
+	c.ID = m.nextID
+	m.nextID++
+	c.Uses = 0
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	m.coupons[c.ID] = *c
+	return nil
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, id int, c *Coupon) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.coupons[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.codeTaken(c.Code, id) {
+		return ErrCodeTaken
+	}
+	c.ID = id
+	c.Uses = existing.Uses
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+	m.coupons[id] = *c
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.coupons[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.coupons, id)
+	kept := m.redemptions[:0]
+	for _, r := range m.redemptions {
+		if r.couponID != id {
+			kept = append(kept, r)
+		}
+	}
+	m.redemptions = kept
+	return nil
+}
+
+func (m *MemoryRepository) Usage(ctx context.Context, couponID, userID int) (Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return Usage{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.usage(couponID, userID), nil
+}
+
+// usage підраховує використання купона; викликається під m.mu
+func (m *MemoryRepository) usage(couponID, userID int) Usage {
+	u := Usage{Total: m.coupons[couponID].Uses}
+	for _, r := range m.redemptions {
+		if r.couponID == couponID && r.userID == userID && userID != 0 {
+			u.ByUser++
+		}
+	}
+	return u
+}
+
+func (m *MemoryRepository) Redeem(ctx context.Context, couponIDs []int, userID, orderID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Спершу перевіряємо всі купони, щоб не записати лише частину з них
+	for _, couponID := range couponIDs {
+		stored, ok := m.coupons[couponID]
+		if !ok {
+			return ErrNotFound
+		}
+		u := m.usage(couponID, userID)
+		if stored.MaxUses > 0 && u.Total >= stored.MaxUses ||
+			stored.MaxUsesPerUser > 0 && u.ByUser >= stored.MaxUsesPerUser {
+			return ErrLimitReached
+		}
+	}
+	for _, couponID := range couponIDs {
+		stored := m.coupons[couponID]
+		stored.Uses++
+		m.coupons[couponID] = stored
+		m.redemptions = append(m.redemptions, redemption{couponID: couponID, userID: userID, orderID: orderID})
+	}
+	return nil
+}
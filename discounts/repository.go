@@ -0,0 +1,55 @@
+package discounts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// ErrNotFound повертається репозиторієм, якщо купона не існує
+var ErrNotFound = errors.New("discounts: not found")
+
+// ErrCodeTaken повертається репозиторієм, якщо купон з таким кодом уже існує
+var ErrCodeTaken = errors.New("discounts: code already exists")
+
+// ErrLimitReached повертається Redeem, якщо ліміт використань купона вичерпано
+var ErrLimitReached = errors.New("discounts: usage limit reached")
+
+// Usage — кількість використань купона
+type Usage struct {
+	Total int
+	// ByUser — використання конкретним користувачем
+	ByUser int
+}
+
+// CouponRepository описує сховище купонів, з яким працює DiscountService
+type CouponRepository interface {
+	// List повертає до q.Limit+1 купонів після межі курсора, впорядкованих за ID
+	// (для зворотного курсора — перед межею, за спаданням ID)
+	List(ctx context.Context, q pagination.Query) ([]Coupon, error)
+	// Count повертає кількість усіх купонів
+	Count(ctx context.Context) (int, error)
+	// Get повертає купон або ErrNotFound
+	Get(ctx context.Context, id int) (Coupon, error)
+	// GetByCode повертає купон за кодом без урахування регістру або ErrNotFound
+	GetByCode(ctx context.Context, code string) (Coupon, error)
+	// Create зберігає новий купон та заповнює ID і дати; повертає ErrCodeTaken
+	Create(ctx context.Context, c *Coupon) error
+	// Update замінює купон за ID; повертає ErrNotFound або ErrCodeTaken
+	Update(ctx context.Context, id int, c *Coupon) error
+	// Delete видаляє купон разом з історією використань або повертає ErrNotFound
+	Delete(ctx context.Context, id int) error
+	// Usage повертає кількість використань купона загалом та користувачем userID
+	Usage(ctx context.Context, couponID, userID int) (Usage, error)
+	// Redeem записує використання купонів користувачем для замовлення orderID,
+	// атомарно перевіряючи їхні ліміти; якщо ліміт хоча б одного вичерпано,
+	// нічого не записує та повертає ErrLimitReached
+	Redeem(ctx context.Context, couponIDs []int, userID, orderID int) error
+}
+
+// ProductReader — частина products.ProductRepository, потрібна для оцінки знижок
+type ProductReader interface {
+	Get(ctx context.Context, id int) (products.Product, error)
+}
@@ -0,0 +1,296 @@
+package discounts
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// SQLRepository зберігає купони у таблицях coupons та coupon_redemptions SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+const couponColumns = "id, code, description, kind, percent, amount, min_total, currency, buy_quantity, get_quantity, category_id, max_uses, max_uses_per_user, uses, starts_at, ends_at, active, created_at, updated_at"
+
+func scanCoupon(row interface{ Scan(...interface{}) error }) (Coupon, error) {
+	var (
+		c                Coupon
+		amount, minTotal decimal.NullDecimal
+		currency         sql.NullString
+		categoryID       sql.NullInt64
+		startsAt, endsAt sql.NullTime
+	)
+	err := row.Scan(&c.ID, &c.Code, &c.Description, &c.Kind, &c.Percent, &amount, &minTotal, &currency,
+		&c.BuyQuantity, &c.GetQuantity, &categoryID, &c.MaxUses, &c.MaxUsesPerUser, &c.Uses,
+		&startsAt, &endsAt, &c.Active, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return Coupon{}, err
+	}
+	if amount.Valid {
+		m := money.New(amount.Decimal, currency.String)
+		c.Amount = &m
+	}
+	if minTotal.Valid {
+		m := money.New(minTotal.Decimal, currency.String)
+		c.MinTotal = &m
+	}
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		c.CategoryID = &id
+	}
+	if startsAt.Valid {
+		c.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		c.EndsAt = &endsAt.Time
+	}
+	return c, nil
+}
+
+// columnValues повертає значення стовпців купона від code до active
+func columnValues(c *Coupon) []interface{} {
+	var (
+		amount, minTotal decimal.NullDecimal
+		currency         sql.NullString
+	)
+	if c.Amount != nil {
+		amount = decimal.NullDecimal{Decimal: c.Amount.Amount, Valid: true}
+		currency = sql.NullString{String: c.Amount.Currency, Valid: true}
+	}
+	if c.MinTotal != nil {
+		minTotal = decimal.NullDecimal{Decimal: c.MinTotal.Amount, Valid: true}
+		currency = sql.NullString{String: c.MinTotal.Currency, Valid: true}
+	}
+	var startsAt, endsAt sql.NullTime
+	if c.StartsAt != nil {
+		startsAt = sql.NullTime{Time: c.StartsAt.UTC(), Valid: true}
+	}
+	if c.EndsAt != nil {
+		endsAt = sql.NullTime{Time: c.EndsAt.UTC(), Valid: true}
+	}
+	return []interface{}{c.Code, c.Description, c.Kind, c.Percent, amount, minTotal, currency,
+		c.BuyQuantity, c.GetQuantity, c.CategoryID, c.MaxUses, c.MaxUsesPerUser, startsAt, endsAt, c.Active}
+}
+
+func (m *SQLRepository) List(ctx context.Context, q pagination.Query) ([]Coupon, error) {
+	key := []pagination.Column{{Expr: "id"}}
+	query := "SELECT " + couponColumns + " FROM coupons"
+	var args []interface{}
+	if q.Cursor != nil {
+		var where string
+		where, args = pagination.Keyset(key, []interface{}{q.Cursor.ID}, q.Backward())
+		query += " WHERE " + where
+	}
+	query += " ORDER BY " + pagination.OrderBy(key, q.Backward()) + " LIMIT ?"
+	args = append(args, q.Limit+1)
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := []Coupon{}
+	for rows.Next() {
+		c, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, c)
+	}
+	return coupons, rows.Err()
+}
+
+func (m *SQLRepository) Count(ctx context.Context) (int, error) {
+	var n int
+	err := m.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM coupons").Scan(&n)
+	return n, err
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (Coupon, error) {
+	c, err := scanCoupon(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+couponColumns+" FROM coupons WHERE id = ?"), id))
+	if err == sql.ErrNoRows {
+		return Coupon{}, ErrNotFound
+	}
+	return c, err
+}
+
+func (m *SQLRepository) GetByCode(ctx context.Context, code string) (Coupon, error) {
+	c, err := scanCoupon(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+couponColumns+" FROM coupons WHERE code = ?"), normalizeCode(code)))
+	if err == sql.ErrNoRows {
+		return Coupon{}, ErrNotFound
+	}
+	return c, err
+}
+
+// codeTaken повідомляє, чи використано код іншим купоном
+func (m *SQLRepository) codeTaken(ctx context.Context, code string, exceptID int) (bool, error) {
+	c, err := m.GetByCode(ctx, code)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return c.ID != exceptID, nil
+}
+
+func (m *SQLRepository) Create(ctx context.Context, c *Coupon) error {
+	if taken, err := m.codeTaken(ctx, c.Code, 0); err != nil || taken {
+		if taken {
+			return ErrCodeTaken
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	args := append(columnValues(c), now, now)
+	id, err := m.Dialect.InsertID(ctx, m.DB, `INSERT INTO coupons (code, description, kind, percent, amount, min_total, currency,
+		buy_quantity, get_quantity, category_id, max_uses, max_uses_per_user, starts_at, ends_at, active, uses, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?)`, args...)
+	if err != nil {
+		return err
+	}
+	c.ID = int(id)
+	c.Uses = 0
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Update(ctx context.Context, id int, c *Coupon) error {
+	existing, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if taken, err := m.codeTaken(ctx, c.Code, id); err != nil || taken {
+		if taken {
+			return ErrCodeTaken
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	args := append(columnValues(c), now, id)
+	_, err = m.DB.ExecContext(ctx, m.Dialect.Rebind(`UPDATE coupons SET code = ?, description = ?, kind = ?, percent = ?, amount = ?, min_total = ?, currency = ?,
+		buy_quantity = ?, get_quantity = ?, category_id = ?, max_uses = ?, max_uses_per_user = ?, starts_at = ?, ends_at = ?, active = ?, updated_at = ?
+		WHERE id = ?`), args...)
+	if err != nil {
+		return err
+	}
+	c.ID = id
+	c.Uses = existing.Uses
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM coupons WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM coupon_redemptions WHERE coupon_id = ?"), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *SQLRepository) Usage(ctx context.Context, couponID, userID int) (Usage, error) {
+	var u Usage
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT uses FROM coupons WHERE id = ?"), couponID).Scan(&u.Total)
+	if err == sql.ErrNoRows {
+		return Usage{}, ErrNotFound
+	}
+	if err != nil || userID == 0 {
+		return u, err
+	}
+	err = m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = ? AND user_id = ?"), couponID, userID).Scan(&u.ByUser)
+	return u, err
+}
+
+func (m *SQLRepository) Redeem(ctx context.Context, couponIDs []int, userID, orderID int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RedeemTx(ctx, tx, m.Dialect, couponIDs, userID, orderID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RedeemTx записує використання купонів у транзакції tx, яку відкрив
+// викликач, — так оформлення замовлення списує купони разом із товаром
+func RedeemTx(ctx context.Context, tx *sql.Tx, d dialect.Dialect, couponIDs []int, userID, orderID int) error {
+	for _, couponID := range couponIDs {
+		// Умова на uses перевіряється атомарно з оновленням, а заблокований рядок
+		// купона впорядковує одночасні використання, тож ліміт на користувача
+		// нижче перевіряється без гонок
+		result, err := tx.ExecContext(ctx, d.Rebind("UPDATE coupons SET uses = uses + 1 WHERE id = ? AND (max_uses = 0 OR uses < max_uses)"), couponID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		var maxPerUser int
+		err = tx.QueryRowContext(ctx, d.Rebind("SELECT max_uses_per_user FROM coupons WHERE id = ?"), couponID).Scan(&maxPerUser)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrLimitReached
+		}
+
+		if maxPerUser > 0 {
+			var used int
+			err := tx.QueryRowContext(ctx, d.Rebind("SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = ? AND user_id = ?"), couponID, userID).Scan(&used)
+			if err != nil {
+				return err
+			}
+			if used >= maxPerUser {
+				return ErrLimitReached
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, d.Rebind("INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, created_at) VALUES (?, ?, ?, ?)"),
+			couponID, userID, orderID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
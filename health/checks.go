@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBPing перевіряє, що пул з'єднань може досягти бази даних
+func DBPing(db *sql.DB) Check {
+	return db.PingContext
+}
+
+// VersionSource повертає поточну та очікувану версії схеми
+type VersionSource interface {
+	Current() (int, error)
+	Latest() int
+}
+
+// MigrationVersion перевіряє, що схема бази даних застосована до останньої
+// вбудованої міграції
+func MigrationVersion(src VersionSource) Check {
+	return func(ctx context.Context) error {
+		current, err := src.Current()
+		if err != nil {
+			return err
+		}
+		if latest := src.Latest(); current != latest {
+			return fmt.Errorf("schema version %d, expected %d", current, latest)
+		}
+		return nil
+	}
+}
+
+// DiskSpace перевіряє, що у файловій системі з каталогом path вільно
+// щонайменше minFree байтів
+func DiskSpace(path string, minFree uint64) Check {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s: %d bytes free, need at least %d", path, free, minFree)
+		}
+		return nil
+	}
+}
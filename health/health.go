@@ -0,0 +1,134 @@
+// Package health містить реєстр перевірок залежностей сервісу та
+// HTTP-обробники /healthz (liveness) і /readyz (readiness).
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Check перевіряє одну залежність; nil означає, що залежність справна
+type Check func(ctx context.Context) error
+
+// Статуси перевірок та звіту
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckResult описує результат однієї перевірки
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report — зведений результат усіх перевірок
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry зберігає зареєстровані перевірки; безпечний для одночасного використання
+type Registry struct {
+	// Timeout обмежує час кожної перевірки; 0 означає без обмеження
+	Timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewRegistry створює порожній реєстр з граничним часом перевірки timeout
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{Timeout: timeout}
+}
+
+// Register додає перевірку під назвою name
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Run паралельно виконує всі перевірки та повертає звіт у порядку реєстрації
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, c namedCheck) CheckResult {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := c.check(ctx)
+	result := CheckResult{
+		Name:      c.name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness відповідає 200, поки процес здатен обробляти HTTP-запити
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+}
+
+// Readiness виконує всі перевірки та відповідає 200, якщо всі залежності
+// справні, або 503 (Service Unavailable) зі звітом про кожну перевірку
+func (r *Registry) Readiness(w http.ResponseWriter, req *http.Request) {
+	report := r.Run(req.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error encoding JSON:", err)
+	}
+}
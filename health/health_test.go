@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVersions struct{ current, latest int }
+
+func (f fakeVersions) Current() (int, error) { return f.current, nil }
+func (f fakeVersions) Latest() int           { return f.latest }
+
+func TestReadiness(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("ok", func(context.Context) error { return nil })
+	registry.Register("migrations", MigrationVersion(fakeVersions{current: 2, latest: 3}))
+
+	rr := httptest.NewRecorder()
+	registry.Readiness(rr, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusUp, report.Checks[0].Status)
+	assert.Equal(t, "schema version 2, expected 3", report.Checks[1].Error)
+}
+
+func TestCheckTimeout(t *testing.T) {
+	registry := NewRegistry(10 * time.Millisecond)
+	registry.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := registry.Run(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestDiskSpace(t *testing.T) {
+	assert.NoError(t, DiskSpace(t.TempDir(), 1)(context.Background()))
+	assert.Error(t, DiskSpace(t.TempDir(), ^uint64(0))(context.Background()))
+}
@@ -0,0 +1,74 @@
+// Package lifecycle керує запуском та зупинкою компонентів сервісу:
+// HTTP-сервера, фонових обробників та пулу з'єднань до бази даних.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook описує компонент з необов'язковими діями запуску та зупинки
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Lifecycle зберігає зареєстровані хуки. Хуки запускаються в порядку
+// реєстрації та зупиняються у зворотному, тож залежності (наприклад, база даних)
+// реєструються першими і закриваються останніми.
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// Append реєструє хук; викликається до Start
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// Start запускає хуки по черзі. Якщо один з них повертає помилку,
+// уже запущені хуки зупиняються, а помилка повертається викликачу.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		h := l.hooks[l.started]
+		if h.Start != nil {
+			if err := h.Start(ctx); err != nil {
+				startErr := fmt.Errorf("lifecycle: starting %s: %w", h.Name, err)
+				return errors.Join(startErr, l.stopLocked(ctx))
+			}
+		}
+		l.started++
+	}
+	return nil
+}
+
+// Stop зупиняє запущені хуки у зворотному порядку та повертає всі помилки разом.
+// Контекст задає граничний час на зупинку.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stopLocked(ctx)
+}
+
+func (l *Lifecycle) stopLocked(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.Stop == nil {
+			continue
+		}
+		if err := h.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle: stopping %s: %w", h.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
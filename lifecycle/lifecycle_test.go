@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingHook(name string, calls *[]string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestStartStopOrder(t *testing.T) {
+	var calls []string
+	var l Lifecycle
+	l.Append(recordingHook("db", &calls, nil))
+	l.Append(recordingHook("http", &calls, nil))
+
+	assert.NoError(t, l.Start(context.Background()))
+	assert.NoError(t, l.Stop(context.Background()))
+	assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, calls)
+
+	// Повторна зупинка нічого не робить
+	assert.NoError(t, l.Stop(context.Background()))
+	assert.Len(t, calls, 4)
+}
+
+func TestStartFailureStopsStartedHooks(t *testing.T) {
+	var calls []string
+	var l Lifecycle
+	l.Append(recordingHook("db", &calls, nil))
+	l.Append(recordingHook("http", &calls, errors.New("address in use")))
+	l.Append(recordingHook("worker", &calls, nil))
+
+	err := l.Start(context.Background())
+	assert.ErrorContains(t, err, "starting http")
+	assert.Equal(t, []string{"start db", "start http", "stop db"}, calls)
+}
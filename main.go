@@ -1,529 +1,344 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
-	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
-	"time"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 
-	//	"github.com/shopspring/decimal"
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/cart"
 	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/config"
+	"github.com/chitawebui131/shop_go/deadline"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/health"
+	"github.com/chitawebui131/shop_go/lifecycle"
+	"github.com/chitawebui131/shop_go/media"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/orders"
+	"github.com/chitawebui131/shop_go/payments"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/shipping"
+	"github.com/chitawebui131/shop_go/tax"
 	"github.com/chitawebui131/shop_go/user"
+	"github.com/chitawebui131/shop_go/validate"
 )
 
-// Product представляє модель продукту
-type Product struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Price         float64   `json:"price"`
-	StockQuantity int       `json:"stockQuantity"`
-	CategoryID    int       `json:"categoryID"`
-	Created_at    time.Time `json:"created_at"`
-	Updated_at    time.Time `json:"updated_at"`
-}
-
-type Category struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
-
-type ProductWithCategoryWithoutDates struct {
-	ProductID           int     `json:"product_id"`
-	ProductName         string  `json:"product_name"`
-	ProductDescription  string  `json:"product_description"`
-	ProductPrice        float64 `json:"product_price"`
-	StockQuantity       int     `json:"product_stockQuantity"`
-	ProductCategoryID   int     `json:"product_category_id"`
-	CategoryID          int     `json:"category_id"`
-	CategoryName        string  `json:"category_name"`
-	CategoryDescription string  `json:"category_description"`
-}
-
-// ProductService надає методи для роботи з продуктами
-type ProductService struct {
-	DB *sql.DB
-}
-
-// GetProducts повертає список усіх продуктів з пагінацією
-//GET /api/products?page=1&limit=10
-/*
-func (s *ProductService) GetProducts(w http.ResponseWriter, r *http.Request) {
-	// Отримання значень параметрів пагінації
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
-	}
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = 10 // За замовчуванням 10 елементів на сторінці
-	}
-
-	// Розрахунок зсуву (offset) для пагінації
-	offset := (page - 1) * limit
-
-	// Вибірка продуктів з бази даних з пагінацією
-	rows, err := s.DB.Query("SELECT * FROM products JOIN categories ON products.category_id = categories.id LIMIT ? OFFSET ?", limit, offset)
-	if err != nil {
-		log.Println("Error querying database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	// Створення слайсу для зберігання результатів
-	var products []Product
-
-	// Зчитування результатів запиту
-	for rows.Next() {
-		var product Product
-		if err := rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.StockQuantity, &product.CategoryID, &product.Created_at, &product.Updated_at ); err != nil {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		products = append(products, product)
-	}
-
-	// Перевірка наявності помилок під час зчитування
-	if err := rows.Err(); err != nil {
-		log.Println("Error reading rows:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(products); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
 	}
 }
 
-func (s *ProductService) GetProducts(w http.ResponseWriter, r *http.Request) {
-	// Отримання значень параметрів пагінації
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
-	}
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = 10 // За замовчуванням 10 елементів на сторінці
-	}
-
-	// Розрахунок зсуву (offset) для пагінації
-	offset := (page - 1) * limit
-
-	// Вибірка продуктів з бази даних з пагінацією
-	query := `
-		SELECT products.id AS product_id, products.name AS product_name,
-			   products.description AS product_description, products.price AS product_price,
-			   products.stock_quantity AS product_stockQuantity, products.category_id AS product_category_id,
-			   products.created_at AS created_at, products.updated_at AS updated_at,
-			   categories.id AS category_id, categories.name AS category_name,
-			   categories.description AS category_description,
-			   categories.created_at AS category_created_at, categories.updated_at AS category_updated_at
-		FROM products
-		JOIN categories ON products.category_id = categories.id
-		LIMIT ? OFFSET ?
-	`
-
-	rows, err := s.DB.Query(query, limit, offset)
+// run налаштовує залежності, запускає сервер та чекає на сигнал зупинки
+func run() error {
+	// Завантаження конфігурації з файлу, змінних оточення та прапорців
+	cfg, args, err := config.Load(os.Args[1:], os.Getenv)
 	if err != nil {
-		log.Println("Error querying database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	// Створення слайсу для зберігання результатів
-	var productsWithCategories []ProductWithCategory
-
-	// Зчитування результатів запиту
-	for rows.Next() {
-		var productWithCategory ProductWithCategory
-		// Сканування результатів у структуру продукту з категорією
-		if err := rows.Scan(
-			&productWithCategory.ProductID,
-			&productWithCategory.ProductName,
-			&productWithCategory.ProductDescription,
-			&productWithCategory.ProductPrice,
-			&productWithCategory.StockQuantity,
-			&productWithCategory.ProductCategoryID,
-			&productWithCategory.CreatedAt,
-			&productWithCategory.UpdatedAt,
-			&productWithCategory.CategoryID,
-			&productWithCategory.CategoryName,
-			&productWithCategory.CategoryDescription,
-			&productWithCategory.CategoryCreatedAt,
-			&productWithCategory.CategoryUpdatedAt,
-		); err != nil {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		productsWithCategories = append(productsWithCategories, productWithCategory)
+		return err
 	}
+	setupLogging(cfg.Log)
+	money.DefaultCurrency = cfg.Shop.Currency
 
-	// Перевірка наявності помилок під час зчитування
-	if err := rows.Err(); err != nil {
-		log.Println("Error reading rows:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(productsWithCategories); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-}
-*/
-
-func (s *ProductService) GetProducts(w http.ResponseWriter, r *http.Request) {
-	// ... (зберігаємо код пагінації та запиту з бази даних)
-	// Отримання значень параметрів пагінації
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = 1
-	}
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = 10 // За замовчуванням 10 елементів на сторінці
-	}
+	// Ініціалізація роутера
+	r := chi.NewRouter()
 
-	// Розрахунок зсуву (offset) для пагінації
-	offset := (page - 1) * limit
-	// Вибірка продуктів з бази даних з пагінацією
-	query := `
-		SELECT products.id AS product_id, products.name AS product_name, 
-			   products.description AS product_description, products.price AS product_price, 
-			   products.stock_quantity AS product_stockQuantity, products.category_id AS product_category_id,
-			   categories.id AS category_id, categories.name AS category_name,
-			   categories.description AS category_description
-		FROM products
-		LEFT JOIN categories ON products.category_id = categories.id
-		LIMIT ? OFFSET ?
-	`
-
-	rows, err := s.DB.Query(query, limit, offset)
+	// Ініціалізація пулу з'єднань до бази даних обраного діалекту
+	d, err := dialect.ByName(cfg.Database.Driver)
 	if err != nil {
-		log.Println("Error querying database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	// Створення слайсу для зберігання результатів
-	var productsWithCategoriesWithoutDates []ProductWithCategoryWithoutDates
-
-	// Зчитування результатів запиту
-	for rows.Next() {
-		var productWithCategoryWithoutDates ProductWithCategoryWithoutDates
-		// Сканування результатів у структуру продукту з категорією без дат
-		if err := rows.Scan(
-			&productWithCategoryWithoutDates.ProductID,
-			&productWithCategoryWithoutDates.ProductName,
-			&productWithCategoryWithoutDates.ProductDescription,
-			&productWithCategoryWithoutDates.ProductPrice,
-			&productWithCategoryWithoutDates.StockQuantity,
-			&productWithCategoryWithoutDates.ProductCategoryID,
-			&productWithCategoryWithoutDates.CategoryID,
-			&productWithCategoryWithoutDates.CategoryName,
-			&productWithCategoryWithoutDates.CategoryDescription,
-		); err != nil {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		productsWithCategoriesWithoutDates = append(productsWithCategoriesWithoutDates, productWithCategoryWithoutDates)
-	}
-
-	// Перевірка наявності помилок під час зчитування
-	if err := rows.Err(); err != nil {
-		log.Println("Error reading rows:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(productsWithCategoriesWithoutDates); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-}
-
-// GetProduct повертає інформацію про конкретний продукт за ID
-func (s *ProductService) GetProduct(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID продукту з URL-параметра
-	productID := chi.URLParam(r, "id")
-	if productID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return err
 	}
-
-	// Вибірка конкретного продукту з бази даних за ID
-	row := s.DB.QueryRow("SELECT * FROM products WHERE id=?", productID)
-
-	// Створення змінної для зберігання результатів
-	var product Product
-	//fmt.Println(row)
-	// Зчитування результатів запиту
-	err := row.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.StockQuantity, &product.CategoryID, &product.Created_at, &product.Updated_at)
+	db, err := sql.Open(d.Driver(), cfg.Database.DSN)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusNotFound)
-		} else {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		return
+		return err
 	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
-	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(product); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	// Підкоманда migrate керує схемою бази даних замість запуску сервера
+	if len(args) > 0 && args[0] == "migrate" {
+		defer db.Close()
+		return runMigrate(db, d, args[1:])
 	}
-}
 
-// CreateProduct додає новий продукт
-func (s *ProductService) CreateProduct(w http.ResponseWriter, r *http.Request) {
-	// Отримання даних про новий продукт з тіла запиту (JSON)
-	var newProduct Product
-	if err := json.NewDecoder(r.Body).Decode(&newProduct); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
-		//fmt.Println(&newProduct)
-		return
-	}
-	fmt.Println(newProduct)
-
-	// Логіка додавання нового продукту до бази даних
-	// result, err := s.DB.Exec("INSERT INTO products (name, description, price, stock_quantity, category_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-	// 	newProduct.Name, newProduct.Description, newProduct.Price, newProduct.StockQuantity, newProduct.CategoryID, time.Now(), time.Now())
-	// if err != nil {
-	// 	log.Println("Error inserting product into database:", err)
-	// 	w.WriteHeader(http.StatusInternalServerError)
-	// 	return
-	// }
-	query := `
-    INSERT INTO products (name, description, price, stock_quantity, category_id, created_at, updated_at)
-    VALUES (?, ?, ?, ?, ?, ?, ?)
-`
-	result, err := s.DB.Exec(query, newProduct.Name, newProduct.Description, newProduct.Price, newProduct.StockQuantity, newProduct.CategoryID, time.Now(), time.Now())
-	if err != nil {
-		log.Println("Error inserting into database:", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	userRepo := user.NewSQLRepository(db, d)
+	hasher := user.Hasher{Cost: cfg.Auth.BcryptCost}
 
-	// Отримання ID новоствореного продукту
-	newProductID, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Error getting last insert ID:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	newProduct.ID = int(newProductID)
-
-	// Відправлення відповіді у форматі JSON з новоствореним продуктом та статусом 201 (Created)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(newProduct); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	// Підкоманда hash-passwords хешує паролі, що зберігаються відкритим текстом
+	if len(args) > 0 && args[0] == "hash-passwords" {
+		defer db.Close()
+		n, err := user.HashPlaintextPasswords(context.Background(), userRepo, hasher)
+		if err != nil {
+			return err
+		}
+		log.Printf("Hashed %d plaintext passwords\n", n)
+		return nil
 	}
-}
 
-// UpdateProduct оновлює інформацію про продукт за ID
-func (s *ProductService) UpdateProduct(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID продукту з URL-параметра
-	productID := chi.URLParam(r, "id")
-	if productID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
+	rbacStore := rbac.NewSQLStore(db, d)
 
-	// Отримання нових даних про продукт з тіла запиту (JSON)
-	var updatedProduct Product
-	if err := json.NewDecoder(r.Body).Decode(&updatedProduct); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	// Підкоманда roles керує ролями користувачів
+	if len(args) > 0 && args[0] == "roles" {
+		defer db.Close()
+		return runRoles(userRepo, rbacStore, args[1:])
 	}
 
-	// Логіка оновлення інформації про продукт в базі даних за ID
-	// result, err := s.DB.Exec("UPDATE products SET name=?, description=?, price=?, stock_quantity=?, category_id=?, updated_at=?   WHERE id=?",
-	// 	updatedProduct.Name, updatedProduct.Description, updatedProduct.Price, updatedProduct.StockQuantity, updatedProduct.CategoryID, productID, time.Now())
-	query := `
-		UPDATE products
-		SET
-			name = ?,
-			description = ?,
-			price = ?,
-			stock_quantity = ?,
-			category_id = ?,
-			updated_at = ?
-		WHERE id = ?
-	`
-	result, err := s.DB.Exec(query,
-		updatedProduct.Name,
-		updatedProduct.Description,
-		updatedProduct.Price,
-		updatedProduct.StockQuantity,
-		updatedProduct.CategoryID,
-		time.Now(),
-		productID,
-	)
-
-	if err != nil {
-		log.Println("Error updating product in database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	// Пул з'єднань реєструється першим, тож закривається останнім —
+	// після того, як HTTP-сервер і фонові обробники завершили роботу
+	lc := &lifecycle.Lifecycle{}
+	lc.Append(lifecycle.Hook{
+		Name: "database",
+		Stop: func(context.Context) error { return db.Close() },
+	})
 
-	// Перевірка, чи існує продукт за вказаним ID
-	rowsAffected, err := result.RowsAffected()
+	// Перевірки залежностей для /readyz
+	migrator, err := migrations.NewMigrator(db, d)
 	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if rowsAffected == 0 {
-		// Якщо немає відповідного продукту, відправити HTTP статус 404 (Not Found)
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-
-	// Відправлення відповіді у форматі JSON з оновленим продуктом
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(updatedProduct); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
+	}
+	checks := health.NewRegistry(cfg.Health.CheckTimeout)
+	checks.Register("database", health.DBPing(db))
+	checks.Register("migrations", health.MigrationVersion(migrator))
+	if cfg.Health.DiskPath != "" {
+		checks.Register("disk", health.DiskSpace(cfg.Health.DiskPath, cfg.Health.MinFreeBytes))
+	}
+
+	catRepo := categories.NewSQLRepository(db, d)
+	imageStorage := media.NewLocalStorage(cfg.Images.Dir, cfg.Images.BaseURL)
+	productService := &products.ProductService{
+		Repo:           products.NewSQLRepository(db, d),
+		Categories:     catRepo,
+		Storage:        imageStorage,
+		MaxImageBytes:  cfg.Images.MaxUploadBytes,
+		MaxImagePixels: cfg.Images.MaxPixels,
+	}
+	for _, t := range cfg.Images.Thumbnails {
+		productService.Thumbnails = append(productService.Thumbnails, media.Size{Name: t.Name, Width: t.Width, Height: t.Height})
+	}
+	userSvc := &user.UserService{Repo: userRepo, Hasher: hasher}
+	catSvc := &categories.CatSetvices{Repo: catRepo}
+	authSvc := &auth.Service{
+		Users:      userSvc,
+		Tokens:     auth.NewSQLRepository(db, d),
+		AccessTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTTL: cfg.Auth.RefreshTokenTTL,
+	}
+	policy := &rbac.Policy{Store: rbacStore}
+	cartSvc := &cart.CartService{Repo: cart.NewSQLRepository(db, d), Products: productService.Repo}
+	discountSvc := &discounts.DiscountService{
+		Repo:       discounts.NewSQLRepository(db, d),
+		Products:   productService.Repo,
+		Categories: catRepo,
+	}
+	taxSvc := &tax.TaxService{
+		Repo:             tax.NewSQLRepository(db, d),
+		Products:         productService.Repo,
+		Categories:       catRepo,
+		PricesIncludeTax: cfg.Tax.PricesIncludeTax,
+	}
+	shippingSvc := &shipping.ShippingService{Repo: shipping.NewSQLRepository(db, d), Products: productService.Repo}
+
+	orderSvc := &orders.OrderService{Repo: orders.NewSQLRepository(db, d), Discounts: discountSvc}
+	paymentSvc := &payments.PaymentService{
+		Repo:          payments.NewSQLRepository(db, d),
+		Provider:      payments.NewFakeProvider(),
+		Orders:        orderSvc,
+		WebhookSecret: cfg.Payments.WebhookSecret,
+	}
+	if cfg.Payments.WebhookSecret == "" {
+		slog.Warn("payments.webhook_secret is not set; payment webhooks will be rejected")
+	}
+
+	// Анонімний кошик, переданий під час входу, переноситься користувачу
+	authSvc.OnLogin = func(ctx context.Context, u user.User, input auth.LoginInput) error {
+		if input.CartID == "" {
+			return nil
+		}
+		_, err := cartSvc.Merge(ctx, input.CartID, u.ID)
+		return err
 	}
-}
 
-// DeleteProduct видаляє продукт за ID
-func (s *ProductService) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID продукту з URL-параметра
-	productID := chi.URLParam(r, "id")
-	if productID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
+	// Кожен запит отримує ID, який потрапляє у журнал та у відповіді з помилками
+	r.Use(middleware.RequestID)
 
-	// Логіка видалення продукту з бази даних за ID
-	result, err := s.DB.Exec("DELETE FROM products WHERE id=?", productID)
-	if err != nil {
-		log.Println("Error deleting product from database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	// Додавання middleware для логування запитів
+	if cfg.Features.RequestLogging {
+		r.Use(middleware.Logger)
 	}
 
-	// Перевірка, чи існує продукт за вказаним ID
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if rowsAffected == 0 {
-		// Якщо немає відповідного продукту, відправити HTTP статус 404 (Not Found)
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
+	// Обмеження розміру тіла запиту; DecodeJSON відповідає 413 при перевищенні
+	r.Use(validate.LimitBody(cfg.Server.MaxBodyBytes))
 
-	// Відправлення відповіді з підтвердженням видалення та статусом 204 (No Content)
-	w.WriteHeader(http.StatusNoContent)
-}
+	// Користувач з токена Authorization: Bearer та його ролі додаються до контексту запиту
+	r.Use(authSvc.Middleware)
+	r.Use(policy.Middleware)
 
-func main() {
-	// Ініціалізація роутера
-	r := chi.NewRouter()
+	// Невідомі маршрути та методи також відповідають у форматі problem+json
+	r.NotFound(problem.NotFound)
+	r.MethodNotAllowed(problem.MethodNotAllowed)
 
-	// Ініціалізація сервісу продуктів з підключенням до бази даних
-	db, err := sql.Open("mysql", "root:usbw@tcp(localhost:3306)/dbshopgo?parseTime=true")
-	if err != nil {
-		log.Fatal(err)
+	// Граничний час запитів до бази даних для групи маршрутів
+	queryDeadline := func(route string) func(http.Handler) http.Handler {
+		return deadline.Middleware(cfg.Database.QueryTimeoutFor(route))
 	}
-	defer db.Close()
-
-	productService := &ProductService{DB: db}
-	userSvc := &user.UserService{DB: db}
-	catSvc := &categories.CatSetvices{DB: db}
-
-	// Додавання middleware для логування запитів
-	r.Use(middleware.Logger)
 
 	// Додавання роутів
-	r.Get("/products", productService.GetProducts)
-	r.Get("/products/{id}", productService.GetProduct)
-	r.Post("/products", productService.CreateProduct)
-	r.Put("/products/{id}", productService.UpdateProduct)
-	r.Delete("/products/{id}", productService.DeleteProduct)
+	r.Get("/healthz", health.Liveness)
+	// Локальне сховище віддає зображення саме, якщо їхні URL не ведуть на окремий хост
+	if strings.HasPrefix(cfg.Images.BaseURL, "/") {
+		r.Handle(cfg.Images.BaseURL+"*", http.StripPrefix(cfg.Images.BaseURL, imageStorage))
+	}
+	r.Get("/readyz", checks.Readiness)
+	r.Route("/auth", func(r chi.Router) {
+		r.Use(queryDeadline("/auth"))
+		r.Post("/login", authSvc.Login)
+		r.Post("/refresh", authSvc.Refresh)
+		r.Post("/logout", authSvc.Logout)
+	})
+	r.Route("/products", func(r chi.Router) {
+		r.Use(queryDeadline("/products"))
+		r.Get("/", productService.GetProducts)
+		r.Get("/{id}", productService.GetProduct)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Post("/", productService.CreateProduct)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Put("/{id}", productService.UpdateProduct)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Patch("/{id}", productService.PatchProduct)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Delete("/{id}", productService.DeleteProduct)
+		r.Get("/{id}/options", productService.GetOptions)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Put("/{id}/options", productService.SetOptions)
+		r.Get("/{id}/variants", productService.GetVariants)
+		r.Get("/{id}/variants/{variantID}", productService.GetVariant)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Post("/{id}/variants", productService.CreateVariant)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Put("/{id}/variants/{variantID}", productService.UpdateVariant)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Delete("/{id}/variants/{variantID}", productService.DeleteVariant)
+		r.Get("/{id}/images", productService.GetImages)
+		// Файл зображення більший за загальне обмеження тіла; запас покриває заголовки multipart
+		r.With(rbac.Require(rbac.PermProductsWrite), validate.OverrideBodyLimit(cfg.Images.MaxUploadBytes+64<<10)).Post("/{id}/images", productService.UploadImage)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Put("/{id}/images/order", productService.ReorderImages)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Put("/{id}/images/{imageID}/primary", productService.SetPrimaryImage)
+		r.With(rbac.Require(rbac.PermProductsWrite)).Delete("/{id}/images/{imageID}", productService.DeleteImage)
+	})
 	r.Route("/users", func(r chi.Router) {
-		r.Get("/", userSvc.GetUsers)
-		r.Get("/{id}", userSvc.GetUser)
+		r.Use(queryDeadline("/users"))
+		// Реєстрація відкрита; покупці бачать і змінюють лише власний профіль
 		r.Post("/", userSvc.CreateUser)
-		r.Put("/{id}", userSvc.UpdateUser)
-		r.Delete("/{id}", userSvc.DeleteUser)
+		r.With(rbac.Require(rbac.PermUsersList)).Get("/", userSvc.GetUsers)
+		r.With(rbac.RequireSelfOr("id", rbac.PermUsersRead)).Get("/{id}", userSvc.GetUser)
+		r.With(rbac.RequireSelfOr("id", rbac.PermUsersUpdate)).Put("/{id}", userSvc.UpdateUser)
+		r.With(rbac.RequireSelfOr("id", rbac.PermUsersUpdate)).Patch("/{id}", userSvc.PatchUser)
+		r.With(rbac.Require(rbac.PermUsersDelete)).Delete("/{id}", userSvc.DeleteUser)
 	})
 	r.Route("/cat", func(r chi.Router) {
+		r.Use(queryDeadline("/cat"))
 		r.Get("/", catSvc.GetCats)
 		r.Get("/{id}", catSvc.GetCat)
-		r.Post("/", catSvc.CreateCat)
-		r.Put("/{id}", catSvc.UpdateCat)
-		r.Delete("/{id}", catSvc.DeleteCat)
+		r.With(rbac.Require(rbac.PermCategoriesWrite)).Post("/", catSvc.CreateCat)
+		r.With(rbac.Require(rbac.PermCategoriesWrite)).Put("/{id}", catSvc.UpdateCat)
+		r.With(rbac.Require(rbac.PermCategoriesWrite)).Patch("/{id}", catSvc.PatchCat)
+		r.With(rbac.Require(rbac.PermCategoriesWrite)).Delete("/{id}", catSvc.DeleteCat)
+	})
+
+	r.Route("/carts", func(r chi.Router) {
+		r.Use(queryDeadline("/carts"))
+		r.Post("/", cartSvc.CreateCart)
+		r.Get("/{id}", cartSvc.GetCart)
+		r.Post("/{id}/items", cartSvc.AddItem)
+		r.Put("/{id}/items/{productID}", cartSvc.UpdateItem)
+		r.Delete("/{id}/items/{productID}", cartSvc.RemoveItem)
+		r.Post("/{id}/merge", cartSvc.MergeCart)
+	})
+
+	r.Route("/orders", func(r chi.Router) {
+		r.Use(queryDeadline("/orders"))
+		r.Use(auth.RequireUser)
+		r.Get("/", orderSvc.GetOrders)
+		r.Get("/{id}", orderSvc.GetOrder)
+		r.Post("/", orderSvc.CreateOrder)
+		r.Post("/{id}/status", orderSvc.ChangeStatus)
+		r.Get("/{id}/history", orderSvc.GetOrderHistory)
+		r.Post("/{id}/payments", paymentSvc.Pay)
+		r.Get("/{id}/payments", paymentSvc.GetPayments)
+	})
+
+	r.Route("/discounts", func(r chi.Router) {
+		r.Use(queryDeadline("/discounts"))
+		r.Post("/evaluate", discountSvc.Evaluate)
+		r.Route("/coupons", func(r chi.Router) {
+			r.Use(rbac.Require(rbac.PermDiscountsManage))
+			r.Get("/", discountSvc.GetCoupons)
+			r.Get("/{id}", discountSvc.GetCoupon)
+			r.Post("/", discountSvc.CreateCoupon)
+			r.Put("/{id}", discountSvc.UpdateCoupon)
+			r.Delete("/{id}", discountSvc.DeleteCoupon)
+		})
+	})
+
+	r.Route("/tax", func(r chi.Router) {
+		r.Use(queryDeadline("/tax"))
+		r.Post("/calculate", taxSvc.Calculate)
+		r.Route("/rules", func(r chi.Router) {
+			r.Use(rbac.Require(rbac.PermTaxManage))
+			r.Get("/", taxSvc.GetRules)
+			r.Get("/{id}", taxSvc.GetRule)
+			r.Post("/", taxSvc.CreateRule)
+			r.Put("/{id}", taxSvc.UpdateRule)
+			r.Delete("/{id}", taxSvc.DeleteRule)
+		})
+	})
+
+	r.Route("/shipping", func(r chi.Router) {
+		r.Use(queryDeadline("/shipping"))
+		r.Post("/rates", shippingSvc.Rates)
+		r.Group(func(r chi.Router) {
+			r.Use(rbac.Require(rbac.PermShippingManage))
+			r.Get("/zones", shippingSvc.GetZones)
+			r.Get("/zones/{id}", shippingSvc.GetZone)
+			r.Post("/zones", shippingSvc.CreateZone)
+			r.Put("/zones/{id}", shippingSvc.UpdateZone)
+			r.Delete("/zones/{id}", shippingSvc.DeleteZone)
+			r.Get("/methods", shippingSvc.GetMethods)
+			r.Get("/methods/{id}", shippingSvc.GetMethod)
+			r.Post("/methods", shippingSvc.CreateMethod)
+			r.Put("/methods/{id}", shippingSvc.UpdateMethod)
+			r.Delete("/methods/{id}", shippingSvc.DeleteMethod)
+		})
+	})
+
+	// Вебхук провайдера автентифікується підписом, а не токеном користувача
+	r.Route("/payments", func(r chi.Router) {
+		r.Use(queryDeadline("/payments"))
+		r.Post("/webhook", paymentSvc.Webhook)
 	})
 
-	// Запуск сервера на порту 8080
-	port := getPort()
-	fmt.Printf("Server is running on :%s...\n", port)
-	http.ListenAndServe(":"+port, r)
+	// Запуск сервера на адресі з конфігурації (за замовчуванням :7000)
+	// до отримання SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serve(ctx, cfg.Server, r, lc)
 }
 
-// getPort повертає номер порту для веб-сервера
-func getPort() string {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "7000" // За замовчуванням використовуємо 8080
+// setupLogging налаштовує рівень журналювання; повідомлення пакету log
+// виводяться з рівнем info
+func setupLogging(cfg config.LogConfig) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
 	}
-	return port
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 }
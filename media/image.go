@@ -0,0 +1,99 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// DefaultMaxPixels — найбільша кількість пікселів зображення за замовчуванням;
+// захищає від зображень, що після розпакування займають гігабайти пам'яті
+const DefaultMaxPixels = 50_000_000
+
+// JPEGQuality — якість JPEG-мініатюр
+const JPEGQuality = 85
+
+// Extensions — підтримувані типи зображень та розширення їхніх файлів
+var Extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var (
+	// ErrUnsupportedType повертається для вмісту, що не є JPEG, PNG, GIF чи WebP
+	ErrUnsupportedType = errors.New("media: unsupported image type")
+	// ErrTooManyPixels повертається, якщо зображення більше за дозволену кількість пікселів
+	ErrTooManyPixels = errors.New("media: image has too many pixels")
+)
+
+// Size — рамка, в яку вписується мініатюра зі збереженням пропорцій
+type Size struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Sniff визначає тип зображення за вмістом, а не за назвою файлу чи заголовком
+// Content-Type клієнта
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := Extensions[contentType]; !ok {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Decode розкодовує зображення, попередньо перевіривши його розміри за
+// заголовком; maxPixels <= 0 — DefaultMaxPixels
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxPixels/cfg.Height {
+		return nil, ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Fit вписує зображення в рамку width × height зі збереженням пропорцій;
+// менші зображення не збільшуються
+func Fit(img image.Image, width, height int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= width && h <= height {
+		return img
+	}
+	// Масштаб визначає сторона, що сильніше виходить за рамку
+	if w*height > h*width {
+		w, h = width, max(1, h*width/w)
+	} else {
+		w, h = max(1, w*height/h), height
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// Encode кодує мініатюру: JPEG-джерела — у JPEG, решту — у PNG, щоб зберегти
+// прозорість. Повертає тип вмісту результату.
+func Encode(w io.Writer, img image.Image, sourceType string) (string, error) {
+	if sourceType == "image/jpeg" {
+		return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	}
+	return "image/png", png.Encode(w, img)
+}
@@ -0,0 +1,88 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/media"
+)
+
+// encodePNG повертає PNG-зображення заданого розміру
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestImage(t *testing.T) {
+	data := encodePNG(t, 400, 100)
+
+	contentType, err := media.Sniff(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	_, err = media.Sniff([]byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"))
+	assert.Equal(t, media.ErrUnsupportedType, err)
+
+	_, err = media.Decode(data, 100*100)
+	assert.Equal(t, media.ErrTooManyPixels, err)
+	img, err := media.Decode(data, 0)
+	assert.NoError(t, err)
+
+	// Пропорції зберігаються, а менші зображення не збільшуються
+	assert.Equal(t, image.Rect(0, 0, 150, 37), media.Fit(img, 150, 150).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 200, 50), media.Fit(img, 300, 50).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 400, 100), media.Fit(img, 600, 600).Bounds())
+
+	var buf bytes.Buffer
+	contentType, err = media.Encode(&buf, img, "image/jpeg")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Equal(t, "image/jpeg", http.DetectContentType(buf.Bytes()))
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := media.NewLocalStorage(dir, "/media/")
+	ctx := context.Background()
+
+	assert.NoError(t, s.Put(ctx, "products/1/a b.png", strings.NewReader("png"), "image/png"))
+	assert.Equal(t, "/media/products/1/a%20b.png", s.URL("products/1/a b.png"))
+	data, err := os.ReadFile(filepath.Join(dir, "products", "1", "a b.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	// Ключі не можуть вийти за межі каталогу сховища
+	for _, key := range []string{"../escape.png", "/abs.png", "products/../../x", ""} {
+		assert.Equal(t, media.ErrInvalidKey, s.Put(ctx, key, strings.NewReader("x"), "image/png"), key)
+	}
+
+	handler := http.StripPrefix("/media/", s)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/media/products/1/a%20b.png", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "png", rr.Body.String())
+	assert.Contains(t, rr.Header().Get("Cache-Control"), "immutable")
+	for _, path := range []string{"/media/products/1/", "/media/products/1/missing.png", "/media/../go.mod"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, path)
+	}
+
+	assert.NoError(t, s.Delete(ctx, "products/1/a b.png"))
+	assert.NoError(t, s.Delete(ctx, "products/1/a b.png"))
+	_, err = os.Stat(filepath.Join(dir, "products", "1", "a b.png"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,127 @@
+// Package media зберігає завантажені файли та готує з них зображення:
+// розпізнає формат за вмістом, обмежує кількість пікселів і створює мініатюри.
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+// ErrInvalidKey повертається сховищем для ключа, що виходить за його межі
+var ErrInvalidKey = errors.New("media: invalid key")
+
+// Storage — сховище файлів за ключами на кшталт products/1/3f9a/original.jpg.
+// Реалізація з локальною файловою системою — LocalStorage; S3-сумісне
+// сховище має лише реалізувати ці три методи.
+type Storage interface {
+	// Put зберігає вміст під ключем, замінюючи наявний файл
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Delete видаляє файл; відсутній файл не є помилкою
+	Delete(ctx context.Context, key string) error
+	// URL повертає публічну адресу файлу
+	URL(key string) string
+}
+
+// LocalStorage зберігає файли в каталозі Dir і віддає їх за адресами BaseURL + ключ
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+// NewLocalStorage створює сховище в каталозі dir; baseURL має закінчуватися на /
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, BaseURL: baseURL}
+}
+
+// path повертає шлях файлу для ключа, не допускаючи виходу за межі Dir
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" || clean != key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// Запис у тимчасовий файл і перейменування не залишають частково записаних файлів
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.BaseURL + (&url.URL{Path: key}).EscapedPath()
+}
+
+// ServeHTTP віддає файли сховища; шлях запиту — ключ файлу, тож обробник
+// монтується з http.StripPrefix. Ключі містять випадкову частину й ніколи
+// не перезаписуються іншим вмістом, тому відповіді кешуються назавжди.
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, err := s.path(strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil {
+		problem.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		problem.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		problem.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+)
+
+const migrateUsage = "usage: shop_go migrate up|down|status|to N"
+
+// runMigrate виконує підкоманду migrate з аргументами командного рядка
+func runMigrate(db *sql.DB, d dialect.Dialect, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := migrations.NewMigrator(db, d)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		if err := migrator.Down(); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		if err := migrator.To(version); err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-30s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	current, err := migrator.Current()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema is at version %d\n", current)
+	return nil
+}
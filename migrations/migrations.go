@@ -0,0 +1,334 @@
+// Package migrations містить версіоновані SQL-міграції схеми бази даних,
+// вбудовані у бінарний файл, та Migrator для їх застосування.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+//go:embed sql
+var files embed.FS
+
+// Migration описує одну версію схеми з SQL для застосування та відкату
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status описує стан однієї міграції у базі даних
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Load читає вбудовані міграції для діалекту та повертає їх, впорядкованими за версією.
+// Файли лежать у sql/<діалект>/ та мають імена виду 0001_name.up.sql і 0001_name.down.sql.
+func Load(d dialect.Dialect) ([]Migration, error) {
+	return load(files, path.Join("sql", d.Name()))
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base := strings.TrimSuffix(fileName, ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return nil, fmt.Errorf("migrations: %s: expected .up.sql or .down.sql suffix", fileName)
+		}
+		base = strings.TrimSuffix(base, direction)
+
+		versionPart, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrations: %s: expected <version>_<name>", fileName)
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations: %s: invalid version %q", fileName, versionPart)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migrations: version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == ".up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrations: version %d (%s) must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator застосовує та відкочує міграції, записуючи версії у таблицю schema_migrations
+type Migrator struct {
+	DB         *sql.DB
+	Dialect    dialect.Dialect
+	Migrations []Migration
+}
+
+// NewMigrator створює Migrator з вбудованими міграціями для діалекту
+func NewMigrator(db *sql.DB, d dialect.Dialect) (*Migrator, error) {
+	migrations, err := Load(d)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Dialect: d, Migrations: migrations}, nil
+}
+
+// ensureTable створює таблицю schema_migrations, якщо її ще немає
+func (m *Migrator) ensureTable() error {
+	_, err := m.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	return err
+}
+
+// applied повертає застосовані версії з часом їх застосування
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+// Current повертає найбільшу застосовану версію або 0 для порожньої бази
+func (m *Migrator) Current() (int, error) {
+	versions, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	current := 0
+	for version := range versions {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Latest повертає версію останньої вбудованої міграції
+func (m *Migrator) Latest() int {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Status повертає стан кожної відомої міграції
+func (m *Migrator) Status() ([]Status, error) {
+	versions, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		appliedAt, ok := versions[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// Up застосовує всі ще не застосовані міграції
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down відкочує останню застосовану міграцію
+func (m *Migrator) Down() error {
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+
+	target := 0
+	for _, migration := range m.Migrations {
+		if migration.Version < current {
+			target = migration.Version
+		}
+	}
+	return m.To(target)
+}
+
+// To переводить схему до вказаної версії, застосовуючи або відкочуючи міграції.
+// Версія 0 означає відкат усіх міграцій.
+func (m *Migrator) To(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("migrations: unknown version %d", version)
+	}
+
+	versions, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	// Застосування відсутніх міграцій до цільової версії включно
+	for _, migration := range m.Migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := versions[migration.Version]; ok {
+			continue
+		}
+		err := m.step(migration.Up, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			migration.Version, migration.Name, time.Now())
+		if err != nil {
+			return fmt.Errorf("migrations: applying %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	// Відкат міграцій, новіших за цільову версію, у зворотному порядку
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		migration := m.Migrations[i]
+		if migration.Version <= version {
+			break
+		}
+		if _, ok := versions[migration.Version]; !ok {
+			continue
+		}
+		if err := m.step(migration.Down, "DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+			return fmt.Errorf("migrations: reverting %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+	return nil
+}
+
+// execer — спільна частина *sql.DB та *sql.Tx, потрібна для виконання міграцій
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// step виконує скрипт міграції та оновлює schema_migrations запитом record.
+// Якщо діалект підтримує транзакційний DDL, обидва кроки виконуються в одній
+// транзакції, тож невдала міграція не лишає схему застосованою наполовину.
+func (m *Migrator) step(script, record string, args ...interface{}) error {
+	if !m.Dialect.TransactionalDDL() {
+		if err := exec(m.DB, script); err != nil {
+			return err
+		}
+		_, err := m.DB.Exec(m.Dialect.Rebind(record), args...)
+		return err
+	}
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := exec(tx, script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(m.Dialect.Rebind(record), args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// exec виконує SQL-скрипт міграції по одному оператору,
+// оскільки не всі драйвери приймають кілька операторів разом
+func exec(db execer, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements розбиває скрипт на оператори за крапкою з комою в кінці рядка
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
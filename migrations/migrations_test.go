@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	var latest []int
+	for _, d := range []dialect.Dialect{dialect.MySQL(), dialect.Postgres(), dialect.SQLite()} {
+		migrations, err := Load(d)
+		assert.NoError(t, err, d.Name())
+		assert.NotEmpty(t, migrations, d.Name())
+
+		// Версії мають бути впорядковані та унікальні
+		for i := 1; i < len(migrations); i++ {
+			assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+		}
+		latest = append(latest, migrations[len(migrations)-1].Version)
+	}
+
+	// Усі діалекти мають однаковий набір версій
+	assert.Equal(t, latest[0], latest[1])
+	assert.Equal(t, latest[0], latest[2])
+}
+
+func TestMigratorSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	migrator, err := NewMigrator(db, dialect.SQLite())
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrator.Up())
+	current, err := migrator.Current()
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), current)
+
+	assert.NoError(t, migrator.Down())
+	current, err = migrator.Current()
+	assert.NoError(t, err)
+	assert.Less(t, current, migrator.Latest())
+
+	assert.NoError(t, migrator.To(0))
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	migrator := &Migrator{DB: db, Dialect: dialect.SQLite(), Migrations: []Migration{
+		{Version: 1, Name: "a", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "b", Up: "CREATE TABLE b (id INT);\nINSERT INTO missing VALUES (1);", Down: "DROP TABLE b;"},
+	}}
+	assert.Error(t, migrator.Up())
+
+	// Перша міграція лишається застосованою, а від другої не лишається нічого
+	current, err := migrator.Current()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, current)
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'b'").Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestLoadRejectsMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_init.up.sql": {Data: []byte("CREATE TABLE t (id INT);")},
+	}
+	_, err := load(fsys, "sql")
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- comment\nCREATE TABLE a (\n  id INT\n);\n\nCREATE TABLE b (id INT);\n"
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id INT\n);", "CREATE TABLE b (id INT);"}, splitStatements(script))
+}
@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN currency,
+    MODIFY price DECIMAL(12, 2) NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE auth_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    family_id CHAR(32) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY auth_tokens_token_hash (token_hash),
+    KEY auth_tokens_family_id (family_id),
+    KEY auth_tokens_user_id (user_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE carts (
+    id CHAR(32) PRIMARY KEY,
+    user_id INT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY carts_user_id (user_id)
+);
+
+CREATE TABLE cart_items (
+    cart_id CHAR(32) NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    added_at DATETIME NOT NULL,
+    PRIMARY KEY (cart_id, product_id)
+);
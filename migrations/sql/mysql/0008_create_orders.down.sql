@@ -0,0 +1,7 @@
+DELETE FROM role_permissions WHERE permission_id = 7;
+
+DELETE FROM permissions WHERE id = 7;
+
+DROP TABLE order_items;
+
+DROP TABLE orders;
@@ -0,0 +1,27 @@
+CREATE TABLE orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    total DECIMAL(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    KEY orders_user_id (user_id, created_at),
+    KEY orders_status (status, created_at)
+);
+
+CREATE TABLE order_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    unit_price DECIMAL(19, 4) NOT NULL,
+    quantity INT NOT NULL,
+    line_total DECIMAL(19, 4) NOT NULL,
+    KEY order_items_order_id (order_id)
+);
+
+-- Права цієї та пізніших міграцій разом з 0006 мають збігатися з rbac.DefaultRoles
+INSERT INTO permissions (id, name) VALUES (7, 'orders:read');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 7);
@@ -0,0 +1,17 @@
+CREATE TABLE order_status_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    from_status VARCHAR(32) NULL,
+    to_status VARCHAR(32) NOT NULL,
+    actor_id INT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    KEY order_status_history_order_id (order_id)
+);
+
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, created_at)
+SELECT id, NULL, status, user_id, created_at FROM orders;
+
+INSERT INTO permissions (id, name) VALUES (8, 'orders:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 8);
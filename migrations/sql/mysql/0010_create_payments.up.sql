@@ -0,0 +1,24 @@
+CREATE TABLE payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY payments_provider_reference (provider, reference),
+    KEY payments_order_id (order_id)
+);
+
+-- Унікальний event_id робить обробку повторно доставлених вебхуків ідемпотентною
+CREATE TABLE payment_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    provider VARCHAR(32) NOT NULL,
+    event_id VARCHAR(128) NOT NULL,
+    payment_id INT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY payment_events_provider_event_id (provider, event_id)
+);
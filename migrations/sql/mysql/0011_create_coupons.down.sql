@@ -0,0 +1,13 @@
+DELETE FROM user_roles WHERE role_id = 4;
+
+DELETE FROM role_permissions WHERE permission_id = 9 OR role_id = 4;
+
+DELETE FROM permissions WHERE id = 9;
+
+DELETE FROM roles WHERE id = 4;
+
+ALTER TABLE orders DROP COLUMN discount;
+
+DROP TABLE coupon_redemptions;
+
+DROP TABLE coupons;
@@ -0,0 +1,40 @@
+CREATE TABLE coupons (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    kind VARCHAR(32) NOT NULL,
+    percent DECIMAL(7, 4) NOT NULL DEFAULT 0,
+    amount DECIMAL(19, 4) NULL,
+    min_total DECIMAL(19, 4) NULL,
+    currency CHAR(3) NULL,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    category_id INT NULL,
+    max_uses INT NOT NULL DEFAULT 0,
+    max_uses_per_user INT NOT NULL DEFAULT 0,
+    uses INT NOT NULL DEFAULT 0,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY coupons_code (code)
+);
+
+CREATE TABLE coupon_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    coupon_id INT NOT NULL,
+    user_id INT NOT NULL,
+    order_id INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    KEY coupon_redemptions_coupon_user (coupon_id, user_id)
+);
+
+-- Знижка купонів, уже віднята від total
+ALTER TABLE orders ADD COLUMN discount DECIMAL(19, 4) NOT NULL DEFAULT 0;
+
+INSERT INTO roles (id, name) VALUES (4, 'marketing');
+
+INSERT INTO permissions (id, name) VALUES (9, 'discounts:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 9), (4, 9);
@@ -0,0 +1,16 @@
+-- category_id = 0 означає правило для всіх категорій
+CREATE TABLE tax_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    country CHAR(2) NOT NULL,
+    region VARCHAR(64) NOT NULL DEFAULT '',
+    category_id INT NOT NULL DEFAULT 0,
+    rate DECIMAL(7, 4) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY tax_rules_scope (country, region, category_id)
+);
+
+INSERT INTO permissions (id, name) VALUES (10, 'tax:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 10);
@@ -0,0 +1,17 @@
+DELETE FROM role_permissions WHERE permission_id = 11;
+
+DELETE FROM permissions WHERE id = 11;
+
+DROP TABLE shipping_weight_rates;
+
+DROP TABLE shipping_methods;
+
+DROP TABLE shipping_zone_locations;
+
+DROP TABLE shipping_zones;
+
+ALTER TABLE products
+    DROP COLUMN weight,
+    DROP COLUMN length,
+    DROP COLUMN width,
+    DROP COLUMN height;
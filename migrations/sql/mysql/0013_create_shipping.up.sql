@@ -0,0 +1,47 @@
+ALTER TABLE products
+    ADD COLUMN weight INT NOT NULL DEFAULT 0,
+    ADD COLUMN length INT NOT NULL DEFAULT 0,
+    ADD COLUMN width INT NOT NULL DEFAULT 0,
+    ADD COLUMN height INT NOT NULL DEFAULT 0;
+
+CREATE TABLE shipping_zones (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- Порожній postcode_prefix охоплює всю країну
+CREATE TABLE shipping_zone_locations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    zone_id INT NOT NULL,
+    country CHAR(2) NOT NULL,
+    postcode_prefix VARCHAR(16) NOT NULL DEFAULT '',
+    INDEX shipping_zone_locations_zone_id (zone_id)
+);
+
+CREATE TABLE shipping_methods (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    zone_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    price DECIMAL(19, 4) NOT NULL,
+    threshold DECIMAL(19, 4) NULL,
+    currency CHAR(3) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX shipping_methods_zone_id (zone_id)
+);
+
+CREATE TABLE shipping_weight_rates (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    method_id INT NOT NULL,
+    max_weight INT NOT NULL,
+    price DECIMAL(19, 4) NOT NULL,
+    INDEX shipping_weight_rates_method_id (method_id)
+);
+
+INSERT INTO permissions (id, name) VALUES (11, 'shipping:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 11);
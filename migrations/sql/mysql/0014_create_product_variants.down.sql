@@ -0,0 +1,7 @@
+DROP TABLE product_variant_options;
+
+DROP TABLE product_variants;
+
+DROP TABLE product_option_values;
+
+DROP TABLE product_options;
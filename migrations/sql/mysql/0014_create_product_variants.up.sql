@@ -0,0 +1,38 @@
+CREATE TABLE product_options (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    position INT NOT NULL,
+    INDEX product_options_product_id (product_id)
+);
+
+CREATE TABLE product_option_values (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    option_id INT NOT NULL,
+    value VARCHAR(64) NOT NULL,
+    position INT NOT NULL,
+    INDEX product_option_values_option_id (option_id)
+);
+
+-- price та currency NULL — варіант продається за ціною продукту
+CREATE TABLE product_variants (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    barcode VARCHAR(14) NOT NULL DEFAULT '',
+    price DECIMAL(19, 4) NULL,
+    currency CHAR(3) NULL,
+    stock_quantity INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY product_variants_sku (sku),
+    INDEX product_variants_product_id (product_id)
+);
+
+CREATE TABLE product_variant_options (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    variant_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value VARCHAR(64) NOT NULL,
+    INDEX product_variant_options_variant_id (variant_id)
+);
@@ -0,0 +1,25 @@
+-- storage_key — ключ файлу у сховищі зображень; URL будується з нього під час відповіді
+CREATE TABLE product_images (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    position INT NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    content_type VARCHAR(32) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX product_images_product_id (product_id)
+);
+
+CREATE TABLE product_image_thumbnails (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    image_id INT NOT NULL,
+    name VARCHAR(32) NOT NULL,
+    content_type VARCHAR(32) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    INDEX product_image_thumbnails_image_id (image_id)
+);
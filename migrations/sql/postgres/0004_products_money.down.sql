@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN currency,
+    ALTER COLUMN price TYPE NUMERIC(12, 2);
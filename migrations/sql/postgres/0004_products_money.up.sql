@@ -0,0 +1,3 @@
+ALTER TABLE products
+    ALTER COLUMN price TYPE NUMERIC(19, 4),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'UAH';
@@ -0,0 +1,15 @@
+CREATE TABLE auth_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    family_id CHAR(32) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT auth_tokens_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX auth_tokens_family_id ON auth_tokens (family_id);
+
+CREATE INDEX auth_tokens_user_id ON auth_tokens (user_id);
@@ -0,0 +1,15 @@
+CREATE TABLE carts (
+    id CHAR(32) PRIMARY KEY,
+    user_id INTEGER NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT carts_user_id UNIQUE (user_id)
+);
+
+CREATE TABLE cart_items (
+    cart_id CHAR(32) NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (cart_id, product_id)
+);
@@ -0,0 +1,30 @@
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    total NUMERIC(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX orders_user_id ON orders (user_id, created_at);
+
+CREATE INDEX orders_status ON orders (status, created_at);
+
+CREATE TABLE order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    unit_price NUMERIC(19, 4) NOT NULL,
+    quantity INTEGER NOT NULL,
+    line_total NUMERIC(19, 4) NOT NULL
+);
+
+CREATE INDEX order_items_order_id ON order_items (order_id);
+
+-- Права цієї та пізніших міграцій разом з 0006 мають збігатися з rbac.DefaultRoles
+INSERT INTO permissions (id, name) VALUES (7, 'orders:read');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 7);
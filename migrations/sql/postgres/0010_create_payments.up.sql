@@ -0,0 +1,25 @@
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT payments_provider_reference UNIQUE (provider, reference)
+);
+
+CREATE INDEX payments_order_id ON payments (order_id);
+
+-- Унікальний event_id робить обробку повторно доставлених вебхуків ідемпотентною
+CREATE TABLE payment_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(32) NOT NULL,
+    event_id VARCHAR(128) NOT NULL,
+    payment_id INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT payment_events_provider_event_id UNIQUE (provider, event_id)
+);
@@ -0,0 +1,41 @@
+CREATE TABLE coupons (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    kind VARCHAR(32) NOT NULL,
+    percent NUMERIC(7, 4) NOT NULL DEFAULT 0,
+    amount NUMERIC(19, 4) NULL,
+    min_total NUMERIC(19, 4) NULL,
+    currency CHAR(3) NULL,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    category_id INTEGER NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP NULL,
+    ends_at TIMESTAMP NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT coupons_code UNIQUE (code)
+);
+
+CREATE TABLE coupon_redemptions (
+    id SERIAL PRIMARY KEY,
+    coupon_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX coupon_redemptions_coupon_user ON coupon_redemptions (coupon_id, user_id);
+
+-- Знижка купонів, уже віднята від total
+ALTER TABLE orders ADD COLUMN discount NUMERIC(19, 4) NOT NULL DEFAULT 0;
+
+INSERT INTO roles (id, name) VALUES (4, 'marketing');
+
+INSERT INTO permissions (id, name) VALUES (9, 'discounts:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 9), (4, 9);
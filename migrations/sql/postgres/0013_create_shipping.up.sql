@@ -0,0 +1,50 @@
+ALTER TABLE products
+    ADD COLUMN weight INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN length INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN width INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE shipping_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Порожній postcode_prefix охоплює всю країну
+CREATE TABLE shipping_zone_locations (
+    id SERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL,
+    country CHAR(2) NOT NULL,
+    postcode_prefix VARCHAR(16) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX shipping_zone_locations_zone_id ON shipping_zone_locations (zone_id);
+
+CREATE TABLE shipping_methods (
+    id SERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    price NUMERIC(19, 4) NOT NULL,
+    threshold NUMERIC(19, 4) NULL,
+    currency CHAR(3) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX shipping_methods_zone_id ON shipping_methods (zone_id);
+
+CREATE TABLE shipping_weight_rates (
+    id SERIAL PRIMARY KEY,
+    method_id INTEGER NOT NULL,
+    max_weight INTEGER NOT NULL,
+    price NUMERIC(19, 4) NOT NULL
+);
+
+CREATE INDEX shipping_weight_rates_method_id ON shipping_weight_rates (method_id);
+
+INSERT INTO permissions (id, name) VALUES (11, 'shipping:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 11);
@@ -0,0 +1,42 @@
+CREATE TABLE product_options (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    position INTEGER NOT NULL
+);
+
+CREATE INDEX product_options_product_id ON product_options (product_id);
+
+CREATE TABLE product_option_values (
+    id SERIAL PRIMARY KEY,
+    option_id INTEGER NOT NULL,
+    value VARCHAR(64) NOT NULL,
+    position INTEGER NOT NULL
+);
+
+CREATE INDEX product_option_values_option_id ON product_option_values (option_id);
+
+-- price та currency NULL — варіант продається за ціною продукту
+CREATE TABLE product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    barcode VARCHAR(14) NOT NULL DEFAULT '',
+    price NUMERIC(19, 4) NULL,
+    currency CHAR(3) NULL,
+    stock_quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT product_variants_sku UNIQUE (sku)
+);
+
+CREATE INDEX product_variants_product_id ON product_variants (product_id);
+
+CREATE TABLE product_variant_options (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX product_variant_options_variant_id ON product_variant_options (variant_id);
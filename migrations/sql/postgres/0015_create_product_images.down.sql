@@ -0,0 +1,3 @@
+DROP TABLE product_image_thumbnails;
+
+DROP TABLE product_images;
@@ -0,0 +1,27 @@
+-- storage_key — ключ файлу у сховищі зображень; URL будується з нього під час відповіді
+CREATE TABLE product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    content_type VARCHAR(32) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX product_images_product_id ON product_images (product_id);
+
+CREATE TABLE product_image_thumbnails (
+    id SERIAL PRIMARY KEY,
+    image_id INTEGER NOT NULL,
+    name VARCHAR(32) NOT NULL,
+    content_type VARCHAR(32) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX product_image_thumbnails_image_id ON product_image_thumbnails (image_id);
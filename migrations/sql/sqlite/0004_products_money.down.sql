@@ -0,0 +1,19 @@
+CREATE TABLE products_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    price REAL NOT NULL,
+    stock_quantity INTEGER NOT NULL DEFAULT 0,
+    category_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT INTO products_old (id, name, description, price, stock_quantity, category_id, created_at, updated_at)
+SELECT id, name, description, CAST(price AS REAL), stock_quantity, category_id, created_at, updated_at FROM products;
+
+DROP TABLE products;
+
+ALTER TABLE products_old RENAME TO products;
+
+CREATE INDEX products_category_id ON products (category_id);
@@ -0,0 +1,22 @@
+-- SQLite не змінює тип стовпця, тому таблиця перебудовується.
+-- Ціна зберігається як TEXT, щоб уникнути перетворення на REAL.
+CREATE TABLE products_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    price TEXT NOT NULL,
+    stock_quantity INTEGER NOT NULL DEFAULT 0,
+    category_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    currency TEXT NOT NULL DEFAULT 'UAH'
+);
+
+INSERT INTO products_new (id, name, description, price, stock_quantity, category_id, created_at, updated_at)
+SELECT id, name, description, printf('%.2f', price), stock_quantity, category_id, created_at, updated_at FROM products;
+
+DROP TABLE products;
+
+ALTER TABLE products_new RENAME TO products;
+
+CREATE INDEX products_category_id ON products (category_id);
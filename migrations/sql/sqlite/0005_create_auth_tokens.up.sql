@@ -0,0 +1,14 @@
+CREATE TABLE auth_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    family_id TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX auth_tokens_family_id ON auth_tokens (family_id);
+
+CREATE INDEX auth_tokens_user_id ON auth_tokens (user_id);
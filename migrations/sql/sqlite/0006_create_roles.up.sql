@@ -0,0 +1,36 @@
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE permissions (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL,
+    permission_id INTEGER NOT NULL,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL,
+    role_id INTEGER NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- Набір ролей та прав має збігатися з rbac.DefaultRoles
+INSERT INTO roles (id, name) VALUES (1, 'admin'), (2, 'catalog_manager'), (3, 'customer');
+
+INSERT INTO permissions (id, name) VALUES
+    (1, 'products:write'),
+    (2, 'categories:write'),
+    (3, 'users:list'),
+    (4, 'users:read'),
+    (5, 'users:update'),
+    (6, 'users:delete');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+    (1, 3), (1, 4), (1, 5), (1, 6),
+    (2, 1), (2, 2);
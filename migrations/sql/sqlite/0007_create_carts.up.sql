@@ -0,0 +1,14 @@
+CREATE TABLE carts (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE cart_items (
+    cart_id TEXT NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    added_at DATETIME NOT NULL,
+    PRIMARY KEY (cart_id, product_id)
+);
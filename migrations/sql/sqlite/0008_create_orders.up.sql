@@ -0,0 +1,31 @@
+-- Суми зберігаються як TEXT, щоб уникнути перетворення на REAL
+CREATE TABLE orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    total TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX orders_user_id ON orders (user_id, created_at);
+
+CREATE INDEX orders_status ON orders (status, created_at);
+
+CREATE TABLE order_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    unit_price TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    line_total TEXT NOT NULL
+);
+
+CREATE INDEX order_items_order_id ON order_items (order_id);
+
+-- Права цієї та пізніших міграцій разом з 0006 мають збігатися з rbac.DefaultRoles
+INSERT INTO permissions (id, name) VALUES (7, 'orders:read');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 7);
@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission_id = 8;
+
+DELETE FROM permissions WHERE id = 8;
+
+DROP TABLE order_status_history;
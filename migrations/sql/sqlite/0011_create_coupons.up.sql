@@ -0,0 +1,40 @@
+CREATE TABLE coupons (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    percent TEXT NOT NULL DEFAULT '0',
+    amount TEXT NULL,
+    min_total TEXT NULL,
+    currency TEXT NULL,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    category_id INTEGER NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE coupon_redemptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    coupon_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX coupon_redemptions_coupon_user ON coupon_redemptions (coupon_id, user_id);
+
+-- Знижка купонів, уже віднята від total
+ALTER TABLE orders ADD COLUMN discount TEXT NOT NULL DEFAULT '0';
+
+INSERT INTO roles (id, name) VALUES (4, 'marketing');
+
+INSERT INTO permissions (id, name) VALUES (9, 'discounts:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 9), (4, 9);
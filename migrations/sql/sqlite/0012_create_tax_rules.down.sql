@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission_id = 10;
+
+DELETE FROM permissions WHERE id = 10;
+
+DROP TABLE tax_rules;
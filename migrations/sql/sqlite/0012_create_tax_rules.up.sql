@@ -0,0 +1,16 @@
+-- category_id = 0 означає правило для всіх категорій
+CREATE TABLE tax_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    country TEXT NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    category_id INTEGER NOT NULL DEFAULT 0,
+    rate TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (country, region, category_id)
+);
+
+INSERT INTO permissions (id, name) VALUES (10, 'tax:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 10);
@@ -0,0 +1,52 @@
+ALTER TABLE products ADD COLUMN weight INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD COLUMN length INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD COLUMN width INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE shipping_zones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- Порожній postcode_prefix охоплює всю країну
+CREATE TABLE shipping_zone_locations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    zone_id INTEGER NOT NULL,
+    country TEXT NOT NULL,
+    postcode_prefix TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX shipping_zone_locations_zone_id ON shipping_zone_locations (zone_id);
+
+CREATE TABLE shipping_methods (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    zone_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    price TEXT NOT NULL,
+    threshold TEXT NULL,
+    currency TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX shipping_methods_zone_id ON shipping_methods (zone_id);
+
+CREATE TABLE shipping_weight_rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    method_id INTEGER NOT NULL,
+    max_weight INTEGER NOT NULL,
+    price TEXT NOT NULL
+);
+
+CREATE INDEX shipping_weight_rates_method_id ON shipping_weight_rates (method_id);
+
+INSERT INTO permissions (id, name) VALUES (11, 'shipping:manage');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 11);
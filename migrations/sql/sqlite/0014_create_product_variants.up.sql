@@ -0,0 +1,41 @@
+CREATE TABLE product_options (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL
+);
+
+CREATE INDEX product_options_product_id ON product_options (product_id);
+
+CREATE TABLE product_option_values (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    option_id INTEGER NOT NULL,
+    value TEXT NOT NULL,
+    position INTEGER NOT NULL
+);
+
+CREATE INDEX product_option_values_option_id ON product_option_values (option_id);
+
+-- price та currency NULL — варіант продається за ціною продукту
+CREATE TABLE product_variants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    sku TEXT NOT NULL UNIQUE,
+    barcode TEXT NOT NULL DEFAULT '',
+    price TEXT NULL,
+    currency TEXT NULL,
+    stock_quantity INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX product_variants_product_id ON product_variants (product_id);
+
+CREATE TABLE product_variant_options (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    variant_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL
+);
+
+CREATE INDEX product_variant_options_variant_id ON product_variant_options (variant_id);
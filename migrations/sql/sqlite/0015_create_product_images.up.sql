@@ -0,0 +1,27 @@
+-- storage_key — ключ файлу у сховищі зображень; URL будується з нього під час відповіді
+CREATE TABLE product_images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX product_images_product_id ON product_images (product_id);
+
+CREATE TABLE product_image_thumbnails (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    image_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL
+);
+
+CREATE INDEX product_image_thumbnails_image_id ON product_image_thumbnails (image_id);
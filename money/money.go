@@ -0,0 +1,211 @@
+// Package money описує грошові суми з точною десятковою арифметикою,
+// щоб ціни, знижки та податки не накопичували похибку float64.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency використовується, якщо валюту в запиті не вказано.
+// Встановлюється з конфігурації під час запуску.
+var DefaultCurrency = "UAH"
+
+// minorUnits містить кількість знаків дробової частини для валют,
+// що відрізняються від типових двох
+var minorUnits = map[string]int32{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+// Money — сума у валюті ISO 4217
+type Money struct {
+	Amount   decimal.Decimal
+	Currency string
+}
+
+// New створює суму з десяткового значення
+func New(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Parse розбирає десятковий рядок на кшталт "12.50"
+func Parse(amount, currency string) (Money, error) {
+	d, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf("money: invalid amount %q", amount)
+	}
+	return New(d, currency), nil
+}
+
+// MustParse працює як Parse, але панікує при помилці; для тестів і констант
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromMinor створює суму з кількості мінімальних одиниць (копійок, центів)
+func FromMinor(minor int64, currency string) Money {
+	currency = strings.ToUpper(currency)
+	return Money{Amount: decimal.New(minor, -Exponent(currency)), Currency: currency}
+}
+
+// Zero повертає нульову суму у валюті
+func Zero(currency string) Money {
+	return New(decimal.Zero, currency)
+}
+
+// Exponent повертає кількість знаків після коми для валюти
+func Exponent(currency string) int32 {
+	if e, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return e
+	}
+	return 2
+}
+
+// ValidCurrency перевіряє, що код валюти складається з трьох латинських літер
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor повертає суму в мінімальних одиницях валюти з банківським округленням
+func (m Money) Minor() int64 {
+	return m.Round().Amount.Shift(Exponent(m.Currency)).IntPart()
+}
+
+// Round округлює суму до мінімальної одиниці валюти (банківське округлення)
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.RoundBank(Exponent(m.Currency)), Currency: m.Currency}
+}
+
+// Exact повідомляє, чи сума виражається цілою кількістю мінімальних одиниць
+// валюти, тобто не має зайвих знаків після коми
+func (m Money) Exact() bool {
+	return m.Amount.Equal(m.Amount.Truncate(Exponent(m.Currency)))
+}
+
+// Add повертає m + o; суми в різних валютах не додаються
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(o.Amount), Currency: m.Currency}, nil
+}
+
+// Sub повертає m - o; суми в різних валютах не віднімаються
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(o.Amount), Currency: m.Currency}, nil
+}
+
+// Mul повертає суму, помножену на ціле число (наприклад, кількість товару)
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount.Mul(decimal.NewFromInt(n)), Currency: m.Currency}
+}
+
+// MulDecimal повертає суму, помножену на десятковий коефіцієнт, без округлення
+func (m Money) MulDecimal(d decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(d), Currency: m.Currency}
+}
+
+// Percent повертає pct відсотків від суми, округлені до мінімальної одиниці
+func (m Money) Percent(pct decimal.Decimal) Money {
+	return m.MulDecimal(pct.Div(decimal.NewFromInt(100))).Round()
+}
+
+// Cmp порівнює суми в одній валюті: -1, 0 або 1
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(o.Amount), nil
+}
+
+// IsZero повідомляє, чи дорівнює сума нулю
+func (m Money) IsZero() bool { return m.Amount.IsZero() }
+
+// IsNegative повідомляє, чи сума від'ємна
+func (m Money) IsNegative() bool { return m.Amount.IsNegative() }
+
+// StringFixed повертає суму з кількістю знаків, визначеною валютою, наприклад "12.50"
+func (m Money) StringFixed() string {
+	return m.Amount.StringFixedBank(Exponent(m.Currency))
+}
+
+// String повертає суму з валютою, наприклад "12.50 UAH"
+func (m Money) String() string {
+	return m.StringFixed() + " " + m.Currency
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("money: currency mismatch %s and %s", m.Currency, o.Currency)
+	}
+	return nil
+}
+
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON кодує суму як {"amount":"12.50","currency":"UAH"};
+// сума передається рядком, щоб клієнти не перетворювали її на float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.StringFixed(), Currency: m.Currency})
+}
+
+// UnmarshalJSON приймає об'єкт {"amount","currency"}, а також рядок або число
+// з сумою у валюті за замовчуванням. Число розбирається з тексту JSON без float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	// null, як і відсутнє поле, лишає суму нульовою без валюти
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		var raw struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		var amount decimal.Decimal
+		if err := amount.UnmarshalJSON(raw.Amount); err != nil {
+			return fmt.Errorf("money: invalid amount %s", raw.Amount)
+		}
+		currency := raw.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		*m = New(amount, currency)
+		return nil
+	}
+
+	var amount decimal.Decimal
+	if err := amount.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("money: invalid amount %s", data)
+	}
+	*m = New(amount, DefaultCurrency)
+	return nil
+}
@@ -0,0 +1,58 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArithmeticIsExact(t *testing.T) {
+	// 0.1 + 0.2 у float64 дає 0.30000000000000004
+	sum, err := MustParse("0.10", "UAH").Add(MustParse("0.20", "UAH"))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.30", sum.StringFixed())
+
+	assert.Equal(t, "59.97", MustParse("19.99", "UAH").Mul(3).StringFixed())
+	assert.Equal(t, int64(5997), MustParse("19.99", "UAH").Mul(3).Minor())
+
+	// 20% ПДВ від 0.125 з банківським округленням
+	assert.Equal(t, "0.02", MustParse("0.125", "UAH").Percent(decimal.NewFromInt(20)).StringFixed())
+
+	_, err = MustParse("1", "UAH").Add(MustParse("1", "USD"))
+	assert.Error(t, err)
+}
+
+func TestMinorUnits(t *testing.T) {
+	assert.Equal(t, "12.34", FromMinor(1234, "UAH").StringFixed())
+	assert.Equal(t, "1234", FromMinor(1234, "JPY").StringFixed())
+	assert.True(t, MustParse("12.340", "UAH").Exact())
+	assert.False(t, MustParse("12.345", "UAH").Exact())
+	assert.False(t, MustParse("1.5", "JPY").Exact())
+}
+
+func TestJSON(t *testing.T) {
+	body, err := json.Marshal(MustParse("12.5", "usd"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"12.50","currency":"USD"}`, string(body))
+
+	for input, want := range map[string]string{
+		`{"amount":"12.50","currency":"EUR"}`: "12.50 EUR",
+		`{"amount":12.5}`:                     "12.50 UAH",
+		`"0.10"`:                              "0.10 UAH",
+		`10.5`:                                "10.50 UAH",
+	} {
+		var m Money
+		assert.NoError(t, json.Unmarshal([]byte(input), &m), input)
+		assert.Equal(t, want, m.String(), input)
+	}
+
+	var m Money
+	assert.Error(t, json.Unmarshal([]byte(`"ten"`), &m))
+
+	// null не підставляє валюту за замовчуванням, тож ціна лишається незаданою
+	var p struct{ Price Money }
+	assert.NoError(t, json.Unmarshal([]byte(`{"price":null}`), &p))
+	assert.Equal(t, Money{}, p.Price)
+}
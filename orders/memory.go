@@ -0,0 +1,242 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// MemoryRepository зберігає замовлення у пам'яті; безпечний для одночасного використання.
+// Залишки списуються через products.ProductRepository.AdjustStock і повертаються,
+// якщо замовлення не вдалося оформити; використання купонів записуються останніми
+// через discounts.CouponRepository.Redeem. Призначений для тестів та локальної розробки.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	orders   map[int]Order
+	history  []StatusChange
+	nextID   int
+	itemID   int
+	products products.ProductRepository
+	coupons  discounts.CouponRepository
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті; c може бути nil,
+// якщо замовлення оформлюються без купонів
+func NewMemoryRepository(p products.ProductRepository, c discounts.CouponRepository) *MemoryRepository {
+	return &MemoryRepository{orders: make(map[int]Order), nextID: 1, itemID: 1, products: p, coupons: c}
+}
+
+// copyOrder повертає копію замовлення, щоб виклики не ділили слайс позицій
+func copyOrder(o Order) Order {
+	o.Items = append([]Item{}, o.Items...)
+	return o
+}
+
+func (m *MemoryRepository) Checkout(ctx context.Context, userID int, lines []Line, coupons []discounts.Candidate) (order Order, err error) {
+	if err := ctx.Err(); err != nil {
+		return Order{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Уже списані залишки повертаються, якщо оформлення не вдалося
+	var taken []Line
+	defer func() {
+		if err != nil {
+			for _, l := range taken {
+				m.products.AdjustStock(context.Background(), l.ProductID, l.Quantity)
+			}
+		}
+	}()
+
+	now := time.Now().UTC()
+	o := Order{UserID: userID, Status: StatusPending, CreatedAt: now, UpdatedAt: now}
+	var cart []discounts.CartLine
+	for _, l := range lines {
+		if err := m.products.AdjustStock(ctx, l.ProductID, -l.Quantity); err != nil {
+			if err == products.ErrNotFound || err == products.ErrInsufficientStock {
+				return Order{}, &LineError{ProductID: l.ProductID, Err: err}
+			}
+			return Order{}, err
+		}
+		taken = append(taken, l)
+
+		p, err := m.products.Get(ctx, l.ProductID)
+		if err != nil {
+			return Order{}, err
+		}
+		o.Items = append(o.Items, newItem(l, p.Name, p.Price))
+		cart = append(cart, discounts.CartLine{ProductID: p.ID, CategoryID: p.CategoryID, Quantity: l.Quantity, UnitPrice: p.Price})
+	}
+	if err := o.sum(); err != nil {
+		return Order{}, err
+	}
+	if err := o.applyCoupons(cart, coupons, now); err != nil {
+		return Order{}, err
+	}
+
+	// Купони записуються останніми: після Redeem оформлення вже не може не вдатися
+	if len(coupons) > 0 {
+		if err := m.coupons.Redeem(ctx, couponIDs(coupons), userID, m.nextID); err != nil {
+			return Order{}, err
+		}
+	}
+
+	o.ID = m.nextID
+	m.nextID++
+	for i := range o.Items {
+		o.Items[i].ID = m.itemID
+		m.itemID++
+	}
+	m.orders[o.ID] = copyOrder(o)
+	m.addChange(StatusChange{OrderID: o.ID, To: o.Status, ActorID: &userID, CreatedAt: now})
+	return o, nil
+}
+
+// addChange додає запис в історію статусів; викликається під m.mu
+func (m *MemoryRepository) addChange(c StatusChange) {
+	c.ID = len(m.history) + 1
+	m.history = append(m.history, c)
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Order, error) {
+	if err := ctx.Err(); err != nil {
+		return Order{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	o, ok := m.orders[id]
+	if !ok {
+		return Order{}, ErrNotFound
+	}
+	return copyOrder(o), nil
+}
+
+// newer повідомляє, чи йде замовлення a перед b у списку: від новіших до старіших
+func newer(a, b Order) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+// matching повертає замовлення, що задовольняють фільтр; викликається під m.mu
+func (m *MemoryRepository) matching(f Filter) []Order {
+	var matched []Order
+	for _, o := range m.orders {
+		if f.UserID != 0 && o.UserID != f.UserID ||
+			f.Status != "" && o.Status != f.Status ||
+			!f.From.IsZero() && o.CreatedAt.Before(f.From) ||
+			!f.To.IsZero() && !o.CreatedAt.Before(f.To) {
+			continue
+		}
+		matched = append(matched, o)
+	}
+	return matched
+}
+
+func (m *MemoryRepository) List(ctx context.Context, f Filter) ([]Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var edge Order
+	if f.Page.Cursor != nil {
+		createdAt, ok := boundary(*f.Page.Cursor)
+		if !ok {
+			return nil, errors.New("orders: malformed cursor")
+		}
+		edge = Order{ID: f.Page.Cursor.ID, CreatedAt: createdAt}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := m.matching(f)
+	backward := f.Page.Backward()
+	sort.Slice(matched, func(i, j int) bool {
+		return newer(matched[i], matched[j]) != backward
+	})
+
+	orders := []Order{}
+	for _, o := range matched {
+		if len(orders) > f.Page.Limit {
+			break
+		}
+		if f.Page.Cursor != nil && (o.ID == edge.ID || newer(o, edge) != backward) {
+			continue
+		}
+		orders = append(orders, copyOrder(o))
+	}
+	return orders, nil
+}
+
+func (m *MemoryRepository) Count(ctx context.Context, f Filter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.matching(f)), nil
+}
+
+func (m *MemoryRepository) SetStatus(ctx context.Context, change StatusChange, restock bool) (Order, error) {
+	if err := ctx.Err(); err != nil {
+		return Order{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, ok := m.orders[change.OrderID]
+	if !ok {
+		return Order{}, ErrNotFound
+	}
+	if o.Status != change.From {
+		return Order{}, ErrStatusChanged
+	}
+
+	if restock {
+		for _, item := range o.Items {
+			err := m.products.AdjustStock(ctx, item.ProductID, item.Quantity)
+			if err != nil && err != products.ErrNotFound {
+				return Order{}, err
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	o.Status = change.To
+	o.UpdatedAt = now
+	m.orders[o.ID] = o
+	change.CreatedAt = now
+	m.addChange(change)
+	return copyOrder(o), nil
+}
+
+func (m *MemoryRepository) History(ctx context.Context, orderID int) ([]StatusChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history := []StatusChange{}
+	for _, c := range m.history {
+		if c.OrderID == orderID {
+			history = append(history, c)
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,452 @@
+// Package orders реалізує замовлення та їх оформлення зі списанням залишків.
+package orders
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Статуси замовлення
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusPacked    = "packed"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded"
+)
+
+// Statuses — усі відомі статуси замовлення
+var Statuses = []string{StatusPending, StatusPaid, StatusPacked, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded}
+
+// Order представляє замовлення
+type Order struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"userID"`
+	Status string `json:"status"`
+	Items  []Item `json:"items"`
+	// Discount — знижка за купонами; Total — сума позицій мінус Discount
+	Discount  money.Money `json:"discount"`
+	Total     money.Money `json:"total"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Item — позиція замовлення зі знімком назви та ціни продукту на момент оформлення
+type Item struct {
+	ID        int         `json:"id"`
+	ProductID int         `json:"productID"`
+	Name      string      `json:"name"`
+	UnitPrice money.Money `json:"unitPrice"`
+	Quantity  int         `json:"quantity"`
+	LineTotal money.Money `json:"lineTotal"`
+}
+
+// OrderInput — тіло запиту на оформлення замовлення
+type OrderInput struct {
+	Items []Line `json:"items"`
+	// Codes — коди купонів, які покупець застосовує до замовлення
+	Codes []string `json:"codes"`
+}
+
+// OrderService надає методи для роботи з замовленнями
+type OrderService struct {
+	Repo OrderRepository
+	// Discounts шукає купони за кодами; nil вимикає купони
+	Discounts *discounts.DiscountService
+}
+
+var errInvalidID = problem.New(http.StatusBadRequest, "invalid order ID")
+
+// newItem створює позицію зі знімком назви та ціни продукту
+func newItem(l Line, name string, price money.Money) Item {
+	return Item{
+		ProductID: l.ProductID,
+		Name:      name,
+		UnitPrice: price,
+		Quantity:  l.Quantity,
+		LineTotal: price.Mul(int64(l.Quantity)),
+	}
+}
+
+// sum обчислює загальну суму замовлення
+func (o *Order) sum() error {
+	if len(o.Items) == 0 {
+		o.Total = money.Zero(money.DefaultCurrency)
+		return nil
+	}
+	o.Total = money.Zero(o.Items[0].UnitPrice.Currency)
+	for _, item := range o.Items {
+		total, err := o.Total.Add(item.LineTotal)
+		if err != nil {
+			return &LineError{ProductID: item.ProductID, Err: ErrCurrencyMismatch}
+		}
+		o.Total = total
+	}
+	return nil
+}
+
+// applyCoupons обчислює знижку купонів для позицій cart та віднімає її від
+// суми замовлення; повертає *CouponError, якщо якийсь купон не підходить
+func (o *Order) applyCoupons(cart []discounts.CartLine, coupons []discounts.Candidate, now time.Time) error {
+	o.Discount = money.Zero(o.Total.Currency)
+	if len(coupons) == 0 {
+		return nil
+	}
+	e := discounts.Evaluate(cart, coupons, o.UserID, now)
+	if len(e.Rejected) > 0 {
+		return &CouponError{Rejected: e.Rejected}
+	}
+	o.Discount = e.Discount
+	o.Total = e.Total
+	return nil
+}
+
+// couponIDs повертає ID купонів, використання яких записується з замовленням
+func couponIDs(coupons []discounts.Candidate) []int {
+	ids := make([]int, len(coupons))
+	for i, c := range coupons {
+		ids[i] = c.Coupon.ID
+	}
+	return ids
+}
+
+// lines перевіряє позиції запиту та об'єднує повтори одного продукту
+func (in OrderInput) lines() ([]Line, error) {
+	v := validate.New()
+	v.Check(len(in.Items) > 0, "items", "must contain at least one item")
+	var lines []Line
+	index := make(map[int]int)
+	for i, l := range in.Items {
+		field := "items[" + strconv.Itoa(i) + "]"
+		v.Positive(field+".productID", l.ProductID)
+		v.Positive(field+".quantity", l.Quantity)
+		if j, ok := index[l.ProductID]; ok {
+			lines[j].Quantity += l.Quantity
+			continue
+		}
+		index[l.ProductID] = len(lines)
+		lines = append(lines, l)
+	}
+	return lines, v.Err()
+}
+
+// lineProblem перетворює *LineError на відповідь 422 з полем позиції запиту
+func (in OrderInput) lineProblem(lineErr *LineError) *problem.Problem {
+	field := "items"
+	for i, l := range in.Items {
+		if l.ProductID == lineErr.ProductID {
+			field = "items[" + strconv.Itoa(i) + "]"
+			break
+		}
+	}
+	switch lineErr.Err {
+	case products.ErrNotFound:
+		return validate.Failed(problem.FieldError{Field: field + ".productID", Message: "product does not exist"})
+	case products.ErrInsufficientStock:
+		return validate.Failed(problem.FieldError{Field: field + ".quantity", Message: "not enough in stock"})
+	default:
+		return validate.Failed(problem.FieldError{Field: field + ".productID", Message: "all products must be priced in the same currency"})
+	}
+}
+
+// couponProblem перетворює відхилені купони на відповідь 422 з полями codes[i]
+func (in OrderInput) couponProblem(rejected []discounts.Rejected) *problem.Problem {
+	var fields []problem.FieldError
+	for _, rej := range rejected {
+		field := "codes"
+		for i, code := range in.Codes {
+			if strings.EqualFold(strings.TrimSpace(code), rej.Code) {
+				field = "codes[" + strconv.Itoa(i) + "]"
+				break
+			}
+		}
+		fields = append(fields, problem.FieldError{Field: field, Message: rej.Reason})
+	}
+	return validate.Failed(fields...)
+}
+
+// CreateOrder оформлює замовлення поточного користувача
+// POST /orders
+func (s *OrderService) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+
+	var input OrderInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	lines, err := input.lines()
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	var coupons []discounts.Candidate
+	if len(input.Codes) > 0 {
+		if s.Discounts == nil {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: "codes", Message: "coupons are not accepted"}))
+			return
+		}
+		var unknown []discounts.Rejected
+		coupons, unknown, err = s.Discounts.Candidates(r.Context(), input.Codes, principal.UserID)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if len(unknown) > 0 {
+			problem.Write(w, r, input.couponProblem(unknown))
+			return
+		}
+	}
+
+	order, err := s.Repo.Checkout(r.Context(), principal.UserID, lines, coupons)
+	if err != nil {
+		var (
+			lineErr   *LineError
+			couponErr *CouponError
+		)
+		switch {
+		case errors.As(err, &lineErr):
+			problem.Write(w, r, input.lineProblem(lineErr))
+		case errors.As(err, &couponErr):
+			problem.Write(w, r, input.couponProblem(couponErr.Rejected))
+		case err == discounts.ErrLimitReached:
+			problem.Write(w, r, problem.New(http.StatusConflict, "coupon usage limit reached"))
+		case err == discounts.ErrNotFound:
+			problem.Write(w, r, problem.New(http.StatusConflict, "coupon no longer exists"))
+		default:
+			log.Println("Error creating order:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	render.JSON(w, r, http.StatusCreated, order)
+}
+
+// GetOrders повертає сторінку замовлень. Покупці бачать лише власні
+// замовлення; користувачі з правом orders:read — усі, з фільтром userID.
+// GET /orders?limit=10&cursor=...&total=true&status=pending&userID=1&from=2024-01-01&to=2024-02-01
+func (s *OrderService) GetOrders(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	query := r.URL.Query()
+
+	v := validate.New()
+	f := Filter{Status: query.Get("status"), Page: pagination.Parse(query, v, "")}
+	if f.Page.Cursor != nil {
+		_, ok := boundary(*f.Page.Cursor)
+		v.Check(ok, "cursor", "is malformed")
+	}
+	if f.Status != "" {
+		v.Check(knownStatus(f.Status), "status", "unknown order status")
+	}
+	var err error
+	if value := query.Get("userID"); value != "" {
+		f.UserID, err = strconv.Atoi(value)
+		v.Check(err == nil && f.UserID > 0, "userID", "must be a positive integer")
+	}
+	f.From, err = parseDate(query.Get("from"))
+	v.Check(err == nil, "from", "must be a date (2006-01-02) or RFC 3339 timestamp")
+	f.To, err = parseDate(query.Get("to"))
+	v.Check(err == nil, "to", "must be a date (2006-01-02) or RFC 3339 timestamp")
+	if err := v.QueryErr(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if !principal.Can(rbac.PermOrdersRead) {
+		f.UserID = principal.UserID
+	}
+
+	orders, err := s.Repo.List(r.Context(), f)
+	if err != nil {
+		log.Println("Error querying orders:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	var list pagination.List[Order]
+	list.Data, list.Page = pagination.Build(orders, f.Page, cursor)
+
+	if f.Page.Total {
+		total, err := s.Repo.Count(r.Context(), f)
+		if err != nil {
+			log.Println("Error counting orders:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		list.Page.Total = &total
+	}
+
+	pagination.SetLink(w, r, list.Page)
+	render.JSON(w, r, http.StatusOK, list)
+}
+
+// GetOrder повертає замовлення за ID; чуже замовлення доступне лише з правом orders:read
+// GET /orders/{id}
+func (s *OrderService) GetOrder(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	order, ok := s.load(w, r, principal, orderID)
+	if !ok {
+		return
+	}
+	render.JSON(w, r, http.StatusOK, order)
+}
+
+// Transition переводить замовлення у статус to, якщо автомат статусів це
+// дозволяє, та записує зміну в історію. actorID дорівнює nil для системних змін.
+// Повертає *TransitionError для заборонених переходів.
+func (s *OrderService) Transition(ctx context.Context, orderID int, to string, actorID *int, note string) (Order, error) {
+	order, err := s.Repo.Get(ctx, orderID)
+	if err != nil {
+		return Order{}, err
+	}
+	if !CanTransition(order.Status, to) {
+		return Order{}, &TransitionError{From: order.Status, To: to}
+	}
+	change := StatusChange{OrderID: orderID, From: order.Status, To: to, ActorID: actorID, Note: note}
+	return s.Repo.SetStatus(ctx, change, restocks(order.Status, to))
+}
+
+// StatusInput — тіло запиту на зміну статусу замовлення
+type StatusInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// ChangeStatus змінює статус замовлення. Користувачі з правом orders:manage
+// виконують будь-які дозволені переходи; покупець може лише скасувати власне
+// неоплачене замовлення.
+// POST /orders/{id}/status
+func (s *OrderService) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var input StatusInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.Check(knownStatus(input.Status), "status", "unknown order status")
+	v.MaxLen("note", input.Note, 255)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	order, ok := s.load(w, r, principal, orderID)
+	if !ok {
+		return
+	}
+	if !principal.Can(rbac.PermOrdersManage) && !(order.Status == StatusPending && input.Status == StatusCancelled) {
+		problem.Write(w, r, problem.Newf(http.StatusForbidden, "permission %q is required", rbac.PermOrdersManage))
+		return
+	}
+
+	updated, err := s.Transition(r.Context(), orderID, input.Status, &principal.UserID, input.Note)
+	if err != nil {
+		var transitionErr *TransitionError
+		switch {
+		case errors.As(err, &transitionErr):
+			problem.Write(w, r, problem.Newf(http.StatusConflict, "cannot change order status from %s to %s", transitionErr.From, transitionErr.To))
+		case err == ErrStatusChanged:
+			problem.Write(w, r, problem.New(http.StatusConflict, "order status was changed by another request"))
+		case err == ErrNotFound:
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "order %d not found", orderID))
+		default:
+			log.Println("Error changing order status:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, updated)
+}
+
+// GetOrderHistory повертає історію статусів замовлення
+// GET /orders/{id}/history
+func (s *OrderService) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+	if _, ok := s.load(w, r, principal, orderID); !ok {
+		return
+	}
+
+	history, err := s.Repo.History(r.Context(), orderID)
+	if err != nil {
+		log.Println("Error querying order history:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, history)
+}
+
+// load повертає замовлення, якщо воно належить користувачу або той має право
+// orders:read. Чужі замовлення не розкриваються навіть фактом існування.
+// У разі помилки відповідь уже надіслано.
+func (s *OrderService) load(w http.ResponseWriter, r *http.Request, principal rbac.Principal, orderID int) (Order, bool) {
+	order, err := s.Repo.Get(r.Context(), orderID)
+	if err == nil && order.UserID != principal.UserID && !principal.Can(rbac.PermOrdersRead) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "order %d not found", orderID))
+		} else {
+			log.Println("Error querying order:", err)
+			problem.Error(w, r, err)
+		}
+		return Order{}, false
+	}
+	return order, true
+}
+
+func knownStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate розбирає дату фільтра; порожнє значення повертає нульовий час
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
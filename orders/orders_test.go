@@ -0,0 +1,340 @@
+package orders_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/orders"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+)
+
+// newRouter імітує auth та rbac middleware: користувач береться із заголовка
+// X-User, а заголовок X-Admin надає права orders:read та orders:manage
+func newRouter(svc *orders.OrderService) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.Atoi(r.Header.Get("X-User"))
+			p := rbac.Principal{UserID: id, Permissions: map[string]bool{}}
+			if r.Header.Get("X-Admin") != "" {
+				p.Permissions[rbac.PermOrdersRead] = true
+				p.Permissions[rbac.PermOrdersManage] = true
+			}
+			next.ServeHTTP(w, r.WithContext(rbac.WithPrincipal(r.Context(), p)))
+		})
+	})
+	r.Get("/orders", svc.GetOrders)
+	r.Get("/orders/{id}", svc.GetOrder)
+	r.Post("/orders", svc.CreateOrder)
+	r.Post("/orders/{id}/status", svc.ChangeStatus)
+	r.Get("/orders/{id}/history", svc.GetOrderHistory)
+	return r
+}
+
+func do(r http.Handler, method, path, userID, body string, admin bool) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", userID)
+	if admin {
+		req.Header.Set("X-Admin", "1")
+	}
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+type fixture struct {
+	products products.ProductRepository
+	orders   orders.OrderRepository
+	coupons  discounts.CouponRepository
+}
+
+// fixtures повертає репозиторії в пам'яті та на SQLite з двома продуктами
+func fixtures(t *testing.T) map[string]fixture {
+	ctx := context.Background()
+	seed := func(repo products.ProductRepository) {
+		assert.NoError(t, repo.Create(ctx, &products.Product{Name: "Go", Price: money.MustParse("10.50", "UAH"), StockQuantity: 3, CategoryID: 1}))
+		assert.NoError(t, repo.Create(ctx, &products.Product{Name: "SQL", Price: money.MustParse("4.25", "UAH"), StockQuantity: 1, CategoryID: 1}))
+	}
+
+	cats := categories.NewMemoryRepository()
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+	memProducts := products.NewMemoryRepository(cats)
+	seed(memProducts)
+	memCoupons := discounts.NewMemoryRepository()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlProducts := products.NewSQLRepository(db, d)
+	seed(sqlProducts)
+
+	return map[string]fixture{
+		"memory": {memProducts, orders.NewMemoryRepository(memProducts, memCoupons), memCoupons},
+		"sqlite": {sqlProducts, orders.NewSQLRepository(db, d), discounts.NewSQLRepository(db, d)},
+	}
+}
+
+func stock(t *testing.T, repo products.ProductRepository, id int) int {
+	p, err := repo.Get(context.Background(), id)
+	assert.NoError(t, err)
+	return p.StockQuantity
+}
+
+func TestCheckout(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(&orders.OrderService{Repo: f.orders})
+
+			// Замовлення зберігає знімок ціни та списує залишки
+			rr := do(r, "POST", "/orders", "7", `{"items":[{"productID":1,"quantity":1},{"productID":2,"quantity":1},{"productID":1,"quantity":1}]}`, false)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var order orders.Order
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &order))
+			assert.Equal(t, 7, order.UserID)
+			assert.Equal(t, orders.StatusPending, order.Status)
+			assert.Len(t, order.Items, 2)
+			assert.Equal(t, "25.25 UAH", order.Total.String())
+			assert.Equal(t, 1, stock(t, f.products, 1))
+			assert.Equal(t, 0, stock(t, f.products, 2))
+
+			// Нестача одного продукту скасовує все замовлення
+			rr = do(r, "POST", "/orders", "7", `{"items":[{"productID":1,"quantity":1},{"productID":2,"quantity":1}]}`, false)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"items[1].quantity"`)
+			assert.Equal(t, 1, stock(t, f.products, 1))
+
+			rr = do(r, "POST", "/orders", "7", `{"items":[{"productID":9,"quantity":1}]}`, false)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"items[0].productID"`)
+			assert.Equal(t, http.StatusUnprocessableEntity, do(r, "POST", "/orders", "7", `{"items":[]}`, false).Code)
+
+			// Знімок не змінюється разом з ціною продукту
+			p, err := f.products.Get(context.Background(), 1)
+			assert.NoError(t, err)
+			p.Price = money.MustParse("99.00", "UAH")
+			assert.NoError(t, f.products.Update(context.Background(), 1, &p))
+			rr = do(r, "GET", "/orders/"+strconv.Itoa(order.ID), "7", "", false)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var got orders.Order
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.Equal(t, "10.50 UAH", got.Items[0].UnitPrice.String())
+
+			// Чуже замовлення бачить лише адміністратор
+			assert.Equal(t, http.StatusNotFound, do(r, "GET", "/orders/"+strconv.Itoa(order.ID), "8", "", false).Code)
+			assert.Equal(t, http.StatusOK, do(r, "GET", "/orders/"+strconv.Itoa(order.ID), "8", "", true).Code)
+		})
+	}
+}
+
+func TestCheckoutWithCoupons(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newRouter(&orders.OrderService{Repo: f.orders, Discounts: &discounts.DiscountService{Repo: f.coupons, Products: f.products}})
+			c := discounts.Coupon{Code: "TEN", Kind: discounts.KindPercentage, Percent: decimal.NewFromInt(10), MaxUses: 1, Active: true}
+			assert.NoError(t, f.coupons.Create(ctx, &c))
+
+			// Знижка віднімається від суми, а використання записується разом із замовленням
+			rr := do(r, "POST", "/orders", "7", `{"items":[{"productID":1,"quantity":1}],"codes":["ten"]}`, false)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var order orders.Order
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &order))
+			assert.Equal(t, "1.05 UAH", order.Discount.String())
+			assert.Equal(t, "9.45 UAH", order.Total.String())
+			got, err := f.orders.Get(ctx, order.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "1.05 UAH", got.Discount.String())
+			assert.Equal(t, "9.45 UAH", got.Total.String())
+			u, err := f.coupons.Usage(ctx, c.ID, 7)
+			assert.NoError(t, err)
+			assert.Equal(t, discounts.Usage{Total: 1, ByUser: 1}, u)
+
+			// Вичерпаний та невідомий купони відхиляються без списання залишків
+			for _, code := range []string{"TEN", "nope"} {
+				rr = do(r, "POST", "/orders", "8", `{"items":[{"productID":1,"quantity":1}],"codes":["`+code+`"]}`, false)
+				assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, code)
+				assert.Contains(t, rr.Body.String(), `"field":"codes[0]"`, code)
+			}
+			assert.Contains(t, rr.Body.String(), `"message":"code does not exist"`)
+			assert.Equal(t, 2, stock(t, f.products, 1))
+
+			// Якщо ліміт вичерпано між оцінкою та оформленням, замовлення не створюється
+			_, err = f.orders.Checkout(ctx, 8, []orders.Line{{ProductID: 1, Quantity: 1}}, []discounts.Candidate{{Coupon: c}})
+			assert.Equal(t, discounts.ErrLimitReached, err)
+			assert.Equal(t, 2, stock(t, f.products, 1))
+			_, err = f.orders.Get(ctx, order.ID+1)
+			assert.Equal(t, orders.ErrNotFound, err)
+
+			// Без DiscountService коди не приймаються
+			r = newRouter(&orders.OrderService{Repo: f.orders})
+			rr = do(r, "POST", "/orders", "8", `{"items":[{"productID":1,"quantity":1}],"codes":["ten"]}`, false)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"codes"`)
+		})
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(&orders.OrderService{Repo: f.orders})
+			for _, user := range []string{"7", "8", "7"} {
+				assert.Equal(t, http.StatusCreated, do(r, "POST", "/orders", user, `{"items":[{"productID":1,"quantity":1}]}`, false).Code)
+			}
+
+			page := func(path, user string, admin bool) pagination.List[orders.Order] {
+				rr := do(r, "GET", path, user, "", admin)
+				assert.Equal(t, http.StatusOK, rr.Code, path)
+				var list pagination.List[orders.Order]
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+				return list
+			}
+			list := func(path, user string, admin bool) []orders.Order {
+				return page(path, user, admin).Data
+			}
+
+			// Покупець бачить лише власні замовлення, навіть з чужим userID
+			assert.Len(t, list("/orders", "7", false), 2)
+			assert.Len(t, list("/orders?userID=8", "7", false), 2)
+
+			// Адміністратор бачить усі, з фільтрами та пагінацією від новіших
+			all := list("/orders", "1", true)
+			assert.Len(t, all, 3)
+			assert.Equal(t, 3, all[0].ID)
+			assert.Len(t, list("/orders?userID=8", "1", true), 1)
+			first := page("/orders?status=pending&limit=2&total=true", "1", true)
+			assert.Len(t, first.Data, 2)
+			assert.Equal(t, 3, *first.Page.Total)
+			assert.Len(t, first.Data[0].Items, 1)
+			second := page("/orders?status=pending&limit=2&cursor="+first.Page.Next, "1", true)
+			assert.Len(t, second.Data, 1)
+			assert.Equal(t, 1, second.Data[0].ID)
+			assert.Empty(t, second.Page.Next)
+			back := page("/orders?status=pending&limit=2&cursor="+second.Page.Prev, "1", true)
+			assert.Equal(t, []int{3, 2}, []int{back.Data[0].ID, back.Data[1].ID})
+			assert.Equal(t, pagination.MaxLimit, page("/orders?limit=100000", "1", true).Page.Limit)
+			assert.Equal(t, http.StatusBadRequest, do(r, "GET", "/orders?cursor=bogus", "1", "", true).Code)
+			assert.Len(t, list("/orders?from=2000-01-01&to=2000-02-01", "1", true), 0)
+
+			rr := do(r, "GET", "/orders?status=lost&from=yesterday", "1", "", true)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"status"`)
+			assert.Contains(t, rr.Body.String(), `"field":"from"`)
+		})
+	}
+}
+
+func TestConcurrentCheckoutDoesNotOversell(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			var (
+				wg        sync.WaitGroup
+				mu        sync.Mutex
+				succeeded int
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(userID int) {
+					defer wg.Done()
+					_, err := f.orders.Checkout(context.Background(), userID, []orders.Line{{ProductID: 2, Quantity: 1}}, nil)
+					if err == nil {
+						mu.Lock()
+						succeeded++
+						mu.Unlock()
+					}
+				}(i + 1)
+			}
+			wg.Wait()
+
+			assert.Equal(t, 1, succeeded)
+			assert.Equal(t, 0, stock(t, f.products, 2))
+		})
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, orders.CanTransition(orders.StatusPending, orders.StatusPaid))
+	assert.True(t, orders.CanTransition(orders.StatusPacked, orders.StatusCancelled))
+	assert.True(t, orders.CanTransition(orders.StatusDelivered, orders.StatusRefunded))
+	assert.False(t, orders.CanTransition(orders.StatusPending, orders.StatusShipped))
+	assert.False(t, orders.CanTransition(orders.StatusShipped, orders.StatusCancelled))
+	assert.False(t, orders.CanTransition(orders.StatusCancelled, orders.StatusPending))
+	assert.Empty(t, orders.Next(orders.StatusRefunded))
+}
+
+func TestOrderStatusFlow(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(&orders.OrderService{Repo: f.orders})
+			assert.Equal(t, http.StatusCreated, do(r, "POST", "/orders", "7", `{"items":[{"productID":1,"quantity":2}]}`, false).Code)
+			assert.Equal(t, 1, stock(t, f.products, 1))
+
+			status := func(user string, admin bool, to string) *httptest.ResponseRecorder {
+				return do(r, "POST", "/orders/1/status", user, `{"status":"`+to+`"}`, admin)
+			}
+
+			// Покупець не може позначити замовлення оплаченим, а чуже навіть не бачить
+			assert.Equal(t, http.StatusForbidden, status("7", false, orders.StatusPaid).Code)
+			assert.Equal(t, http.StatusNotFound, status("8", false, orders.StatusCancelled).Code)
+
+			// Заборонені переходи відхиляються з 409
+			assert.Equal(t, http.StatusConflict, status("1", true, orders.StatusShipped).Code)
+			assert.Equal(t, http.StatusUnprocessableEntity, status("1", true, "lost").Code)
+
+			for _, to := range []string{orders.StatusPaid, orders.StatusPacked, orders.StatusShipped, orders.StatusDelivered} {
+				rr := status("1", true, to)
+				assert.Equal(t, http.StatusOK, rr.Code, to)
+				assert.Contains(t, rr.Body.String(), `"status":"`+to+`"`)
+			}
+			assert.Equal(t, http.StatusConflict, status("1", true, orders.StatusCancelled).Code)
+			assert.Equal(t, http.StatusOK, status("1", true, orders.StatusRefunded).Code)
+			// Повернення коштів після доставки не повертає товар на склад
+			assert.Equal(t, 1, stock(t, f.products, 1))
+
+			rr := do(r, "GET", "/orders/1/history", "7", "", false)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var history []orders.StatusChange
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &history))
+			assert.Len(t, history, 6)
+			assert.Equal(t, "", history[0].From)
+			assert.Equal(t, orders.StatusPending, history[0].To)
+			assert.Equal(t, 7, *history[0].ActorID)
+			assert.Equal(t, orders.StatusDelivered, history[5].From)
+			assert.Equal(t, orders.StatusRefunded, history[5].To)
+			assert.Equal(t, 1, *history[5].ActorID)
+
+			// Покупець може скасувати власне неоплачене замовлення; товар повертається на склад
+			assert.Equal(t, http.StatusCreated, do(r, "POST", "/orders", "7", `{"items":[{"productID":1,"quantity":1},{"productID":2,"quantity":1}]}`, false).Code)
+			assert.Equal(t, 0, stock(t, f.products, 1))
+			rr = do(r, "POST", "/orders/2/status", "7", `{"status":"cancelled","note":"changed my mind"}`, false)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, 1, stock(t, f.products, 1))
+			assert.Equal(t, 1, stock(t, f.products, 2))
+			assert.Equal(t, http.StatusConflict, do(r, "POST", "/orders/2/status", "1", `{"status":"cancelled"}`, true).Code)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// ErrNotFound повертається репозиторієм, якщо замовлення з таким ID не існує
+var ErrNotFound = errors.New("orders: not found")
+
+// ErrCurrencyMismatch повертається, якщо продукти замовлення мають різні валюти
+var ErrCurrencyMismatch = errors.New("orders: products are priced in different currencies")
+
+// LineError описує позицію, через яку замовлення не вдалося оформити.
+// Err — products.ErrNotFound, products.ErrInsufficientStock або ErrCurrencyMismatch.
+type LineError struct {
+	ProductID int
+	Err       error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("orders: product %d: %v", e.ProductID, e.Err)
+}
+
+func (e *LineError) Unwrap() error { return e.Err }
+
+// CouponError повертається Checkout, якщо хоча б один купон не можна
+// застосувати до замовлення
+type CouponError struct {
+	Rejected []discounts.Rejected
+}
+
+func (e *CouponError) Error() string {
+	return fmt.Sprintf("orders: coupon %s rejected: %s", e.Rejected[0].Code, e.Rejected[0].Reason)
+}
+
+// Line — продукт і кількість у запиті на оформлення замовлення
+type Line struct {
+	ProductID int `json:"productID"`
+	Quantity  int `json:"quantity"`
+}
+
+// Filter обмежує вибірку замовлень; нульові поля не фільтрують
+type Filter struct {
+	UserID int
+	Status string
+	// From та To обмежують дату створення: From включно, To — ні
+	From time.Time
+	To   time.Time
+	// Page — межа та розмір сторінки
+	Page pagination.Query
+}
+
+// OrderRepository описує сховище замовлень, з яким працює OrderService
+type OrderRepository interface {
+	// Checkout в одній транзакції списує залишки продуктів та створює замовлення
+	// зі знімком назв і цін та першим записом історії статусів. Якщо хоча б
+	// одну позицію не можна виконати, нічого не змінюється і повертається *LineError.
+	// Купони coupons оцінюються за цінами з тієї ж транзакції, їхня знижка
+	// віднімається від суми, а використання записується разом із замовленням;
+	// якщо купон не підходить, повертається *CouponError, а якщо його ліміт
+	// вичерпано — discounts.ErrLimitReached.
+	Checkout(ctx context.Context, userID int, lines []Line, coupons []discounts.Candidate) (Order, error)
+	// Get повертає замовлення з позиціями або ErrNotFound
+	Get(ctx context.Context, id int) (Order, error)
+	// List повертає до f.Page.Limit+1 замовлень з позиціями після межі курсора,
+	// від новіших до старіших (для зворотного курсора — перед межею, від старіших)
+	List(ctx context.Context, f Filter) ([]Order, error)
+	// Count повертає кількість замовлень, що задовольняють фільтр; сторінка ігнорується
+	Count(ctx context.Context, f Filter) (int, error)
+	// SetStatus в одній транзакції змінює статус замовлення з change.From на
+	// change.To, записує change в історію та, якщо restock, повертає позиції на
+	// склад. Якщо поточний статус уже не change.From, повертає ErrStatusChanged.
+	SetStatus(ctx context.Context, change StatusChange, restock bool) (Order, error)
+	// History повертає історію статусів замовлення від найстарішого запису
+	History(ctx context.Context, orderID int) ([]StatusChange, error)
+}
+
+// cursor повертає курсор, межею якого є замовлення
+func cursor(o Order) pagination.Cursor {
+	return pagination.Cursor{ID: o.ID, Values: []string{o.CreatedAt.UTC().Format(time.RFC3339Nano)}}
+}
+
+// boundary розбирає дату створення межового замовлення з курсора
+func boundary(c pagination.Cursor) (time.Time, bool) {
+	if len(c.Values) != 1 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, c.Values[0])
+	return t, err == nil
+}
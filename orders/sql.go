@@ -0,0 +1,349 @@
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/discounts"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// SQLRepository зберігає замовлення у таблицях orders та order_items SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+func (m *SQLRepository) Checkout(ctx context.Context, userID int, lines []Line, coupons []discounts.Candidate) (Order, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, err
+	}
+	defer tx.Rollback()
+
+	// Рядки продуктів блокуються у порядку зростання ID, щоб одночасні
+	// замовлення не потрапляли у взаємне блокування
+	lines = append([]Line(nil), lines...)
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+
+	now := time.Now().UTC()
+	o := Order{UserID: userID, Status: StatusPending, CreatedAt: now, UpdatedAt: now}
+	var cart []discounts.CartLine
+	for _, l := range lines {
+		// Умова stock_quantity >= ? перевіряється атомарно з оновленням:
+		// з двох покупців останньої одиниці успішним буде лише один
+		result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE products SET stock_quantity = stock_quantity - ?, updated_at = ? WHERE id = ? AND stock_quantity >= ?"),
+			l.Quantity, now, l.ProductID, l.Quantity)
+		if err != nil {
+			return Order{}, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return Order{}, err
+		}
+
+		var (
+			name       string
+			price      money.Money
+			categoryID int
+		)
+		err = tx.QueryRowContext(ctx, m.Dialect.Rebind("SELECT name, price, currency, category_id FROM products WHERE id = ?"), l.ProductID).
+			Scan(&name, &price.Amount, &price.Currency, &categoryID)
+		if err == sql.ErrNoRows {
+			return Order{}, &LineError{ProductID: l.ProductID, Err: products.ErrNotFound}
+		}
+		if err != nil {
+			return Order{}, err
+		}
+		if rowsAffected == 0 {
+			return Order{}, &LineError{ProductID: l.ProductID, Err: products.ErrInsufficientStock}
+		}
+		o.Items = append(o.Items, newItem(l, name, price))
+		cart = append(cart, discounts.CartLine{ProductID: l.ProductID, CategoryID: categoryID, Quantity: l.Quantity, UnitPrice: price})
+	}
+	if err := o.sum(); err != nil {
+		return Order{}, err
+	}
+	if err := o.applyCoupons(cart, coupons, now); err != nil {
+		return Order{}, err
+	}
+
+	id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO orders (user_id, status, discount, total, currency, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		o.UserID, o.Status, o.Discount.Amount, o.Total.Amount, o.Total.Currency, now, now)
+	if err != nil {
+		return Order{}, err
+	}
+	o.ID = int(id)
+
+	for i := range o.Items {
+		item := &o.Items[i]
+		id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO order_items (order_id, product_id, name, unit_price, quantity, line_total) VALUES (?, ?, ?, ?, ?, ?)",
+			o.ID, item.ProductID, item.Name, item.UnitPrice.Amount, item.Quantity, item.LineTotal.Amount)
+		if err != nil {
+			return Order{}, err
+		}
+		item.ID = int(id)
+	}
+
+	if err := m.insertChange(ctx, tx, StatusChange{OrderID: o.ID, To: o.Status, ActorID: &userID, CreatedAt: now}); err != nil {
+		return Order{}, err
+	}
+
+	if len(coupons) > 0 {
+		if err := discounts.RedeemTx(ctx, tx, m.Dialect, couponIDs(coupons), userID, o.ID); err != nil {
+			return Order{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Order{}, err
+	}
+	return o, nil
+}
+
+const orderColumns = "id, user_id, status, discount, total, currency, created_at, updated_at"
+
+func scanOrder(row interface{ Scan(...interface{}) error }) (Order, error) {
+	var o Order
+	err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.Discount.Amount, &o.Total.Amount, &o.Total.Currency, &o.CreatedAt, &o.UpdatedAt)
+	o.Discount.Currency = o.Total.Currency
+	return o, err
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (Order, error) {
+	o, err := scanOrder(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+orderColumns+" FROM orders WHERE id = ?"), id))
+	if err == sql.ErrNoRows {
+		return Order{}, ErrNotFound
+	}
+	if err != nil {
+		return Order{}, err
+	}
+	orders := []Order{o}
+	if err := m.loadItems(ctx, orders); err != nil {
+		return Order{}, err
+	}
+	return orders[0], nil
+}
+
+// where будує умову WHERE для фільтра без урахування сторінки
+func (m *SQLRepository) where(f Filter) ([]string, []interface{}) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	if f.UserID != 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, f.Status)
+	}
+	if !f.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	return where, args
+}
+
+// listKey — порядок списку замовлень: від новіших до старіших
+var listKey = []pagination.Column{{Expr: "created_at", Desc: true}, {Expr: "id", Desc: true}}
+
+func (m *SQLRepository) List(ctx context.Context, f Filter) ([]Order, error) {
+	where, args := m.where(f)
+	if f.Page.Cursor != nil {
+		createdAt, ok := boundary(*f.Page.Cursor)
+		if !ok {
+			return nil, errors.New("orders: malformed cursor")
+		}
+		keyset, keyArgs := pagination.Keyset(listKey, []interface{}{createdAt.UTC(), f.Page.Cursor.ID}, f.Page.Backward())
+		where = append(where, keyset)
+		args = append(args, keyArgs...)
+	}
+
+	query := "SELECT " + orderColumns + " FROM orders"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + pagination.OrderBy(listKey, f.Page.Backward()) + " LIMIT ?"
+	args = append(args, f.Page.Limit+1)
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := m.loadItems(ctx, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (m *SQLRepository) Count(ctx context.Context, f Filter) (int, error) {
+	where, args := m.where(f)
+	query := "SELECT COUNT(*) FROM orders"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	var count int
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind(query), args...).Scan(&count)
+	return count, err
+}
+
+// loadItems заповнює позиції замовлень одним запитом
+func (m *SQLRepository) loadItems(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	index := make(map[int]*Order, len(orders))
+	placeholders := make([]string, len(orders))
+	args := make([]interface{}, len(orders))
+	for i := range orders {
+		o := &orders[i]
+		o.Items = []Item{}
+		index[o.ID] = o
+		placeholders[i] = "?"
+		args[i] = o.ID
+	}
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT order_id, id, product_id, name, unit_price, quantity, line_total FROM order_items WHERE order_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY order_id, id"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID int
+			item    Item
+		)
+		if err := rows.Scan(&orderID, &item.ID, &item.ProductID, &item.Name, &item.UnitPrice.Amount, &item.Quantity, &item.LineTotal.Amount); err != nil {
+			return err
+		}
+		o := index[orderID]
+		item.UnitPrice.Currency = o.Total.Currency
+		item.LineTotal.Currency = o.Total.Currency
+		o.Items = append(o.Items, item)
+	}
+	return rows.Err()
+}
+
+func (m *SQLRepository) SetStatus(ctx context.Context, change StatusChange, restock bool) (Order, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, err
+	}
+	defer tx.Rollback()
+
+	// Умова status = ? не дає двом одночасним змінам обидві застосуватися
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE orders SET status = ?, updated_at = ? WHERE id = ? AND status = ?"),
+		change.To, now, change.OrderID, change.From)
+	if err != nil {
+		return Order{}, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return Order{}, err
+	}
+	if rowsAffected == 0 {
+		var n int
+		if err := tx.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM orders WHERE id = ?"), change.OrderID).Scan(&n); err != nil {
+			return Order{}, err
+		}
+		if n == 0 {
+			return Order{}, ErrNotFound
+		}
+		return Order{}, ErrStatusChanged
+	}
+
+	if restock {
+		// Видалені продукти пропускаються: повертати залишок нікуди
+		_, err := tx.ExecContext(ctx, m.Dialect.Rebind(`
+			UPDATE products
+			SET stock_quantity = stock_quantity + (SELECT SUM(quantity) FROM order_items WHERE order_items.order_id = ? AND order_items.product_id = products.id),
+				updated_at = ?
+			WHERE id IN (SELECT product_id FROM order_items WHERE order_id = ?)
+		`), change.OrderID, now, change.OrderID)
+		if err != nil {
+			return Order{}, err
+		}
+	}
+
+	change.CreatedAt = now
+	if err := m.insertChange(ctx, tx, change); err != nil {
+		return Order{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Order{}, err
+	}
+	return m.Get(ctx, change.OrderID)
+}
+
+// insertChange додає запис в історію статусів
+func (m *SQLRepository) insertChange(ctx context.Context, tx *sql.Tx, c StatusChange) error {
+	var from sql.NullString
+	if c.From != "" {
+		from = sql.NullString{String: c.From, Valid: true}
+	}
+	_, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, note, created_at) VALUES (?, ?, ?, ?, ?, ?)"),
+		c.OrderID, from, c.To, c.ActorID, c.Note, c.CreatedAt)
+	return err
+}
+
+func (m *SQLRepository) History(ctx context.Context, orderID int) ([]StatusChange, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT id, order_id, from_status, to_status, actor_id, note, created_at FROM order_status_history WHERE order_id = ? ORDER BY id"), orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []StatusChange{}
+	for rows.Next() {
+		var (
+			c       StatusChange
+			from    sql.NullString
+			actorID sql.NullInt64
+		)
+		if err := rows.Scan(&c.ID, &c.OrderID, &from, &c.To, &actorID, &c.Note, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.From = from.String
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			c.ActorID = &id
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
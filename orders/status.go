@@ -0,0 +1,70 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIllegalTransition повертається, якщо автомат статусів не дозволяє перехід
+var ErrIllegalTransition = errors.New("orders: illegal status transition")
+
+// ErrStatusChanged повертається репозиторієм, якщо статус замовлення змінився
+// між читанням і записом
+var ErrStatusChanged = errors.New("orders: status changed concurrently")
+
+// transitions — дозволені переходи між статусами. Скасувати можна лише
+// замовлення, яке ще не відвантажено; після відвантаження можливе лише повернення коштів.
+var transitions = map[string][]string{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusPacked, StatusCancelled, StatusRefunded},
+	StatusPacked:    {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {StatusRefunded},
+	StatusCancelled: nil,
+	StatusRefunded:  nil,
+}
+
+// CanTransition повідомляє, чи дозволено перехід зі статусу from у to
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next повертає статуси, у які можна перевести замовлення зі статусу from
+func Next(from string) []string {
+	return append([]string(nil), transitions[from]...)
+}
+
+// restocks повідомляє, чи повертає перехід товари на склад: скасування, а
+// також повернення коштів за оплачене, але ще не зібране замовлення
+func restocks(from, to string) bool {
+	return to == StatusCancelled || to == StatusRefunded && from == StatusPaid
+}
+
+// TransitionError описує заборонений перехід
+type TransitionError struct {
+	From, To string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("orders: cannot change status from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error { return ErrIllegalTransition }
+
+// StatusChange — запис історії статусів замовлення
+type StatusChange struct {
+	ID      int    `json:"id"`
+	OrderID int    `json:"orderID"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	// ActorID — користувач, що змінив статус; nil для системних змін (наприклад, оплати)
+	ActorID   *int      `json:"actorID,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,87 @@
+package pagination
+
+import (
+	"sort"
+	"strings"
+)
+
+// Column — стовпець ключа сортування для SQL-запиту
+type Column struct {
+	// Expr — SQL-вираз стовпця
+	Expr string
+	// Param — вираз параметра; порожній — "?". Потрібен, коли значення
+	// курсора треба привести до типу стовпця, наприклад CAST(? AS DECIMAL(19, 4)).
+	Param string
+	Desc  bool
+}
+
+func (c Column) param() string {
+	if c.Param == "" {
+		return "?"
+	}
+	return c.Param
+}
+
+// after повідомляє, чи йдуть рядки сторінки у бік зростання стовпця
+func (c Column) after(backward bool) bool {
+	return c.Desc == backward
+}
+
+// Keyset повертає умову, що відбирає рядки після межі values у порядку
+// columns (для backward — перед нею), та її аргументи. Останнім стовпцем
+// має бути унікальний ключ, зазвичай ID.
+func Keyset(columns []Column, values []interface{}, backward bool) (string, []interface{}) {
+	var (
+		or   []string
+		args []interface{}
+	)
+	for i, c := range columns {
+		and := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, columns[j].Expr+" = "+columns[j].param())
+			args = append(args, values[j])
+		}
+		op := " < "
+		if c.after(backward) {
+			op = " > "
+		}
+		and = append(and, c.Expr+op+c.param())
+		args = append(args, values[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+// OrderBy повертає перелік ORDER BY для columns; backward обертає напрям
+func OrderBy(columns []Column, backward bool) string {
+	order := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c.after(backward) {
+			order = append(order, c.Expr)
+		} else {
+			order = append(order, c.Expr+" DESC")
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+// SelectIDs відбирає з ids до Limit+1 ідентифікаторів після межі курсора в
+// напрямку запиту. Для сховищ у пам'яті, впорядкованих лише за ID.
+func SelectIDs(ids []int, q Query) []int {
+	sort.Ints(ids)
+	if q.Backward() {
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	}
+
+	selected := make([]int, 0, q.Limit+1)
+	for _, id := range ids {
+		if len(selected) > q.Limit {
+			break
+		}
+		if q.Cursor != nil && ((q.Backward() && id >= q.Cursor.ID) || (!q.Backward() && id <= q.Cursor.ID)) {
+			continue
+		}
+		selected = append(selected, id)
+	}
+	return selected
+}
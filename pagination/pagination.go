@@ -0,0 +1,181 @@
+// Package pagination реалізує курсорну (keyset) пагінацію списків: непрозорі
+// курсори, обмежений розмір сторінки, необов'язкову загальну кількість,
+// заголовок Link (RFC 8288) та спільний конверт відповіді {data, page}.
+//
+// Репозиторій вибирає Limit+1 рядків після межі курсора (для зворотного курсора —
+// перед нею, у зворотному порядку), а Build обрізає зайвий рядок і будує курсори
+// сусідніх сторінок. Вставка рядків під час гортання не зсуває сторінки, як це
+// буває з OFFSET.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// DefaultLimit — розмір сторінки, якщо limit не задано
+const DefaultLimit = 10
+
+// MaxLimit — найбільший розмір сторінки; більші значення обрізаються
+const MaxLimit = 100
+
+// Cursor — межа сторінки: ключ сортування рядка, після (або перед) яким
+// починається сторінка. Клієнтам передається закодованим і непрозорим.
+type Cursor struct {
+	// ID — ідентифікатор межового рядка, останнє поле ключа сортування
+	ID int `json:"id"`
+	// Values — значення інших полів сортування межового рядка
+	Values []string `json:"v,omitempty"`
+	// Sort — порядок сортування, для якого створено курсор
+	Sort string `json:"s,omitempty"`
+	// Backward — сторінка перед межею (курсор prev)
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode кодує курсор для передачі клієнту
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode розбирає курсор, отриманий від клієнта
+func Decode(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Query — запит сторінки до репозиторію
+type Query struct {
+	Limit int
+	// Cursor — межа сторінки; nil — перша сторінка
+	Cursor *Cursor
+	// Total — клієнт просить загальну кількість рядків
+	Total bool
+	// Sort — порядок сортування, що записується в курсори
+	Sort string
+}
+
+// Backward повідомляє, що сторінку треба вибирати перед межею курсора
+func (q Query) Backward() bool {
+	return q.Cursor != nil && q.Cursor.Backward
+}
+
+// Parse розбирає параметри limit, cursor та total; помилки додаються у v.
+// sort — поточний порядок сортування: курсор, створений для іншого порядку,
+// відхиляється.
+func Parse(query url.Values, v *validate.Validator, sort string) Query {
+	q := Query{Limit: DefaultLimit, Sort: sort}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		v.Check(err == nil && limit > 0, "limit", "must be a positive integer")
+		if limit > MaxLimit {
+			limit = MaxLimit
+		}
+		if limit > 0 {
+			q.Limit = limit
+		}
+	}
+	if value := query.Get("cursor"); value != "" {
+		c, err := Decode(value)
+		v.Check(err == nil, "cursor", "is malformed")
+		if err == nil {
+			v.Check(c.Sort == sort, "cursor", "was issued for a different sort order")
+			q.Cursor = c
+		}
+	}
+	if value := query.Get("total"); value != "" {
+		total, err := strconv.ParseBool(value)
+		v.Check(err == nil, "total", "must be a boolean")
+		q.Total = total
+	}
+	return q
+}
+
+// Page — метадані сторінки у відповіді
+type Page struct {
+	Limit int `json:"limit"`
+	// Next та Prev — курсори сусідніх сторінок; порожні, якщо сторінки немає
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+	// Total — кількість усіх рядків; лише якщо запитано total=true
+	Total *int `json:"total,omitempty"`
+}
+
+// List — спільний конверт відповіді для списків
+type List[T any] struct {
+	Data []T  `json:"data"`
+	Page Page `json:"page"`
+}
+
+// Build обрізає вибірку репозиторію до сторінки в природному порядку та
+// будує курсори сусідніх сторінок. rows — до Limit+1 рядків у напрямку запиту;
+// key повертає ID та значення полів сортування рядка.
+func Build[T any](rows []T, q Query, key func(T) Cursor) ([]T, Page) {
+	more := len(rows) > q.Limit
+	if more {
+		rows = rows[:q.Limit]
+	}
+	if q.Backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+
+	p := Page{Limit: q.Limit}
+	cursor := func(row T, backward bool) string {
+		c := key(row)
+		c.Sort, c.Backward = q.Sort, backward
+		return c.Encode()
+	}
+	if len(rows) > 0 {
+		// Попередня сторінка є, якщо ми прийшли з курсором вперед або знайшли
+		// зайвий рядок, гортаючи назад; наступна — навпаки
+		if (q.Cursor != nil && !q.Backward()) || (q.Backward() && more) {
+			p.Prev = cursor(rows[0], true)
+		}
+		if (!q.Backward() && more) || q.Backward() {
+			p.Next = cursor(rows[len(rows)-1], false)
+		}
+	}
+	return rows, p
+}
+
+// SetLink записує заголовок Link (RFC 8288) з посиланнями first, prev та next;
+// інші параметри запиту зберігаються
+func SetLink(w http.ResponseWriter, r *http.Request, p Page) {
+	link := func(cursor, rel string) string {
+		u := *r.URL
+		query := u.Query()
+		query.Del("cursor")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		u.RawQuery = query.Encode()
+		return "<" + u.RequestURI() + `>; rel="` + rel + `"`
+	}
+
+	links := []string{link("", "first")}
+	if p.Prev != "" {
+		links = append(links, link(p.Prev, "prev"))
+	}
+	if p.Next != "" {
+		links = append(links, link(p.Next, "next"))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
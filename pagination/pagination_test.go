@@ -0,0 +1,46 @@
+package pagination_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+func TestParse(t *testing.T) {
+	v := validate.New()
+	q := pagination.Parse(url.Values{"limit": {"1000"}, "total": {"true"}}, v, "")
+	assert.NoError(t, v.QueryErr())
+	assert.Equal(t, pagination.MaxLimit, q.Limit)
+	assert.True(t, q.Total)
+
+	cursor := pagination.Cursor{ID: 3, Sort: "-price"}.Encode()
+	v = validate.New()
+	pagination.Parse(url.Values{"limit": {"0"}, "cursor": {cursor}, "total": {"maybe"}}, v, "price")
+	p, ok := v.QueryErr().(*problem.Problem)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	var fields []string
+	for _, e := range p.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"limit", "cursor", "total"}, fields)
+}
+
+func TestKeyset(t *testing.T) {
+	columns := []pagination.Column{{Expr: "price", Param: "CAST(? AS DECIMAL(19, 4))", Desc: true}, {Expr: "id"}}
+
+	where, args := pagination.Keyset(columns, []interface{}{"10", 7}, false)
+	assert.Equal(t, "((price < CAST(? AS DECIMAL(19, 4))) OR (price = CAST(? AS DECIMAL(19, 4)) AND id > ?))", where)
+	assert.Equal(t, []interface{}{"10", "10", 7}, args)
+	assert.Equal(t, "price DESC, id", pagination.OrderBy(columns, false))
+
+	where, _ = pagination.Keyset(columns, []interface{}{"10", 7}, true)
+	assert.Equal(t, "((price > CAST(? AS DECIMAL(19, 4))) OR (price = CAST(? AS DECIMAL(19, 4)) AND id < ?))", where)
+	assert.Equal(t, "price, id DESC", pagination.OrderBy(columns, true))
+}
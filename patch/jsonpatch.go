@@ -0,0 +1,272 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+// operation — одна операція JSON Patch. Value лишається сирим JSON, щоб
+// відрізнити відсутнє значення від null.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply застосовує до документа target послідовність операцій JSON Patch за
+// RFC 6902. Операції виконуються атомарно: за першої помилки документ не
+// змінюється. Некоректні операції дають 400, відсутні шляхи 422, а невдала
+// операція test 409.
+func Apply(target, patch []byte) ([]byte, error) {
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, problem.New(http.StatusBadRequest, "JSON Patch document must be an array of operations")
+	}
+	doc, err := parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if op.Path == nil {
+			return nil, problem.Newf(http.StatusBadRequest, "operation %d: path is required", i)
+		}
+		path, err := pointer(*op.Path)
+		if err != nil {
+			return nil, problem.Newf(http.StatusBadRequest, "operation %d: %v", i, err)
+		}
+		if doc, err = op.apply(doc, path); err != nil {
+			if p, ok := err.(*problem.Problem); ok {
+				return nil, p
+			}
+			return nil, problem.Newf(http.StatusUnprocessableEntity, "operation %d (%s %s): %v", i, op.Op, *op.Path, err)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func (op operation) apply(doc interface{}, path []string) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, problem.Newf(http.StatusBadRequest, "%s operation requires a value", op.Op)
+		}
+		value, err := parse(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, problem.Newf(http.StatusConflict, "test failed: value at %q does not match", *op.Path)
+		}
+		return doc, nil
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, problem.Newf(http.StatusBadRequest, "%s operation requires from", op.Op)
+		}
+		from, err := pointer(*op.From)
+		if err != nil {
+			return nil, problem.Newf(http.StatusBadRequest, "from: %v", err)
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if strings.HasPrefix(*op.Path, *op.From+"/") {
+				return nil, problem.New(http.StatusBadRequest, "cannot move a value into one of its children")
+			}
+			if doc, value, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(doc, from); err != nil {
+				return nil, err
+			}
+			// Копія не повинна ділити вкладені об'єкти з оригіналом
+			data, _ := json.Marshal(value)
+			value, _ = parse(data)
+		}
+		return add(doc, path, value)
+	default:
+		return nil, problem.Newf(http.StatusBadRequest, "unsupported operation %q", op.Op)
+	}
+}
+
+// pointer розбирає JSON Pointer (RFC 6901) на токени
+func pointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// update знаходить батьківський вузол останнього токена path, викликає для
+// нього fn та повертає оновлений документ. Зрізи можуть перевиділятися, тому
+// кожен рівень записує змінений дочірній вузол назад.
+func update(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		i, _ := strconv.Atoi(path[0])
+		node[i] = child
+	}
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := index(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot add member %q to a scalar", token)
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, problem.New(http.StatusBadRequest, "cannot remove the whole document")
+	}
+	var removed interface{}
+	doc, err := update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[i]
+			return append(node[:i], node[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove member %q from a scalar", token)
+		}
+	})
+	return doc, removed, err
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i, _ := strconv.Atoi(token)
+			node[i] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot replace member %q of a scalar", token)
+		}
+	})
+}
+
+// index розбирає індекс масиву, не більший за max. Провідні нулі заборонені RFC 6901.
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') || token[0] == '+' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// equal порівнює JSON-значення; числа порівнюються за значенням, а не за записом
+func equal(a, b interface{}) bool {
+	var x, y interface{}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	if json.Unmarshal(ja, &x) != nil || json.Unmarshal(jb, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
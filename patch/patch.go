@@ -0,0 +1,119 @@
+// Package patch застосовує часткові зміни запитів PATCH до JSON-представлення
+// ресурсу: JSON Merge Patch (RFC 7396) та JSON Patch (RFC 6902).
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Типи вмісту запитів PATCH
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Accept — значення заголовка Accept-Patch з підтримуваними форматами
+const Accept = MergePatchType + ", " + JSONPatchType
+
+// Decode застосовує тіло запиту PATCH до JSON-представлення current і декодує
+// результат у dst за правилами validate.DecodeJSON, тож невідомі поля та поля
+// неправильного типу дають 422. Тип application/json чи відсутній Content-Type
+// вважаються merge patch, інші типи, крім JSONPatchType, дають 415.
+// Повертає *problem.Problem.
+func Decode(r *http.Request, current, dst interface{}) error {
+	apply := Merge
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		switch {
+		case err != nil:
+			return problem.Newf(http.StatusUnsupportedMediaType, "invalid Content-Type %q", contentType)
+		case mediaType == JSONPatchType:
+			apply = Apply
+		case mediaType != MergePatchType && mediaType != "application/json":
+			return problem.Newf(http.StatusUnsupportedMediaType, "Content-Type must be one of %s", Accept)
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return problem.Newf(http.StatusRequestEntityTooLarge, "request body must not exceed %d bytes", maxBytesErr.Limit)
+		}
+		return err
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	patched, err := apply(doc, body)
+	if err != nil {
+		return err
+	}
+	return validate.Unmarshal(patched, dst)
+}
+
+// Error записує помилку Decode; відповідь 415 містить заголовок Accept-Patch
+// з підтримуваними форматами (RFC 5789)
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	if p, ok := err.(*problem.Problem); ok && p.Status == http.StatusUnsupportedMediaType {
+		w.Header().Set("Accept-Patch", Accept)
+	}
+	problem.Error(w, r, err)
+}
+
+// Merge застосовує merge patch до документа target за RFC 7396: null видаляє
+// член об'єкта, об'єкти зливаються рекурсивно, решта значень замінюється.
+func Merge(target, patch []byte) ([]byte, error) {
+	p, err := parse(patch)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parse(target)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(t, p))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = merge(t[name], value)
+		}
+	}
+	return t
+}
+
+// parse декодує JSON-документ, зберігаючи числа текстом, щоб не втратити точність сум
+func parse(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, problem.Newf(http.StatusBadRequest, "malformed patch document: %v", err)
+	}
+	if decoder.More() {
+		return nil, problem.New(http.StatusBadRequest, "patch document must contain a single JSON value")
+	}
+	return v, nil
+}
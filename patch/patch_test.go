@@ -0,0 +1,75 @@
+package patch_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/patch"
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+func TestMerge(t *testing.T) {
+	// Приклади з додатка A RFC 7396
+	for _, c := range []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Числа не проходять через float64
+		{`{"price":"1"}`, `{"price":12345678901234567890.12}`, `{"price":12345678901234567890.12}`},
+	} {
+		got, err := patch.Merge([]byte(c.target), []byte(c.patch))
+		assert.NoError(t, err, c.patch)
+		assert.JSONEq(t, c.want, string(got), c.patch)
+	}
+
+	_, err := patch.Merge([]byte(`{}`), []byte(`{"a":`))
+	assert.Equal(t, http.StatusBadRequest, err.(*problem.Problem).Status)
+}
+
+func TestApply(t *testing.T) {
+	target := `{"foo":"bar","list":[1,2,3],"nested":{"a~b":1,"c/d":2}}`
+	for _, c := range []struct{ patch, want string }{
+		{`[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux","list":[1,2,3],"nested":{"a~b":1,"c/d":2}}`},
+		{`[{"op":"add","path":"/list/1","value":9},{"op":"add","path":"/list/-","value":4}]`, `{"foo":"bar","list":[1,9,2,3,4],"nested":{"a~b":1,"c/d":2}}`},
+		{`[{"op":"remove","path":"/list/0"},{"op":"remove","path":"/nested/a~0b"}]`, `{"foo":"bar","list":[2,3],"nested":{"c/d":2}}`},
+		{`[{"op":"replace","path":"/nested/c~1d","value":null}]`, `{"foo":"bar","list":[1,2,3],"nested":{"a~b":1,"c/d":null}}`},
+		{`[{"op":"move","path":"/moved","from":"/foo"}]`, `{"moved":"bar","list":[1,2,3],"nested":{"a~b":1,"c/d":2}}`},
+		{`[{"op":"copy","path":"/list/0","from":"/nested"},{"op":"replace","path":"/list/0/a~0b","value":5}]`, `{"foo":"bar","list":[{"a~b":5,"c/d":2},1,2,3],"nested":{"a~b":1,"c/d":2}}`},
+		{`[{"op":"test","path":"/list","value":[1,2,3.0]},{"op":"replace","path":"","value":{}}]`, `{}`},
+	} {
+		got, err := patch.Apply([]byte(target), []byte(c.patch))
+		assert.NoError(t, err, c.patch)
+		assert.JSONEq(t, c.want, string(got), c.patch)
+	}
+
+	for p, status := range map[string]int{
+		`{"op":"add"}`:                                        http.StatusBadRequest,
+		`[{"op":"increment","path":"/foo"}]`:                  http.StatusBadRequest,
+		`[{"op":"add","path":"/foo"}]`:                        http.StatusBadRequest,
+		`[{"op":"remove","path":"foo"}]`:                      http.StatusBadRequest,
+		`[{"op":"move","path":"/nested/x","from":"/nested"}]`: http.StatusBadRequest,
+		`[{"op":"remove","path":"/missing"}]`:                 http.StatusUnprocessableEntity,
+		`[{"op":"replace","path":"/list/3","value":0}]`:       http.StatusUnprocessableEntity,
+		`[{"op":"add","path":"/list/01","value":0}]`:          http.StatusUnprocessableEntity,
+		`[{"op":"add","path":"/foo/bar","value":0}]`:          http.StatusUnprocessableEntity,
+		`[{"op":"test","path":"/foo","value":"baz"}]`:         http.StatusConflict,
+	} {
+		_, err := patch.Apply([]byte(target), []byte(p))
+		if assert.IsType(t, &problem.Problem{}, err, p) {
+			assert.Equal(t, status, err.(*problem.Problem).Status, p)
+		}
+	}
+}
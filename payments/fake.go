@@ -0,0 +1,138 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// Токени способів оплати, які розуміє FakeProvider
+const (
+	// FakeSourceOK завжди проходить авторизацію
+	FakeSourceOK = "tok_ok"
+	// FakeSourceDeclined завжди відхиляється
+	FakeSourceDeclined = "tok_declined"
+)
+
+// FakeProvider — детермінований провайдер для тестів і локальної розробки.
+// Посилання видаються послідовно (fake_1, fake_2, ...), тож однакова
+// послідовність викликів дає однакові результати. Платежі зберігаються у пам'яті.
+type FakeProvider struct {
+	mu       sync.Mutex
+	next     int
+	payments map[string]*fakePayment
+	byKey    map[string]string
+}
+
+type fakePayment struct {
+	status     string
+	authorized money.Money
+	captured   money.Money
+	refunded   money.Money
+}
+
+// NewFakeProvider створює порожній фейковий провайдер
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{next: 1, payments: make(map[string]*fakePayment), byKey: make(map[string]string)}
+}
+
+func (f *FakeProvider) Name() string { return "fake" }
+
+// Authorize відхиляє FakeSourceDeclined та порожній токен; будь-який інший
+// токен проходить авторизацію
+func (f *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if ref, ok := f.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return f.result(ref)
+	}
+
+	ref := fmt.Sprintf("fake_%d", f.next)
+	f.next++
+	p := &fakePayment{status: StatusAuthorized, authorized: req.Amount, captured: money.Zero(req.Amount.Currency), refunded: money.Zero(req.Amount.Currency)}
+	if req.Source == "" || req.Source == FakeSourceDeclined {
+		p.status = StatusFailed
+	}
+	f.payments[ref] = p
+	if req.IdempotencyKey != "" {
+		f.byKey[req.IdempotencyKey] = ref
+	}
+	return f.result(ref)
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	return f.update(ctx, reference, func(p *fakePayment) error {
+		if p.status != StatusAuthorized {
+			return ErrInvalidState
+		}
+		if cmp, err := amount.Cmp(p.authorized); err != nil || cmp > 0 || !amount.Amount.IsPositive() {
+			return ErrInvalidState
+		}
+		p.captured = amount
+		p.status = StatusCaptured
+		return nil
+	})
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	return f.update(ctx, reference, func(p *fakePayment) error {
+		if p.status != StatusCaptured && p.status != StatusRefunded {
+			return ErrInvalidState
+		}
+		refunded, err := p.refunded.Add(amount)
+		if err != nil || !amount.Amount.IsPositive() {
+			return ErrInvalidState
+		}
+		if cmp, _ := refunded.Cmp(p.captured); cmp > 0 {
+			return ErrInvalidState
+		}
+		p.refunded = refunded
+		p.status = StatusRefunded
+		return nil
+	})
+}
+
+func (f *FakeProvider) Void(ctx context.Context, reference string) (Result, error) {
+	return f.update(ctx, reference, func(p *fakePayment) error {
+		if p.status != StatusAuthorized {
+			return ErrInvalidState
+		}
+		p.status = StatusVoided
+		return nil
+	})
+}
+
+// update застосовує операцію до платежу під блокуванням
+func (f *FakeProvider) update(ctx context.Context, reference string, apply func(p *fakePayment) error) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[reference]
+	if !ok {
+		return Result{}, ErrUnknownPayment
+	}
+	if err := apply(p); err != nil {
+		return Result{}, err
+	}
+	return f.result(reference)
+}
+
+// result повертає поточний стан платежу; викликається під f.mu
+func (f *FakeProvider) result(reference string) (Result, error) {
+	res := Result{Reference: reference, Status: f.payments[reference].status}
+	if res.Status == StatusFailed {
+		return res, ErrDeclined
+	}
+	return res, nil
+}
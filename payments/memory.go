@@ -0,0 +1,86 @@
+package payments
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRepository зберігає платежі у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки.
+type MemoryRepository struct {
+	mu       sync.Mutex
+	payments []Payment
+	events   map[string]bool
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{events: make(map[string]bool)}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, p *Payment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	p.ID = len(m.payments) + 1
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	m.payments = append(m.payments, *p)
+	return nil
+}
+
+func (m *MemoryRepository) ListByOrder(ctx context.Context, orderID int) ([]Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	payments := []Payment{}
+	for _, p := range m.payments {
+		if p.OrderID == orderID {
+			payments = append(payments, p)
+		}
+	}
+	return payments, nil
+}
+
+func (m *MemoryRepository) ApplyEvent(ctx context.Context, provider string, event Event) (Payment, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Payment{}, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := -1
+	for j, p := range m.payments {
+		if p.Provider == provider && p.Reference == event.Reference {
+			i = j
+		}
+	}
+	if i < 0 {
+		return Payment{}, false, ErrNotFound
+	}
+
+	key := provider + "\x00" + event.ID
+	if m.events[key] {
+		return m.payments[i], false, nil
+	}
+	m.events[key] = true
+
+	p := &m.payments[i]
+	if !supersedes(p.Status, event.Status) {
+		return *p, false, nil
+	}
+	p.Status = event.Status
+	p.UpdatedAt = time.Now().UTC()
+	return *p, true, nil
+}
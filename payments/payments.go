@@ -0,0 +1,250 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/orders"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// PaymentService приймає оплати замовлень та обробляє вебхуки провайдера
+type PaymentService struct {
+	Repo     PaymentRepository
+	Provider Provider
+	Orders   *orders.OrderService
+	// WebhookSecret — спільний з провайдером секрет підпису вебхуків;
+	// порожній секрет відхиляє всі вебхуки
+	WebhookSecret string
+}
+
+// PayInput — тіло запиту на оплату замовлення
+type PayInput struct {
+	// Source — токен способу оплати, отриманий клієнтом від провайдера
+	Source string `json:"source"`
+}
+
+var errInvalidOrderID = problem.New(http.StatusBadRequest, "invalid order ID")
+
+// Pay оплачує неоплачене замовлення поточного користувача: блокує та одразу
+// списує повну суму і переводить замовлення у статус paid. Заголовок
+// Idempotency-Key передається провайдеру, щоб повтор запиту не списав кошти двічі.
+// POST /orders/{id}/payments
+func (s *PaymentService) Pay(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, errInvalidOrderID)
+		return
+	}
+
+	var input PayInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	v.Required("source", input.Source)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Оплатити можна лише власне замовлення
+	order, err := s.Orders.Repo.Get(r.Context(), orderID)
+	if err == orders.ErrNotFound || err == nil && order.UserID != principal.UserID {
+		problem.Write(w, r, problem.Newf(http.StatusNotFound, "order %d not found", orderID))
+		return
+	}
+	if err != nil {
+		log.Println("Error querying order:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	if order.Status != orders.StatusPending {
+		problem.Write(w, r, problem.Newf(http.StatusConflict, "order %d is %s; only pending orders can be paid", orderID, order.Status))
+		return
+	}
+
+	payment := Payment{OrderID: orderID, Provider: s.Provider.Name(), Amount: order.Total}
+	res, err := s.Provider.Authorize(r.Context(), AuthorizeRequest{
+		OrderID:        orderID,
+		Amount:         order.Total,
+		Source:         input.Source,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	if err == ErrDeclined {
+		payment.Reference, payment.Status = res.Reference, StatusFailed
+		s.record(r, &payment)
+		problem.Write(w, r, problem.New(http.StatusPaymentRequired, "payment declined"))
+		return
+	}
+	if err != nil {
+		log.Println("Error authorizing payment:", err)
+		problem.Write(w, r, problem.New(http.StatusBadGateway, "payment provider is unavailable"))
+		return
+	}
+	payment.Reference = res.Reference
+
+	if _, err := s.Provider.Capture(r.Context(), res.Reference, order.Total); err != nil {
+		log.Println("Error capturing payment:", err)
+		if _, err := s.Provider.Void(r.Context(), res.Reference); err != nil {
+			log.Println("Error voiding payment:", err)
+		}
+		payment.Status = StatusVoided
+		s.record(r, &payment)
+		problem.Write(w, r, problem.New(http.StatusBadGateway, "payment provider is unavailable"))
+		return
+	}
+	payment.Status = StatusCaptured
+
+	// Замовлення могли скасувати, поки провайдер обробляв платіж: тоді кошти повертаються
+	_, err = s.Orders.Transition(r.Context(), orderID, orders.StatusPaid, &principal.UserID, "payment "+res.Reference)
+	if err != nil {
+		if _, refundErr := s.Provider.Refund(r.Context(), res.Reference, order.Total); refundErr != nil {
+			log.Println("Error refunding payment:", refundErr)
+		} else {
+			payment.Status = StatusRefunded
+		}
+		s.record(r, &payment)
+		var transitionErr *orders.TransitionError
+		if errors.As(err, &transitionErr) || err == orders.ErrStatusChanged {
+			problem.Write(w, r, problem.Newf(http.StatusConflict, "order %d is no longer pending; the payment was refunded", orderID))
+		} else {
+			log.Println("Error marking order paid:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	if !s.record(r, &payment) {
+		problem.Error(w, r, errors.New("payments: payment was taken but not recorded"))
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, payment)
+}
+
+// record зберігає платіж; помилка лише журналюється, бо гроші вже рухалися
+// і відповідь клієнту має відображати результат у провайдера
+func (s *PaymentService) record(r *http.Request, p *Payment) bool {
+	if err := s.Repo.Create(r.Context(), p); err != nil {
+		log.Printf("Error recording payment %s for order %d: %v\n", p.Reference, p.OrderID, err)
+		return false
+	}
+	return true
+}
+
+// GetPayments повертає платежі замовлення власнику або користувачу з правом orders:read
+// GET /orders/{id}/payments
+func (s *PaymentService) GetPayments(w http.ResponseWriter, r *http.Request) {
+	principal, _ := rbac.FromContext(r.Context())
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, r, errInvalidOrderID)
+		return
+	}
+
+	order, err := s.Orders.Repo.Get(r.Context(), orderID)
+	if err == nil && order.UserID != principal.UserID && !principal.Can(rbac.PermOrdersRead) {
+		err = orders.ErrNotFound
+	}
+	if err == orders.ErrNotFound {
+		problem.Write(w, r, problem.Newf(http.StatusNotFound, "order %d not found", orderID))
+		return
+	}
+	if err != nil {
+		log.Println("Error querying order:", err)
+		problem.Error(w, r, err)
+		return
+	}
+
+	payments, err := s.Repo.ListByOrder(r.Context(), orderID)
+	if err != nil {
+		log.Println("Error querying payments:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, payments)
+}
+
+// Webhook приймає підписане повідомлення провайдера про зміну статусу платежу.
+// Повторна доставка тієї самої події нічого не змінює. Списання переводить
+// замовлення у paid, повернення коштів — у refunded; такі зміни записуються
+// в історію без автора.
+// POST /payments/webhook
+func (s *PaymentService) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			problem.Write(w, r, problem.Newf(http.StatusRequestEntityTooLarge, "request body must not exceed %d bytes", maxBytesErr.Limit))
+		} else {
+			problem.Write(w, r, problem.New(http.StatusBadRequest, "cannot read request body"))
+		}
+		return
+	}
+	if err := verify(s.WebhookSecret, r.Header.Get(SignatureHeader), body, time.Now()); err != nil {
+		problem.Write(w, r, problem.New(http.StatusUnauthorized, "invalid webhook signature"))
+		return
+	}
+
+	// Невідомі поля дозволені: провайдер може додавати їх без попередження
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		problem.Write(w, r, problem.New(http.StatusBadRequest, "request body must be a JSON object"))
+		return
+	}
+	v := validate.New()
+	v.Required("id", event.ID)
+	v.Required("reference", event.Reference)
+	v.Check(rank[event.Status] > 0, "status", "unknown payment status")
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	payment, changed, err := s.Repo.ApplyEvent(r.Context(), s.Provider.Name(), event)
+	if err == ErrNotFound {
+		// Провайдер повторить доставку, якщо платіж ще не встигли зберегти
+		problem.Write(w, r, problem.Newf(http.StatusNotFound, "payment %s not found", event.Reference))
+		return
+	}
+	if err != nil {
+		log.Println("Error applying payment event:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	if changed {
+		s.syncOrder(r, payment)
+	}
+	render.JSON(w, r, http.StatusOK, payment)
+}
+
+// syncOrder переводить замовлення у статус, що відповідає статусу платежу.
+// Заборонені переходи лише журналюються: замовлення вже могло змінитися іншим шляхом.
+func (s *PaymentService) syncOrder(r *http.Request, p Payment) {
+	var to string
+	switch p.Status {
+	case StatusCaptured:
+		to = orders.StatusPaid
+	case StatusRefunded:
+		to = orders.StatusRefunded
+	default:
+		return
+	}
+	note := fmt.Sprintf("payment %s %s", p.Reference, p.Status)
+	if _, err := s.Orders.Transition(r.Context(), p.OrderID, to, nil, note); err != nil {
+		log.Printf("Order %d not moved to %s after %s: %v\n", p.OrderID, to, note, err)
+	}
+}
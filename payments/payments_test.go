@@ -0,0 +1,228 @@
+package payments_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/orders"
+	"github.com/chitawebui131/shop_go/payments"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/rbac"
+)
+
+const secret = "whsec_test"
+
+type fixture struct {
+	products products.ProductRepository
+	svc      *payments.PaymentService
+}
+
+// fixtures повертає сервіси на репозиторіях у пам'яті та на SQLite
+// з одним продуктом та неоплаченим замовленням 1 користувача 7
+func fixtures(t *testing.T) map[string]fixture {
+	ctx := context.Background()
+	build := func(p products.ProductRepository, o orders.OrderRepository, repo payments.PaymentRepository) fixture {
+		assert.NoError(t, p.Create(ctx, &products.Product{Name: "Go", Price: money.MustParse("10.50", "UAH"), StockQuantity: 5, CategoryID: 1}))
+		_, err := o.Checkout(ctx, 7, []orders.Line{{ProductID: 1, Quantity: 2}}, nil)
+		assert.NoError(t, err)
+		return fixture{p, &payments.PaymentService{
+			Repo:          repo,
+			Provider:      payments.NewFakeProvider(),
+			Orders:        &orders.OrderService{Repo: o},
+			WebhookSecret: secret,
+		}}
+	}
+
+	cats := categories.NewMemoryRepository()
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+	memProducts := products.NewMemoryRepository(cats)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlProducts := products.NewSQLRepository(db, d)
+
+	return map[string]fixture{
+		"memory": build(memProducts, orders.NewMemoryRepository(memProducts, nil), payments.NewMemoryRepository()),
+		"sqlite": build(sqlProducts, orders.NewSQLRepository(db, d), payments.NewSQLRepository(db, d)),
+	}
+}
+
+// newRouter імітує auth та rbac middleware: користувач береться із заголовка X-User
+func newRouter(svc *payments.PaymentService) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.Atoi(r.Header.Get("X-User"))
+			p := rbac.Principal{UserID: id, Permissions: map[string]bool{}}
+			next.ServeHTTP(w, r.WithContext(rbac.WithPrincipal(r.Context(), p)))
+		})
+	})
+	r.Post("/orders/{id}/payments", svc.Pay)
+	r.Get("/orders/{id}/payments", svc.GetPayments)
+	r.Post("/payments/webhook", svc.Webhook)
+	return r
+}
+
+func do(r http.Handler, method, path, userID, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", userID)
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func webhook(r http.Handler, body string, signedAt time.Time) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/payments/webhook", bytes.NewReader([]byte(body)))
+	req.Header.Set(payments.SignatureHeader, payments.Sign(secret, signedAt, []byte(body)))
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func orderStatus(t *testing.T, svc *payments.PaymentService) string {
+	o, err := svc.Orders.Repo.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	return o.Status
+}
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	p := payments.NewFakeProvider()
+	amount := money.MustParse("10.00", "UAH")
+
+	res, err := p.Authorize(ctx, payments.AuthorizeRequest{Amount: amount, Source: payments.FakeSourceDeclined})
+	assert.Equal(t, payments.ErrDeclined, err)
+	assert.Equal(t, payments.Result{Reference: "fake_1", Status: payments.StatusFailed}, res)
+
+	res, err = p.Authorize(ctx, payments.AuthorizeRequest{Amount: amount, Source: payments.FakeSourceOK, IdempotencyKey: "k"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake_2", res.Reference)
+	again, err := p.Authorize(ctx, payments.AuthorizeRequest{Amount: amount, Source: payments.FakeSourceOK, IdempotencyKey: "k"})
+	assert.NoError(t, err)
+	assert.Equal(t, res, again)
+
+	_, err = p.Capture(ctx, "fake_2", money.MustParse("10.01", "UAH"))
+	assert.Equal(t, payments.ErrInvalidState, err)
+	res, err = p.Capture(ctx, "fake_2", amount)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, res.Status)
+	_, err = p.Void(ctx, "fake_2")
+	assert.Equal(t, payments.ErrInvalidState, err)
+
+	_, err = p.Refund(ctx, "fake_2", money.MustParse("4.00", "UAH"))
+	assert.NoError(t, err)
+	_, err = p.Refund(ctx, "fake_2", money.MustParse("6.01", "UAH"))
+	assert.Equal(t, payments.ErrInvalidState, err)
+	res, err = p.Refund(ctx, "fake_2", money.MustParse("6.00", "UAH"))
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusRefunded, res.Status)
+
+	_, err = p.Capture(ctx, "fake_9", amount)
+	assert.Equal(t, payments.ErrUnknownPayment, err)
+}
+
+func TestPay(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(f.svc)
+
+			// Чуже замовлення не розкривається, відхилений платіж записується
+			assert.Equal(t, http.StatusNotFound, do(r, "POST", "/orders/1/payments", "8", `{"source":"tok_ok"}`).Code)
+			assert.Equal(t, http.StatusUnprocessableEntity, do(r, "POST", "/orders/1/payments", "7", `{}`).Code)
+			assert.Equal(t, http.StatusPaymentRequired, do(r, "POST", "/orders/1/payments", "7", `{"source":"tok_declined"}`).Code)
+			assert.Equal(t, orders.StatusPending, orderStatus(t, f.svc))
+
+			rr := do(r, "POST", "/orders/1/payments", "7", `{"source":"tok_ok"}`)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var payment payments.Payment
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &payment))
+			assert.Equal(t, payments.StatusCaptured, payment.Status)
+			assert.Equal(t, "21.00 UAH", payment.Amount.String())
+			assert.Equal(t, orders.StatusPaid, orderStatus(t, f.svc))
+
+			// Оплачене замовлення не можна оплатити вдруге
+			assert.Equal(t, http.StatusConflict, do(r, "POST", "/orders/1/payments", "7", `{"source":"tok_ok"}`).Code)
+
+			rr = do(r, "GET", "/orders/1/payments", "7", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var list []payments.Payment
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			assert.Len(t, list, 2)
+			assert.Equal(t, payments.StatusFailed, list[0].Status)
+			assert.Equal(t, payments.StatusCaptured, list[1].Status)
+			assert.Equal(t, http.StatusNotFound, do(r, "GET", "/orders/1/payments", "8", "").Code)
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	for name, f := range fixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newRouter(f.svc)
+			assert.Equal(t, http.StatusCreated, do(r, "POST", "/orders/1/payments", "7", `{"source":"tok_ok"}`).Code)
+			ref := "fake_1"
+			refunded := `{"id":"evt_1","reference":"` + ref + `","status":"refunded","livemode":false}`
+
+			// Непідписаний, підроблений або застарілий запит відхиляється
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest("POST", "/payments/webhook", strings.NewReader(refunded)))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			rr = httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/payments/webhook", strings.NewReader(refunded))
+			req.Header.Set(payments.SignatureHeader, payments.Sign("other", time.Now(), []byte(refunded)))
+			r.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.Equal(t, http.StatusUnauthorized, webhook(r, refunded, time.Now().Add(-time.Hour)).Code)
+			assert.Equal(t, orders.StatusPaid, orderStatus(t, f.svc))
+
+			// Повернення коштів переводить замовлення у refunded без автора
+			rr = webhook(r, refunded, time.Now())
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"status":"refunded"`)
+			assert.Equal(t, orders.StatusRefunded, orderStatus(t, f.svc))
+			history, err := f.svc.Orders.Repo.History(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Nil(t, history[len(history)-1].ActorID)
+			// Оплачене, але не зібране замовлення повертається на склад
+			p, err := f.products.Get(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, p.StockQuantity)
+
+			// Повторна доставка та запізніла подія нічого не змінюють
+			assert.Equal(t, http.StatusOK, webhook(r, refunded, time.Now()).Code)
+			rr = webhook(r, `{"id":"evt_0","reference":"`+ref+`","status":"captured"}`, time.Now())
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"status":"refunded"`)
+			history2, err := f.svc.Orders.Repo.History(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Len(t, history2, len(history))
+
+			assert.Equal(t, http.StatusNotFound, webhook(r, `{"id":"evt_2","reference":"fake_9","status":"captured"}`, time.Now()).Code)
+			rr = webhook(r, `{"id":"","reference":"`+ref+`","status":"lost"}`, time.Now())
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"status"`)
+		})
+	}
+}
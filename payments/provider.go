@@ -0,0 +1,65 @@
+// Package payments реалізує прийом оплат за замовлення через платіжних
+// провайдерів та обробку їх вебхуків.
+package payments
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// Статуси платежу
+const (
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusVoided     = "voided"
+	StatusFailed     = "failed"
+)
+
+// Statuses — усі відомі статуси платежу
+var Statuses = []string{StatusAuthorized, StatusCaptured, StatusRefunded, StatusVoided, StatusFailed}
+
+// ErrDeclined повертається провайдером, якщо платіж відхилено
+var ErrDeclined = errors.New("payments: payment declined")
+
+// ErrInvalidState повертається провайдером, якщо операція неможлива у поточному
+// стані платежу, наприклад повернення коштів за неоплаченим платежем
+var ErrInvalidState = errors.New("payments: operation not allowed in current payment state")
+
+// ErrUnknownPayment повертається провайдером, якщо платежу з таким посиланням немає
+var ErrUnknownPayment = errors.New("payments: unknown payment reference")
+
+// AuthorizeRequest — запит на блокування коштів
+type AuthorizeRequest struct {
+	OrderID int
+	Amount  money.Money
+	// Source — токен способу оплати, отриманий клієнтом від провайдера
+	Source string
+	// IdempotencyKey дозволяє безпечно повторити запит: провайдер поверне той самий результат
+	IdempotencyKey string
+}
+
+// Result — стан платежу на боці провайдера після операції
+type Result struct {
+	// Reference — ідентифікатор платежу у провайдера
+	Reference string
+	Status    string
+}
+
+// Provider описує платіжний шлюз. Реалізації повертають ErrDeclined,
+// ErrInvalidState або ErrUnknownPayment для очікуваних відмов.
+type Provider interface {
+	// Name повертає назву провайдера, під якою зберігаються його платежі
+	Name() string
+	// Authorize блокує кошти; для відхиленого платежу повертає Result зі
+	// статусом failed разом з ErrDeclined
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	// Capture списує раніше заблоковану суму, не більшу за авторизовану
+	Capture(ctx context.Context, reference string, amount money.Money) (Result, error)
+	// Refund повертає списані кошти, частково або повністю
+	Refund(ctx context.Context, reference string, amount money.Money) (Result, error)
+	// Void скасовує авторизацію, за якою ще нічого не списано
+	Void(ctx context.Context, reference string) (Result, error)
+}
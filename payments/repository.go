@@ -0,0 +1,60 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// ErrNotFound повертається репозиторієм, якщо платежу не існує
+var ErrNotFound = errors.New("payments: not found")
+
+// Payment — платіж за замовлення, як його бачить магазин
+type Payment struct {
+	ID        int         `json:"id"`
+	OrderID   int         `json:"orderID"`
+	Provider  string      `json:"provider"`
+	Reference string      `json:"reference"`
+	Status    string      `json:"status"`
+	Amount    money.Money `json:"amount"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Event — повідомлення провайдера про зміну статусу платежу
+type Event struct {
+	// ID унікальний у межах провайдера; повторна доставка має той самий ID
+	ID        string `json:"id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+}
+
+// PaymentRepository описує сховище платежів, з яким працює PaymentService
+type PaymentRepository interface {
+	// Create зберігає новий платіж та заповнює ID і дати
+	Create(ctx context.Context, p *Payment) error
+	// ListByOrder повертає платежі замовлення від найстарішого
+	ListByOrder(ctx context.Context, orderID int) ([]Payment, error)
+	// ApplyEvent в одній транзакції записує подію провайдера та оновлює статус
+	// платежу, якщо подія його просуває; changed повідомляє, чи статус змінився.
+	// Подія з уже обробленим ID нічого не змінює.
+	// Повертає ErrNotFound, якщо платежу з event.Reference немає.
+	ApplyEvent(ctx context.Context, provider string, event Event) (p Payment, changed bool, err error)
+}
+
+// rank впорядковує статуси: платіж не повертається до попереднього статусу,
+// навіть якщо провайдер доставив події не по порядку
+var rank = map[string]int{
+	StatusAuthorized: 1,
+	StatusCaptured:   2,
+	StatusRefunded:   3,
+	StatusVoided:     3,
+	StatusFailed:     3,
+}
+
+// supersedes повідомляє, чи має статус next замінити поточний статус current
+func supersedes(current, next string) bool {
+	return rank[next] > rank[current]
+}
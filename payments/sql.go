@@ -0,0 +1,132 @@
+package payments
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+// SQLRepository зберігає платежі у таблицях payments та payment_events SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+const paymentColumns = "id, order_id, provider, reference, status, amount, currency, created_at, updated_at"
+
+func scanPayment(row interface{ Scan(...interface{}) error }) (Payment, error) {
+	var p Payment
+	err := row.Scan(&p.ID, &p.OrderID, &p.Provider, &p.Reference, &p.Status, &p.Amount.Amount, &p.Amount.Currency, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (m *SQLRepository) Create(ctx context.Context, p *Payment) error {
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, m.DB, "INSERT INTO payments (order_id, provider, reference, status, amount, currency, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		p.OrderID, p.Provider, p.Reference, p.Status, p.Amount.Amount, p.Amount.Currency, now, now)
+	if err != nil {
+		return err
+	}
+	p.ID = int(id)
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) ListByOrder(ctx context.Context, orderID int) ([]Payment, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT "+paymentColumns+" FROM payments WHERE order_id = ? ORDER BY id"), orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+func (m *SQLRepository) ApplyEvent(ctx context.Context, provider string, event Event) (Payment, bool, error) {
+	p, changed, err := m.applyEvent(ctx, provider, event)
+	if err != nil && err != ErrNotFound {
+		// Одночасна доставка тієї самої події порушує унікальність event_id;
+		// якщо подію вже записано, це повтор, а не помилка
+		if seen, seenErr := m.eventSeen(ctx, m.DB, provider, event.ID); seenErr == nil && seen {
+			p, err := scanPayment(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+paymentColumns+" FROM payments WHERE provider = ? AND reference = ?"), provider, event.Reference))
+			return p, false, err
+		}
+	}
+	return p, changed, err
+}
+
+func (m *SQLRepository) applyEvent(ctx context.Context, provider string, event Event) (Payment, bool, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Payment{}, false, err
+	}
+	defer tx.Rollback()
+
+	p, err := scanPayment(tx.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+paymentColumns+" FROM payments WHERE provider = ? AND reference = ?"), provider, event.Reference))
+	if err == sql.ErrNoRows {
+		return Payment{}, false, ErrNotFound
+	}
+	if err != nil {
+		return Payment{}, false, err
+	}
+
+	seen, err := m.eventSeen(ctx, tx, provider, event.ID)
+	if err != nil || seen {
+		return p, false, err
+	}
+
+	now := time.Now().UTC()
+	_, err = tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO payment_events (provider, event_id, payment_id, status, created_at) VALUES (?, ?, ?, ?, ?)"),
+		provider, event.ID, p.ID, event.Status, now)
+	if err != nil {
+		return Payment{}, false, err
+	}
+
+	changed := false
+	if supersedes(p.Status, event.Status) {
+		// Умова status = ? не дає одночасній події перезаписати новіший статус
+		result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE payments SET status = ?, updated_at = ? WHERE id = ? AND status = ?"),
+			event.Status, now, p.ID, p.Status)
+		if err != nil {
+			return Payment{}, false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return Payment{}, false, err
+		}
+		if changed = rowsAffected > 0; changed {
+			p.Status = event.Status
+			p.UpdatedAt = now
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Payment{}, false, err
+	}
+	return p, changed, nil
+}
+
+// eventSeen повідомляє, чи подію провайдера вже оброблено
+func (m *SQLRepository) eventSeen(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, provider, eventID string) (bool, error) {
+	var n int
+	err := q.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM payment_events WHERE provider = ? AND event_id = ?"), provider, eventID).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader — заголовок з підписом вебхука у форматі "t=<unix>,v1=<hex>"
+const SignatureHeader = "X-Payment-Signature"
+
+// SignatureTolerance — допустима різниця між часом підпису та поточним часом;
+// старіші запити відхиляються, щоб перехоплений вебхук не можна було відтворити пізніше
+const SignatureTolerance = 5 * time.Minute
+
+var errBadSignature = errors.New("payments: invalid webhook signature")
+
+// Sign повертає значення заголовка SignatureHeader для тіла вебхука:
+// HMAC-SHA256 від "<unix>.<body>" з секретом secret
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, signature(secret, ts, body))
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify перевіряє заголовок підпису; порожній секрет відхиляє будь-який запит
+func verify(secret, header string, body []byte, now time.Time) error {
+	if secret == "" {
+		return errBadSignature
+	}
+
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errBadSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return errBadSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, ts, body))) {
+		return errBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package problem реалізує відповіді про помилки у форматі
+// RFC 7807 (application/problem+json), спільні для всіх сервісів.
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+
+	"github.com/chitawebui131/shop_go/deadline"
+)
+
+// ContentType — тип вмісту відповідей з описом проблеми
+const ContentType = "application/problem+json"
+
+// Problem описує помилку відповідно до RFC 7807
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError описує помилку в окремому полі запиту
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// New створює проблему зі стандартним заголовком для статусу
+func New(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Newf створює проблему з форматованим описом
+func Newf(status int, format string, args ...interface{}) *Problem {
+	return New(status, fmt.Sprintf(format, args...))
+}
+
+// Error реалізує інтерфейс error, щоб проблему можна було повертати з функцій
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// Write надсилає проблему у відповідь, доповнюючи її шляхом та ID запиту.
+// p не змінюється, тож спільні значення проблем можна використовувати повторно.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	out := *p
+	if out.Instance == "" {
+		out.Instance = r.URL.Path
+	}
+	if out.RequestID == "" {
+		out.RequestID = middleware.GetReqID(r.Context())
+	}
+
+	body, err := json.Marshal(out)
+	if err != nil {
+		log.Println("Error encoding problem:", err)
+		body = []byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`)
+		out.Status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(out.Status)
+	w.Write(append(body, '\n'))
+}
+
+// Error надсилає проблему для помилки сховища. Якщо err уже є *Problem,
+// вона надсилається як є; інакше статус визначається deadline.Status,
+// а деталі внутрішньої помилки не розкриваються клієнту.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	if p, ok := err.(*Problem); ok {
+		Write(w, r, p)
+		return
+	}
+
+	status := deadline.Status(err)
+	detail := ""
+	switch status {
+	case http.StatusGatewayTimeout:
+		detail = "the request took too long to complete"
+	case http.StatusServiceUnavailable:
+		detail = "the request was cancelled"
+	}
+	Write(w, r, New(status, detail))
+}
+
+// NotFound обробляє невідомі маршрути
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Newf(http.StatusNotFound, "no route for %s", r.URL.Path))
+}
+
+// MethodNotAllowed обробляє запити з непідтримуваним методом
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Newf(http.StatusMethodNotAllowed, "method %s is not allowed for %s", r.Method, r.URL.Path))
+}
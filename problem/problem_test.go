@@ -0,0 +1,50 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	req := httptest.NewRequest("GET", "/products/7", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "req-1"))
+	rr := httptest.NewRecorder()
+
+	p := New(http.StatusUnprocessableEntity, "validation failed")
+	p.Errors = []FieldError{{Field: "price", Message: "must not be negative"}}
+	Write(rr, req, p)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+
+	var got Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, "Unprocessable Entity", got.Title)
+	assert.Equal(t, "/products/7", got.Instance)
+	assert.Equal(t, "req-1", got.RequestID)
+	assert.Equal(t, "price", got.Errors[0].Field)
+}
+
+func TestErrorHidesInternalDetails(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Error(rr, httptest.NewRequest("GET", "/users", nil), errors.New("dial tcp 10.0.0.5:3306: connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "10.0.0.5")
+}
+
+func TestErrorPassesProblemThrough(t *testing.T) {
+	rr := httptest.NewRecorder()
+	var err error = New(http.StatusConflict, "email already registered")
+	Error(rr, httptest.NewRequest("POST", "/users", nil), err)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "email already registered")
+}
@@ -0,0 +1,395 @@
+package products
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/media"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// DefaultMaxImageBytes — найбільший розмір файлу зображення за замовчуванням
+const DefaultMaxImageBytes = 10 << 20
+
+// Image — зображення продукту з мініатюрами
+type Image struct {
+	ID        int `json:"id"`
+	ProductID int `json:"productID"`
+	// Position — порядок зображення в галереї продукту
+	Position int `json:"position"`
+	// Primary — головне зображення продукту, що показується у списках
+	Primary     bool   `json:"primary"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Size — розмір файлу в байтах
+	Size int64 `json:"size"`
+	// Key — ключ оригіналу у сховищі
+	Key        string      `json:"-"`
+	URL        string      `json:"url"`
+	Thumbnails []Thumbnail `json:"thumbnails"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// Thumbnail — зменшена копія зображення, вписана в рамку з назвою Name
+type Thumbnail struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Key         string `json:"-"`
+	URL         string `json:"url"`
+}
+
+// keys повертає ключі всіх файлів зображення у сховищі
+func (img Image) keys() []string {
+	keys := []string{img.Key}
+	for _, t := range img.Thumbnails {
+		keys = append(keys, t.Key)
+	}
+	return keys
+}
+
+var (
+	errInvalidImageID = problem.New(http.StatusBadRequest, "imageID must be a positive integer")
+	errNoStorage      = problem.New(http.StatusServiceUnavailable, "image storage is not configured")
+	errNotMultipart   = problem.New(http.StatusBadRequest, "request must be multipart/form-data with a file field")
+	errImageType      = problem.New(http.StatusUnsupportedMediaType, "image must be JPEG, PNG, GIF or WebP")
+)
+
+// getURLParamImageID повертає числовий ID з URL-параметра {imageID}
+func getURLParamImageID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "imageID"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// withURLs заповнює адреси файлів зображень зі сховища
+func (s *ProductService) withURLs(images ...*Image) {
+	if s.Storage == nil {
+		return
+	}
+	for _, img := range images {
+		img.URL = s.Storage.URL(img.Key)
+		for i := range img.Thumbnails {
+			img.Thumbnails[i].URL = s.Storage.URL(img.Thumbnails[i].Key)
+		}
+	}
+}
+
+// images повертає зображення продукту з адресами файлів
+func (s *ProductService) images(ctx context.Context, productID int) ([]Image, error) {
+	images, err := s.Repo.Images(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range images {
+		s.withURLs(&images[i])
+	}
+	return images, nil
+}
+
+// removeFiles видаляє файли зі сховища; помилки лише журналюються, бо записи
+// зображень уже видалено
+func (s *ProductService) removeFiles(ctx context.Context, keys []string) {
+	if s.Storage == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil {
+			log.Println("Error deleting image file:", key, err)
+		}
+	}
+}
+
+// readUpload читає файл з поля file multipart-запиту, не більше maxBytes байтів
+func readUpload(r *http.Request, maxBytes int64) ([]byte, error) {
+	tooLarge := problem.Newf(http.StatusRequestEntityTooLarge, "image must not exceed %d bytes", maxBytes)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errNotMultipart
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, validate.Failed(problem.FieldError{Field: "file", Message: "is required"})
+		}
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, tooLarge
+		}
+		if err != nil {
+			return nil, errNotMultipart
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxBytes+1))
+		if errors.As(err, &maxErr) || int64(len(data)) > maxBytes {
+			return nil, tooLarge
+		}
+		if err != nil {
+			return nil, errNotMultipart
+		}
+		return data, nil
+	}
+}
+
+// storeImage зберігає оригінал та мініатюри зображення; у разі помилки вже
+// збережені файли видаляються
+func (s *ProductService) storeImage(ctx context.Context, productID int, data []byte, contentType string) (Image, error) {
+	maxPixels := s.MaxImagePixels
+	decoded, err := media.Decode(data, maxPixels)
+	if err == media.ErrTooManyPixels {
+		if maxPixels <= 0 {
+			maxPixels = media.DefaultMaxPixels
+		}
+		return Image{}, validate.Failed(problem.FieldError{Field: "file", Message: fmt.Sprintf("must not exceed %d pixels", maxPixels)})
+	}
+	if err != nil {
+		return Image{}, validate.Failed(problem.FieldError{Field: "file", Message: "is not a valid image"})
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return Image{}, err
+	}
+	prefix := fmt.Sprintf("products/%d/%s/", productID, hex.EncodeToString(random))
+	img := Image{
+		ProductID:   productID,
+		ContentType: contentType,
+		Width:       decoded.Bounds().Dx(),
+		Height:      decoded.Bounds().Dy(),
+		Size:        int64(len(data)),
+		Key:         prefix + "original" + media.Extensions[contentType],
+		Thumbnails:  []Thumbnail{},
+	}
+
+	stored := []string{}
+	put := func(key string, body []byte, contentType string) error {
+		if err := s.Storage.Put(ctx, key, bytes.NewReader(body), contentType); err != nil {
+			return err
+		}
+		stored = append(stored, key)
+		return nil
+	}
+	if err := put(img.Key, data, contentType); err != nil {
+		return Image{}, err
+	}
+	for _, size := range s.Thumbnails {
+		thumb := media.Fit(decoded, size.Width, size.Height)
+		var buf bytes.Buffer
+		thumbType, err := media.Encode(&buf, thumb, contentType)
+		if err == nil {
+			t := Thumbnail{
+				Name:        size.Name,
+				ContentType: thumbType,
+				Width:       thumb.Bounds().Dx(),
+				Height:      thumb.Bounds().Dy(),
+				Key:         prefix + size.Name + media.Extensions[thumbType],
+			}
+			err = put(t.Key, buf.Bytes(), thumbType)
+			img.Thumbnails = append(img.Thumbnails, t)
+		}
+		if err != nil {
+			s.removeFiles(ctx, stored)
+			return Image{}, err
+		}
+	}
+	return img, nil
+}
+
+// GetImages повертає зображення продукту в порядку галереї
+// GET /products/{id}/images
+func (s *ProductService) GetImages(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	images, err := s.images(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, images)
+}
+
+// UploadImage приймає зображення з поля file multipart-запиту, визначає його
+// тип за вмістом, зберігає оригінал і мініатюри та додає його в кінець
+// галереї. Перше зображення продукту стає головним.
+// POST /products/{id}/images
+func (s *ProductService) UploadImage(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	if s.Storage == nil {
+		problem.Write(w, r, errNoStorage)
+		return
+	}
+
+	maxBytes := s.MaxImageBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxImageBytes
+	}
+	data, err := readUpload(r, maxBytes)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	contentType, err := media.Sniff(data)
+	if err != nil {
+		problem.Write(w, r, errImageType)
+		return
+	}
+
+	img, err := s.storeImage(r.Context(), product.ID, data, contentType)
+	if err != nil {
+		if _, ok := err.(*problem.Problem); !ok {
+			log.Println("Error storing product image:", err)
+		}
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.Repo.CreateImage(r.Context(), &img); err != nil {
+		log.Println("Error inserting product image:", err)
+		s.removeFiles(r.Context(), img.keys())
+		problem.Error(w, r, err)
+		return
+	}
+
+	s.withURLs(&img)
+	render.JSON(w, r, http.StatusCreated, img)
+}
+
+// ImageOrder — новий порядок усіх зображень продукту
+type ImageOrder struct {
+	ImageIDs []int `json:"imageIDs"`
+}
+
+// ReorderImages змінює порядок галереї; запит має перелічити всі зображення продукту
+// PUT /products/{id}/images/order
+func (s *ProductService) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+
+	var order ImageOrder
+	if err := validate.DecodeJSON(r, &order); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	images, err := s.Repo.Images(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	known := make(map[int]bool, len(images))
+	for _, img := range images {
+		known[img.ID] = true
+	}
+	seen := make(map[int]bool, len(order.ImageIDs))
+	valid := len(order.ImageIDs) == len(images)
+	for _, id := range order.ImageIDs {
+		valid = valid && known[id] && !seen[id]
+		seen[id] = true
+	}
+	if !valid {
+		problem.Error(w, r, validate.Failed(problem.FieldError{Field: "imageIDs", Message: "must list every image of the product exactly once"}))
+		return
+	}
+
+	if err := s.Repo.ReorderImages(r.Context(), product.ID, order.ImageIDs); err != nil {
+		log.Println("Error reordering product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	images, err = s.images(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, images)
+}
+
+// SetPrimaryImage робить зображення головним
+// PUT /products/{id}/images/{imageID}/primary
+func (s *ProductService) SetPrimaryImage(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	imageID, ok := getURLParamImageID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidImageID)
+		return
+	}
+
+	if err := s.Repo.SetPrimaryImage(r.Context(), product.ID, imageID); err != nil {
+		if err == ErrImageNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "image %d of product %d not found", imageID, product.ID))
+		} else {
+			log.Println("Error updating product image:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	images, err := s.images(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, images)
+}
+
+// DeleteImage видаляє зображення та його файли; якщо воно було головним,
+// головним стає перше з решти
+// DELETE /products/{id}/images/{imageID}
+func (s *ProductService) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	imageID, ok := getURLParamImageID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidImageID)
+		return
+	}
+
+	img, err := s.Repo.GetImage(r.Context(), product.ID, imageID)
+	if err == nil {
+		err = s.Repo.DeleteImage(r.Context(), product.ID, imageID)
+	}
+	if err != nil {
+		if err == ErrImageNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "image %d of product %d not found", imageID, product.ID))
+		} else {
+			log.Println("Error deleting product image:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	s.removeFiles(r.Context(), img.keys())
+	w.WriteHeader(http.StatusNoContent)
+}
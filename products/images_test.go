@@ -0,0 +1,168 @@
+package products_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/media"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// multipartFile повертає тіло multipart-запиту з файлом у полі field та його Content-Type
+func multipartFile(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	// Назва та тип файлу від клієнта ігноруються: тип визначається за вмістом
+	fw, err := mw.CreateFormFile(field, "photo.jpg")
+	assert.NoError(t, err)
+	_, err = fw.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func TestImages(t *testing.T) {
+	memCats := categories.NewMemoryRepository()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	var photo bytes.Buffer
+	assert.NoError(t, png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 800, 400))))
+
+	for name, repos := range map[string]struct {
+		cats categories.CategoryRepository
+		repo products.ProductRepository
+	}{
+		"memory": {memCats, products.NewMemoryRepository(memCats)},
+		"sqlite": {sqlCats, products.NewSQLRepository(db, d)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, repos.cats.Create(ctx, &categories.Category{Name: "Lamps"}))
+			assert.NoError(t, repos.repo.Create(ctx, &products.Product{Name: "Desk lamp", Price: money.MustParse("999", "UAH"), CategoryID: 1}))
+
+			dir := t.TempDir()
+			svc := &products.ProductService{
+				Repo:          repos.repo,
+				Storage:       media.NewLocalStorage(dir, "/media/"),
+				Thumbnails:    []media.Size{{Name: "small", Width: 100, Height: 100}, {Name: "large", Width: 1000, Height: 1000}},
+				MaxImageBytes: 64 << 10,
+			}
+			r := newRouter(svc)
+			r.Get("/products/{id}/images", svc.GetImages)
+			r.Post("/products/{id}/images", svc.UploadImage)
+			r.Put("/products/{id}/images/order", svc.ReorderImages)
+			r.Put("/products/{id}/images/{imageID}/primary", svc.SetPrimaryImage)
+			r.Delete("/products/{id}/images/{imageID}", svc.DeleteImage)
+
+			upload := func(field string, data []byte) *httptest.ResponseRecorder {
+				body, contentType := multipartFile(t, field, data)
+				req := httptest.NewRequest("POST", "/products/1/images", body)
+				req.Header.Set("Content-Type", contentType)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+				return rr
+			}
+			do := func(method, path, body string) *httptest.ResponseRecorder {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+				return rr
+			}
+			fileOf := func(url string) string {
+				return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(url, "/media/")))
+			}
+
+			rr := upload("file", photo.Bytes())
+			assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+			var first products.Image
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &first))
+			assert.True(t, first.Primary)
+			assert.Equal(t, "image/png", first.ContentType)
+			assert.Equal(t, []int{800, 400}, []int{first.Width, first.Height})
+			assert.True(t, strings.HasPrefix(first.URL, "/media/products/1/"))
+			assert.FileExists(t, fileOf(first.URL))
+			assert.Len(t, first.Thumbnails, 2)
+			assert.Equal(t, []int{100, 50}, []int{first.Thumbnails[0].Width, first.Thumbnails[0].Height})
+			// Мініатюра не збільшує менше зображення
+			assert.Equal(t, []int{800, 400}, []int{first.Thumbnails[1].Width, first.Thumbnails[1].Height})
+			assert.FileExists(t, fileOf(first.Thumbnails[0].URL))
+
+			rr = upload("file", photo.Bytes())
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var second products.Image
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &second))
+			assert.False(t, second.Primary)
+			assert.Equal(t, 1, second.Position)
+
+			assert.Equal(t, http.StatusUnsupportedMediaType, upload("file", []byte("just some text")).Code)
+			// Сигнатура GIF без коректного вмісту
+			assert.Equal(t, http.StatusUnprocessableEntity, upload("file", []byte("GIF89a broken")).Code)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, upload("file", bytes.Repeat([]byte{0}, 65<<10)).Code)
+			assert.Equal(t, http.StatusUnprocessableEntity, upload("photo", photo.Bytes()).Code)
+			assert.Equal(t, http.StatusBadRequest, do("POST", "/products/1/images", `{}`).Code)
+
+			rr = do("PUT", "/products/1/images/order", `{"imageIDs":[2]}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			rr = do("PUT", "/products/1/images/order", `{"imageIDs":[2,1]}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var images []products.Image
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &images))
+			assert.Equal(t, []int{2, 1}, []int{images[0].ID, images[1].ID})
+
+			rr = do("PUT", "/products/1/images/2/primary", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &images))
+			assert.True(t, images[0].Primary)
+			assert.False(t, images[1].Primary)
+
+			// Головне зображення потрапляє у список продуктів, а всі — у картку продукту
+			rr = do("GET", "/products", "")
+			var list products.ProductList
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			assert.Equal(t, second.URL, list.Data[0].ProductImage.URL)
+			rr = do("GET", "/products/1", "")
+			var detail products.ProductDetail
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &detail))
+			assert.Len(t, detail.Images, 2)
+
+			// Видалення головного зображення робить головним наступне та прибирає файли
+			assert.Equal(t, http.StatusNoContent, do("DELETE", "/products/1/images/2", "").Code)
+			assert.NoFileExists(t, fileOf(second.URL))
+			assert.NoFileExists(t, fileOf(second.Thumbnails[0].URL))
+			rr = do("GET", "/products/1/images", "")
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &images))
+			assert.Len(t, images, 1)
+			assert.True(t, images[0].Primary)
+			assert.Equal(t, http.StatusNotFound, do("DELETE", "/products/1/images/2", "").Code)
+
+			assert.Equal(t, http.StatusNoContent, do("DELETE", "/products/1", "").Code)
+			_, err := os.Stat(fileOf(first.URL))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
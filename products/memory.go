@@ -0,0 +1,524 @@
+package products
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MemoryRepository зберігає продукти у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryRepository struct {
+	mu         sync.RWMutex
+	products   map[int]Product
+	nextID     int
+	categories CategoryReader
+
+	options       map[int][]Option
+	variants      map[int]Variant
+	nextVariantID int
+	images        map[int]Image
+	nextImageID   int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті.
+// cats може бути nil — тоді List не заповнює дані категорій.
+func NewMemoryRepository(cats CategoryReader) *MemoryRepository {
+	return &MemoryRepository{
+		products:      make(map[int]Product),
+		nextID:        1,
+		categories:    cats,
+		options:       make(map[int][]Option),
+		variants:      make(map[int]Variant),
+		nextVariantID: 1,
+		images:        make(map[int]Image),
+		nextImageID:   1,
+	}
+}
+
+// filtered повертає продукти, що задовольняють фільтр, у його порядку; викликається під m.mu
+func (m *MemoryRepository) filtered(f Filter) []Product {
+	products := []Product{}
+	for _, p := range m.products {
+		if f.matches(p) {
+			products = append(products, p)
+		}
+	}
+	f.sortProducts(products)
+	return products
+}
+
+func (m *MemoryRepository) List(ctx context.Context, f Filter) ([]ProductWithCategoryWithoutDates, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	found := m.filtered(f)
+	if f.Page.Backward() {
+		for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+			found[i], found[j] = found[j], found[i]
+		}
+	}
+	var (
+		boundary    Product
+		hasBoundary bool
+	)
+	if f.Page.Cursor != nil {
+		boundary, hasBoundary = f.boundary(*f.Page.Cursor)
+	}
+
+	products := []ProductWithCategoryWithoutDates{}
+	for _, p := range found {
+		if len(products) > f.Page.Limit {
+			break
+		}
+		if hasBoundary && ((f.Page.Backward() && !f.less(p, boundary)) || (!f.Page.Backward() && !f.less(boundary, p))) {
+			continue
+		}
+		item := ProductWithCategoryWithoutDates{
+			ProductID:          p.ID,
+			ProductName:        p.Name,
+			ProductDescription: p.Description,
+			ProductPrice:       p.Price,
+			StockQuantity:      p.StockQuantity,
+			ProductCategoryID:  p.CategoryID,
+			ProductCreatedAt:   p.Created_at,
+		}
+		// Аналог LEFT JOIN: відсутня категорія залишає порожні поля
+		if m.categories != nil {
+			if cat, err := m.categories.Get(ctx, p.CategoryID); err == nil {
+				item.CategoryID = cat.ID
+				item.CategoryName = cat.Name
+				item.CategoryDescription = cat.Description
+			}
+		}
+		products = append(products, item)
+	}
+	return products, nil
+}
+
+func (m *MemoryRepository) Facets(ctx context.Context, f Filter, bounds []decimal.Decimal) (Facets, error) {
+	if err := ctx.Err(); err != nil {
+		return Facets{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	facets := Facets{Categories: []CategoryFacet{}, Prices: newPriceFacets(bounds)}
+	counts := make(map[int]int)
+	for _, p := range m.filtered(f) {
+		counts[p.CategoryID]++
+		facets.Prices[priceBucket(bounds, p.Price.Amount)].Count++
+	}
+	for id, count := range counts {
+		facet := CategoryFacet{CategoryID: id, Count: count}
+		if m.categories != nil {
+			if cat, err := m.categories.Get(ctx, id); err == nil {
+				facet.Name = cat.Name
+			}
+		}
+		facets.Categories = append(facets.Categories, facet)
+	}
+	sort.Slice(facets.Categories, func(i, j int) bool { return facets.Categories[i].CategoryID < facets.Categories[j].CategoryID })
+	return facets, nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Product, error) {
+	if err := ctx.Err(); err != nil {
+		return Product{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	product, ok := m.products[id]
+	if !ok {
+		return Product{}, ErrNotFound
+	}
+	return product, nil
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, p *Product) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	p.ID = m.nextID
+	p.Created_at = now
+	p.Updated_at = now
+	m.products[p.ID] = *p
+	m.nextID++
+	return nil
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, id int, p *Product) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.products[id]
+	if !ok {
+		return ErrNotFound
+	}
+	p.ID = id
+	p.Created_at = old.Created_at
+	p.Updated_at = time.Now()
+	m.products[id] = *p
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.products[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.products, id)
+	delete(m.options, id)
+	for variantID, v := range m.variants {
+		if v.ProductID == id {
+			delete(m.variants, variantID)
+		}
+	}
+	for imageID, img := range m.images {
+		if img.ProductID == id {
+			delete(m.images, imageID)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) AdjustStock(ctx context.Context, id, delta int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.products[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if p.StockQuantity+delta < 0 {
+		return ErrInsufficientStock
+	}
+	p.StockQuantity += delta
+	p.Updated_at = time.Now()
+	m.products[id] = p
+	return nil
+}
+
+// copyOptions повертає копію типів опцій, що не ділить пам'ять з оригіналом
+func copyOptions(options []Option) []Option {
+	copied := make([]Option, 0, len(options))
+	for _, o := range options {
+		copied = append(copied, Option{Name: o.Name, Values: append([]string{}, o.Values...)})
+	}
+	return copied
+}
+
+// copyVariant повертає копію варіанта, що не ділить пам'ять з оригіналом
+func copyVariant(v Variant) Variant {
+	options := make(map[string]string, len(v.Options))
+	for name, value := range v.Options {
+		options[name] = value
+	}
+	v.Options = options
+	if v.Price != nil {
+		price := *v.Price
+		v.Price = &price
+	}
+	return v
+}
+
+func (m *MemoryRepository) Options(ctx context.Context, productID int) ([]Option, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return copyOptions(m.options[productID]), nil
+}
+
+func (m *MemoryRepository) SetOptions(ctx context.Context, productID int, options []Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.options[productID] = copyOptions(options)
+	return nil
+}
+
+func (m *MemoryRepository) Variants(ctx context.Context, productID int) ([]Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	variants := []Variant{}
+	for _, v := range m.variants {
+		if v.ProductID == productID {
+			variants = append(variants, copyVariant(v))
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].ID < variants[j].ID })
+	return variants, nil
+}
+
+func (m *MemoryRepository) GetVariant(ctx context.Context, productID, id int) (Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return Variant{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.variants[id]
+	if !ok || v.ProductID != productID {
+		return Variant{}, ErrVariantNotFound
+	}
+	return copyVariant(v), nil
+}
+
+// skuTaken повідомляє, чи зайнятий артикул іншим варіантом; викликається під m.mu
+func (m *MemoryRepository) skuTaken(sku string, exceptID int) bool {
+	for id, v := range m.variants {
+		if id != exceptID && v.SKU == sku {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryRepository) CreateVariant(ctx context.Context, v *Variant) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.skuTaken(v.SKU, 0) {
+		return ErrDuplicateSKU
+	}
+	now := time.Now()
+	v.ID = m.nextVariantID
+	v.CreatedAt = now
+	v.UpdatedAt = now
+	m.variants[v.ID] = copyVariant(*v)
+	m.nextVariantID++
+	return nil
+}
+
+func (m *MemoryRepository) UpdateVariant(ctx context.Context, id int, v *Variant) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.variants[id]
+	if !ok || old.ProductID != v.ProductID {
+		return ErrVariantNotFound
+	}
+	if m.skuTaken(v.SKU, id) {
+		return ErrDuplicateSKU
+	}
+	v.ID = id
+	v.CreatedAt = old.CreatedAt
+	v.UpdatedAt = time.Now()
+	m.variants[id] = copyVariant(*v)
+	return nil
+}
+
+func (m *MemoryRepository) DeleteVariant(ctx context.Context, productID, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.variants[id]; !ok || v.ProductID != productID {
+		return ErrVariantNotFound
+	}
+	delete(m.variants, id)
+	return nil
+}
+
+// copyImage повертає копію зображення, що не ділить пам'ять з оригіналом
+func copyImage(img Image) Image {
+	img.Thumbnails = append([]Thumbnail{}, img.Thumbnails...)
+	return img
+}
+
+// productImages повертає зображення продукту в порядку галереї; викликається під m.mu
+func (m *MemoryRepository) productImages(productID int) []Image {
+	images := []Image{}
+	for _, img := range m.images {
+		if img.ProductID == productID {
+			images = append(images, copyImage(img))
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Position != images[j].Position {
+			return images[i].Position < images[j].Position
+		}
+		return images[i].ID < images[j].ID
+	})
+	return images
+}
+
+func (m *MemoryRepository) Images(ctx context.Context, productID int) ([]Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.productImages(productID), nil
+}
+
+func (m *MemoryRepository) PrimaryImages(ctx context.Context, productIDs []int) (map[int]Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+	primary := make(map[int]Image)
+	for _, img := range m.images {
+		if img.Primary && wanted[img.ProductID] {
+			primary[img.ProductID] = copyImage(img)
+		}
+	}
+	return primary, nil
+}
+
+func (m *MemoryRepository) GetImage(ctx context.Context, productID, id int) (Image, error) {
+	if err := ctx.Err(); err != nil {
+		return Image{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	img, ok := m.images[id]
+	if !ok || img.ProductID != productID {
+		return Image{}, ErrImageNotFound
+	}
+	return copyImage(img), nil
+}
+
+func (m *MemoryRepository) CreateImage(ctx context.Context, img *Image) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := m.productImages(img.ProductID)
+	img.ID = m.nextImageID
+	img.Position = 0
+	if len(existing) > 0 {
+		img.Position = existing[len(existing)-1].Position + 1
+	}
+	img.Primary = len(existing) == 0
+	img.CreatedAt = time.Now()
+	m.images[img.ID] = copyImage(*img)
+	m.nextImageID++
+	return nil
+}
+
+func (m *MemoryRepository) ReorderImages(ctx context.Context, productID int, ids []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for position, id := range ids {
+		if img, ok := m.images[id]; ok && img.ProductID == productID {
+			img.Position = position
+			m.images[id] = img
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) SetPrimaryImage(ctx context.Context, productID, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if img, ok := m.images[id]; !ok || img.ProductID != productID {
+		return ErrImageNotFound
+	}
+	for imageID, img := range m.images {
+		if img.ProductID == productID {
+			img.Primary = imageID == id
+			m.images[imageID] = img
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) DeleteImage(ctx context.Context, productID, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	img, ok := m.images[id]
+	if !ok || img.ProductID != productID {
+		return ErrImageNotFound
+	}
+	delete(m.images, id)
+	if remaining := m.productImages(productID); img.Primary && len(remaining) > 0 {
+		first := m.images[remaining[0].ID]
+		first.Primary = true
+		m.images[first.ID] = first
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/media"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/patch"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Product представляє модель продукту
+type Product struct {
+	ID            int         `json:"id"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	Price         money.Money `json:"price"`
+	StockQuantity int         `json:"stockQuantity"`
+	CategoryID    int         `json:"categoryID"`
+	// Weight — вага одиниці товару в грамах; 0 — невідома
+	Weight int `json:"weight"`
+	// Length, Width та Height — розміри упаковки в міліметрах
+	Length     int       `json:"length"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	Created_at time.Time `json:"created_at"`
+	Updated_at time.Time `json:"updated_at"`
+}
+
+// ProductWithCategoryWithoutDates представляє продукт разом з його категорією у списку
+type ProductWithCategoryWithoutDates struct {
+	ProductID           int         `json:"product_id"`
+	ProductName         string      `json:"product_name"`
+	ProductDescription  string      `json:"product_description"`
+	ProductPrice        money.Money `json:"product_price"`
+	StockQuantity       int         `json:"product_stockQuantity"`
+	ProductCategoryID   int         `json:"product_category_id"`
+	CategoryID          int         `json:"category_id"`
+	CategoryName        string      `json:"category_name"`
+	CategoryDescription string      `json:"category_description"`
+	ProductCreatedAt    time.Time   `json:"product_created_at"`
+	// ProductImage — головне зображення продукту; nil — зображень немає
+	ProductImage *Image `json:"product_image"`
+}
+
+// ProductService надає методи для роботи з продуктами
+type ProductService struct {
+	Repo ProductRepository
+	// Categories використовується для перевірки існування категорії; nil вимикає перевірку
+	Categories CategoryReader
+	// PriceBuckets — зростаючі межі цінових діапазонів фасету; nil — DefaultPriceBuckets
+	PriceBuckets []decimal.Decimal
+	// Storage зберігає файли зображень; nil вимикає завантаження
+	Storage media.Storage
+	// Thumbnails — розміри мініатюр, що створюються для кожного зображення
+	Thumbnails []media.Size
+	// MaxImageBytes обмежує розмір файлу зображення; 0 — DefaultMaxImageBytes
+	MaxImageBytes int64
+	// MaxImagePixels обмежує ширину × висоту зображення; 0 — media.DefaultMaxPixels
+	MaxImagePixels int
+}
+
+// check додає до v правила для полів продукту
+func (p Product) check(v *validate.Validator) {
+	v.Required("name", p.Name)
+	v.MaxLen("name", p.Name, 255)
+	v.MaxLen("description", p.Description, 10000)
+	if p.Price.Currency == "" {
+		// Валюту заповнює розбір JSON, тож її немає лише без ціни
+		v.Check(false, "price", "is required")
+	} else {
+		v.Check(!p.Price.IsNegative(), "price", "must not be negative")
+		v.Check(p.Price.Exact(), "price", fmt.Sprintf("must have at most %d decimal places", money.Exponent(p.Price.Currency)))
+		v.Check(money.ValidCurrency(p.Price.Currency), "price.currency", "must be a three-letter ISO 4217 code")
+	}
+	v.NonNegative("stockQuantity", float64(p.StockQuantity))
+	v.Positive("categoryID", p.CategoryID)
+	v.NonNegative("weight", float64(p.Weight))
+	v.NonNegative("length", float64(p.Length))
+	v.NonNegative("width", float64(p.Width))
+	v.NonNegative("height", float64(p.Height))
+}
+
+// Validate перевіряє поля продукту перед збереженням
+func (p Product) Validate() error {
+	v := validate.New()
+	p.check(v)
+	return v.Err()
+}
+
+// validate перевіряє поля продукту та існування його категорії
+func (s *ProductService) validate(ctx context.Context, p Product) error {
+	v := validate.New()
+	p.check(v)
+	if s.Categories != nil && p.CategoryID > 0 {
+		_, err := s.Categories.Get(ctx, p.CategoryID)
+		if err == categories.ErrNotFound {
+			v.Check(false, "categoryID", "category does not exist")
+		} else if err != nil {
+			log.Println("Error querying category:", err)
+			return err
+		}
+	}
+	return v.Err()
+}
+
+// ProductList — сторінка знайдених продуктів разом з фасетами для фільтра
+type ProductList struct {
+	Data   []ProductWithCategoryWithoutDates `json:"data"`
+	Page   pagination.Page                   `json:"page"`
+	Facets Facets                            `json:"facets"`
+}
+
+// GetProducts повертає сторінку продуктів, що задовольняють параметри пошуку
+// (див. ParseFilter), та фасети за категоріями і ціновими діапазонами.
+// Сторінки гортаються курсорами page.next та page.prev, які також передаються
+// в заголовку Link.
+// GET /products?limit=10&cursor=...&total=true&q=go&category_id=1,2&min_price=10&max_price=100&in_stock=true&sort=price,-created_at
+func (s *ProductService) GetProducts(w http.ResponseWriter, r *http.Request) {
+	f, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Вибірка продуктів зі сховища з пагінацією
+	rows, err := s.Repo.List(r.Context(), f)
+	if err != nil {
+		log.Println("Error querying products:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	list := ProductList{}
+	list.Data, list.Page = pagination.Build(rows, f.Page, f.cursor)
+
+	ids := make([]int, 0, len(list.Data))
+	for _, p := range list.Data {
+		ids = append(ids, p.ProductID)
+	}
+	primary, err := s.Repo.PrimaryImages(r.Context(), ids)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	for i := range list.Data {
+		if img, ok := primary[list.Data[i].ProductID]; ok {
+			s.withURLs(&img)
+			list.Data[i].ProductImage = &img
+		}
+	}
+
+	bounds := s.PriceBuckets
+	if bounds == nil {
+		bounds = DefaultPriceBuckets
+	}
+	list.Facets, err = s.Repo.Facets(r.Context(), f, bounds)
+	if err != nil {
+		log.Println("Error querying product facets:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	if f.Page.Total {
+		// Фасет категорій охоплює всі знайдені продукти, тож окремий COUNT не потрібен
+		total := 0
+		for _, c := range list.Facets.Categories {
+			total += c.Count
+		}
+		list.Page.Total = &total
+	}
+
+	// Відправлення відповіді у форматі JSON
+	pagination.SetLink(w, r, list.Page)
+	render.JSON(w, r, http.StatusOK, list)
+}
+
+// GetProduct повертає інформацію про конкретний продукт за ID разом з його
+// типами опцій та варіантами
+func (s *ProductService) GetProduct(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID продукту з URL-параметра
+	productID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	// Вибірка конкретного продукту зі сховища за ID
+	product, err := s.Repo.Get(r.Context(), productID)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d not found", productID))
+		} else {
+			log.Println("Error querying product:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	detail, err := s.detail(r.Context(), product)
+	if err != nil {
+		log.Println("Error querying product variants:", err)
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Відправлення відповіді у форматі JSON
+	render.JSON(w, r, http.StatusOK, detail)
+}
+
+// CreateProduct додає новий продукт
+func (s *ProductService) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	// Отримання даних про новий продукт з тіла запиту (JSON)
+	var newProduct Product
+	if err := validate.DecodeJSON(r, &newProduct); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), newProduct); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Додавання нового продукту до сховища
+	if err := s.Repo.Create(r.Context(), &newProduct); err != nil {
+		log.Println("Error inserting product:", err)
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Відправлення відповіді у форматі JSON з новоствореним продуктом та статусом 201 (Created)
+	render.JSON(w, r, http.StatusCreated, newProduct)
+}
+
+// UpdateProduct оновлює інформацію про продукт за ID
+func (s *ProductService) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID продукту з URL-параметра
+	productID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	// Отримання нових даних про продукт з тіла запиту (JSON)
+	var updatedProduct Product
+	if err := validate.DecodeJSON(r, &updatedProduct); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), updatedProduct); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Оновлення інформації про продукт у сховищі за ID
+	if err := s.Repo.Update(r.Context(), productID, &updatedProduct); err != nil {
+		if err == ErrNotFound {
+			// Якщо немає відповідного продукту, відправити HTTP статус 404 (Not Found)
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d not found", productID))
+		} else {
+			log.Println("Error updating product:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	// Відправлення відповіді у форматі JSON з оновленим продуктом
+	render.JSON(w, r, http.StatusOK, updatedProduct)
+}
+
+// PatchProduct частково оновлює продукт: тіло запиту (merge patch або JSON Patch)
+// застосовується до збереженого продукту, тож поля, яких немає в патчі, не
+// змінюються. ID та дати змінити не можна. Відповідь містить продукт у тому ж
+// вигляді, що й GetProduct.
+func (s *ProductService) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	current, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+
+	var patched Product
+	if err := patch.Decode(r, current, &patched); err != nil {
+		patch.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), patched); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.Update(r.Context(), current.ID, &patched); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d not found", current.ID))
+		} else {
+			log.Println("Error updating product:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	// Повертається збережений стан, а не декодований патч
+	stored, err := s.Repo.Get(r.Context(), current.ID)
+	if err != nil {
+		log.Println("Error querying product:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	detail, err := s.detail(r.Context(), stored)
+	if err != nil {
+		log.Println("Error querying product variants:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, detail)
+}
+
+// DeleteProduct видаляє продукт за ID
+func (s *ProductService) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID продукту з URL-параметра
+	productID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	// Файли зображень видаляються після видалення продукту, тож їхні ключі потрібні заздалегідь
+	images, err := s.Repo.Images(r.Context(), productID)
+	if err != nil {
+		log.Println("Error querying product images:", err)
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Видалення продукту зі сховища за ID
+	if err := s.Repo.Delete(r.Context(), productID); err != nil {
+		if err == ErrNotFound {
+			// Якщо немає відповідного продукту, відправити HTTP статус 404 (Not Found)
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d not found", productID))
+		} else {
+			log.Println("Error deleting product:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	for _, img := range images {
+		s.removeFiles(r.Context(), img.keys())
+	}
+
+	// Відправлення відповіді з підтвердженням видалення та статусом 204 (No Content)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errInvalidID повертається, якщо {id} не є додатним цілим числом
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
+
+// getURLParamID повертає числовий ID з URL-параметра {id}
+func getURLParamID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
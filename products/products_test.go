@@ -0,0 +1,217 @@
+package products_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+func newRouter(svc *products.ProductService) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/products", svc.GetProducts)
+	r.Get("/products/{id}", svc.GetProduct)
+	r.Post("/products", svc.CreateProduct)
+	r.Put("/products/{id}", svc.UpdateProduct)
+	r.Patch("/products/{id}", svc.PatchProduct)
+	r.Delete("/products/{id}", svc.DeleteProduct)
+	r.Get("/products/{id}/options", svc.GetOptions)
+	r.Put("/products/{id}/options", svc.SetOptions)
+	r.Get("/products/{id}/variants", svc.GetVariants)
+	r.Get("/products/{id}/variants/{variantID}", svc.GetVariant)
+	r.Post("/products/{id}/variants", svc.CreateVariant)
+	r.Put("/products/{id}/variants/{variantID}", svc.UpdateVariant)
+	r.Delete("/products/{id}/variants/{variantID}", svc.DeleteVariant)
+	return r
+}
+
+func TestProductLifecycleMemory(t *testing.T) {
+	cats := categories.NewMemoryRepository()
+	assert.NoError(t, cats.Create(context.Background(), &categories.Category{Name: "Books"}))
+
+	testProductLifecycle(t, products.NewMemoryRepository(cats), cats)
+}
+
+func TestProductLifecycleSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	cats := categories.NewSQLRepository(db, d)
+	assert.NoError(t, cats.Create(context.Background(), &categories.Category{Name: "Books"}))
+
+	testProductLifecycle(t, products.NewSQLRepository(db, d), cats)
+}
+
+func testProductLifecycle(t *testing.T, repo products.ProductRepository, cats products.CategoryReader) {
+	r := newRouter(&products.ProductService{Repo: repo, Categories: cats})
+
+	// Некоректний продукт відхиляється з переліком полів
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"","price":-1,"stockQuantity":1,"categoryID":99,"weight":-5}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	for _, field := range []string{`"field":"name"`, `"field":"price"`, `"field":"categoryID"`, `"field":"weight"`} {
+		assert.Contains(t, rr.Body.String(), field)
+	}
+
+	// Створення продукту
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"Go","price":10.5,"stockQuantity":3,"categoryID":1,"weight":450,"length":240,"width":170,"height":30}`)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var created products.Product
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, 1, created.ID)
+
+	// Вага та розміри зберігаються разом з продуктом
+	got, err := repo.Get(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, [4]int{450, 240, 170, 30}, [4]int{got.Weight, got.Length, got.Width, got.Height})
+
+	// Список містить дані категорії
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/products", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var list products.ProductList
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, "Books", list.Data[0].CategoryName)
+	assert.Equal(t, "10.50 UAH", list.Data[0].ProductPrice.String())
+
+	// Ціна з дрібнішими за копійку частками відхиляється, а не округлюється мовчки
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/products", strings.NewReader(`{"name":"Go","price":"12.345","categoryID":1}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"price"`)
+
+	// Відсутня ціна позначається як обов'язкове поле, а не як помилка валюти
+	for _, body := range []string{`{"name":"Go","categoryID":1}`, `{"name":"Go","price":null,"categoryID":1}`} {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("POST", "/products", strings.NewReader(body)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, body)
+		assert.Contains(t, rr.Body.String(), `{"field":"price","message":"is required"}`, body)
+		assert.NotContains(t, rr.Body.String(), "price.currency", body)
+	}
+
+	// PATCH змінює лише передані поля та повертає збережений продукт
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`{"price":11}`)))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var patched products.ProductDetail
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &patched))
+	assert.Equal(t, "Go", patched.Name)
+	assert.Equal(t, 3, patched.StockQuantity)
+	assert.Equal(t, 450, patched.Weight)
+	assert.Equal(t, "11.00 UAH", patched.Price.String())
+
+	req := httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`[{"op":"test","path":"/name","value":"Go"},{"op":"replace","path":"/stockQuantity","value":7}]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	got, err = repo.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, got.StockQuantity)
+	assert.Equal(t, "11.00 UAH", got.Price.String())
+
+	// Патч інших полів зберігає ціну без змін, а ціна з зайвими знаками відхиляється
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`{"price":"11.99"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`{"name":"Go Patched"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`{"price":{"amount":"10.505"}}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	got, err = repo.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.True(t, got.Price.Amount.Equal(money.MustParse("11.99", "UAH").Amount), got.Price.Amount.String())
+	assert.Equal(t, "Go Patched", got.Name)
+
+	for body, status := range map[string]int{
+		`{"name":null}`:     http.StatusUnprocessableEntity,
+		`{"colour":"red"}`:  http.StatusUnprocessableEntity,
+		`{"name":`:          http.StatusBadRequest,
+		`{"categoryID":99}`: http.StatusUnprocessableEntity,
+	} {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("PATCH", "/products/1", strings.NewReader(body)))
+		assert.Equal(t, status, rr.Code, body)
+	}
+	req = httptest.NewRequest("PATCH", "/products/1", strings.NewReader(`name=Go`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Contains(t, rr.Header().Get("Accept-Patch"), "application/merge-patch+json")
+
+	// Оновлення та видалення
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("PUT", "/products/1", strings.NewReader(`{"name":"Go 2","price":12,"categoryID":1}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var updated products.Product
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &updated))
+	assert.True(t, updated.Created_at.Equal(created.Created_at), updated.Created_at)
+	assert.False(t, updated.Updated_at.Before(updated.Created_at))
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("DELETE", "/products/1", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/products/1", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"detail":"product 1 not found"`)
+}
+
+func TestListWithoutCategory(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	ctx := context.Background()
+	cats := categories.NewSQLRepository(db, d)
+	repo := products.NewSQLRepository(db, d)
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+	assert.NoError(t, repo.Create(ctx, &products.Product{Name: "Go", Price: money.MustParse("10", "UAH"), CategoryID: 1}))
+
+	// Продукт видаленої категорії лишається у списку з порожніми полями категорії,
+	// як у репозиторії в пам'яті
+	assert.NoError(t, cats.Delete(ctx, 1))
+	rr := httptest.NewRecorder()
+	newRouter(&products.ProductService{Repo: repo}).ServeHTTP(rr, httptest.NewRequest("GET", "/products", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var list products.ProductList
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	if assert.Len(t, list.Data, 1) {
+		assert.Equal(t, 1, list.Data[0].ProductCategoryID)
+		assert.Zero(t, list.Data[0].CategoryID)
+		assert.Empty(t, list.Data[0].CategoryName)
+	}
+}
@@ -0,0 +1,88 @@
+package products
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/categories"
+)
+
+// ErrNotFound повертається репозиторієм, якщо продукту з таким ID не існує
+var ErrNotFound = errors.New("products: not found")
+
+// ErrVariantNotFound повертається репозиторієм, якщо у продукту немає варіанта з таким ID
+var ErrVariantNotFound = errors.New("products: variant not found")
+
+// ErrDuplicateSKU повертається репозиторієм, якщо артикул уже зайнятий іншим варіантом
+var ErrDuplicateSKU = errors.New("products: duplicate sku")
+
+// ErrImageNotFound повертається репозиторієм, якщо у продукту немає зображення з таким ID
+var ErrImageNotFound = errors.New("products: image not found")
+
+// ErrInsufficientStock повертається, якщо залишку продукту не вистачає для списання
+var ErrInsufficientStock = errors.New("products: insufficient stock")
+
+// ProductRepository описує сховище продуктів, з яким працює ProductService
+type ProductRepository interface {
+	// List повертає до f.Page.Limit+1 продуктів, що задовольняють фільтр, після
+	// межі курсора в порядку f.Sort (для зворотного курсора — перед межею, у
+	// зворотному порядку) разом з даними їхніх категорій
+	List(ctx context.Context, f Filter) ([]ProductWithCategoryWithoutDates, error)
+	// Facets підраховує продукти, що задовольняють фільтр, за категоріями та за
+	// ціновими діапазонами з межами bounds; сортування та сторінка ігноруються
+	Facets(ctx context.Context, f Filter, bounds []decimal.Decimal) (Facets, error)
+	// Get повертає продукт за ID або ErrNotFound
+	Get(ctx context.Context, id int) (Product, error)
+	// Create зберігає новий продукт та заповнює ID і дати
+	Create(ctx context.Context, p *Product) error
+	// Update перезаписує дані продукту за ID або повертає ErrNotFound
+	Update(ctx context.Context, id int, p *Product) error
+	// Delete видаляє продукт за ID разом з його опціями, варіантами та записами
+	// зображень або повертає ErrNotFound
+	Delete(ctx context.Context, id int) error
+	// AdjustStock змінює залишок продукту на delta. Залишок ніколи не стає
+	// від'ємним: у такому разі повертається ErrInsufficientStock
+	AdjustStock(ctx context.Context, id, delta int) error
+
+	// Options повертає типи опцій продукту в заданому порядку
+	Options(ctx context.Context, productID int) ([]Option, error)
+	// SetOptions замінює типи опцій продукту
+	SetOptions(ctx context.Context, productID int, options []Option) error
+	// Variants повертає варіанти продукту за зростанням ID
+	Variants(ctx context.Context, productID int) ([]Variant, error)
+	// GetVariant повертає варіант продукту за ID або ErrVariantNotFound
+	GetVariant(ctx context.Context, productID, id int) (Variant, error)
+	// CreateVariant зберігає новий варіант v.ProductID та заповнює ID і дати;
+	// повертає ErrDuplicateSKU
+	CreateVariant(ctx context.Context, v *Variant) error
+	// UpdateVariant перезаписує варіант продукту v.ProductID за ID; повертає
+	// ErrVariantNotFound або ErrDuplicateSKU
+	UpdateVariant(ctx context.Context, id int, v *Variant) error
+	// DeleteVariant видаляє варіант продукту за ID або повертає ErrVariantNotFound
+	DeleteVariant(ctx context.Context, productID, id int) error
+
+	// Images повертає зображення продукту в порядку галереї
+	Images(ctx context.Context, productID int) ([]Image, error)
+	// PrimaryImages повертає головні зображення продуктів за їхніми ID
+	PrimaryImages(ctx context.Context, productIDs []int) (map[int]Image, error)
+	// GetImage повертає зображення продукту за ID або ErrImageNotFound
+	GetImage(ctx context.Context, productID, id int) (Image, error)
+	// CreateImage додає зображення в кінець галереї img.ProductID та заповнює
+	// ID, позицію і дату; перше зображення продукту стає головним
+	CreateImage(ctx context.Context, img *Image) error
+	// ReorderImages задає позиції зображень продукту в порядку ids
+	ReorderImages(ctx context.Context, productID int, ids []int) error
+	// SetPrimaryImage робить зображення головним або повертає ErrImageNotFound
+	SetPrimaryImage(ctx context.Context, productID, id int) error
+	// DeleteImage видаляє зображення або повертає ErrImageNotFound; якщо воно було
+	// головним, головним стає перше з решти
+	DeleteImage(ctx context.Context, productID, id int) error
+}
+
+// CategoryReader надає доступ до категорій для перевірки CategoryID та
+// для заповнення даних категорій у репозиторії в пам'яті
+type CategoryReader interface {
+	Get(ctx context.Context, id int) (categories.Category, error)
+}
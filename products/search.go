@@ -0,0 +1,320 @@
+package products
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Sort — поле сортування списку продуктів
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// SortFields — поля, за якими дозволено сортувати список продуктів
+var SortFields = []string{"id", "name", "price", "stock", "created_at"}
+
+// Filter обмежує та впорядковує вибірку продуктів; нульові поля не фільтрують
+type Filter struct {
+	// Query — слова пошуку; кожне має зустрічатися в назві або описі
+	Query       string
+	CategoryIDs []int
+	// MinPrice та MaxPrice обмежують ціну включно
+	MinPrice *decimal.Decimal
+	MaxPrice *decimal.Decimal
+	// InStock залишає лише продукти з ненульовим залишком
+	InStock bool
+	// From та To обмежують дату створення: From включно, To — ні
+	From time.Time
+	To   time.Time
+	// Sort задає порядок; за рівності значень продукти впорядковуються за ID
+	Sort []Sort
+	// Page — розмір сторінки та курсор її межі
+	Page pagination.Query
+}
+
+// DefaultPriceBuckets — межі цінових діапазонів фасету за замовчуванням
+var DefaultPriceBuckets = []decimal.Decimal{
+	decimal.NewFromInt(100), decimal.NewFromInt(500), decimal.NewFromInt(1000), decimal.NewFromInt(5000),
+}
+
+// CategoryFacet — кількість продуктів категорії серед знайдених
+type CategoryFacet struct {
+	CategoryID int    `json:"categoryID"`
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+}
+
+// PriceFacet — кількість знайдених продуктів із ціною в [Min, Max); Max nil — без верхньої межі
+type PriceFacet struct {
+	Min   decimal.Decimal  `json:"min"`
+	Max   *decimal.Decimal `json:"max"`
+	Count int              `json:"count"`
+}
+
+// Facets — підсумки за категоріями та ціновими діапазонами для поточного фільтра
+type Facets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Prices     []PriceFacet    `json:"prices"`
+}
+
+// newPriceFacets створює порожні цінові діапазони з меж bounds
+func newPriceFacets(bounds []decimal.Decimal) []PriceFacet {
+	facets := make([]PriceFacet, 0, len(bounds)+1)
+	min := decimal.Zero
+	for i := range bounds {
+		facets = append(facets, PriceFacet{Min: min, Max: &bounds[i]})
+		min = bounds[i]
+	}
+	return append(facets, PriceFacet{Min: min})
+}
+
+// priceBucket повертає індекс цінового діапазону для ціни
+func priceBucket(bounds []decimal.Decimal, price decimal.Decimal) int {
+	for i, bound := range bounds {
+		if price.LessThan(bound) {
+			return i
+		}
+	}
+	return len(bounds)
+}
+
+// terms повертає слова пошуку в нижньому регістрі
+func (f Filter) terms() []string {
+	return strings.Fields(strings.ToLower(f.Query))
+}
+
+// matches повідомляє, чи задовольняє продукт фільтр
+func (f Filter) matches(p Product) bool {
+	text := strings.ToLower(p.Name + "\n" + p.Description)
+	for _, term := range f.terms() {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	if len(f.CategoryIDs) > 0 {
+		found := false
+		for _, id := range f.CategoryIDs {
+			found = found || id == p.CategoryID
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.MinPrice != nil && p.Price.Amount.LessThan(*f.MinPrice) {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price.Amount.GreaterThan(*f.MaxPrice) {
+		return false
+	}
+	if f.InStock && p.StockQuantity <= 0 {
+		return false
+	}
+	if !f.From.IsZero() && p.Created_at.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !p.Created_at.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// less порівнює продукти в порядку f.Sort
+func (f Filter) less(a, b Product) bool {
+	for _, s := range f.Sort {
+		var c int
+		switch s.Field {
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "price":
+			c = a.Price.Amount.Cmp(b.Price.Amount)
+		case "stock":
+			c = a.StockQuantity - b.StockQuantity
+		case "created_at":
+			c = a.Created_at.Compare(b.Created_at)
+		case "id":
+			c = a.ID - b.ID
+		}
+		if c != 0 {
+			return (c < 0) != s.Desc
+		}
+	}
+	return a.ID < b.ID
+}
+
+// sortProducts впорядковує продукти в порядку f.Sort
+func (f Filter) sortProducts(products []Product) {
+	sort.Slice(products, func(i, j int) bool { return f.less(products[i], products[j]) })
+}
+
+// parseDate розбирає дату (2006-01-02) або мітку часу RFC 3339; порожнє значення — нульовий час
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// parsePrice розбирає невід'ємну ціну; порожнє значення — nil
+func parsePrice(value string) (*decimal.Decimal, bool) {
+	if value == "" {
+		return nil, true
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil || d.IsNegative() {
+		return nil, false
+	}
+	return &d, true
+}
+
+// sortKey повертає значення полів сортування продукту для курсора
+func (f Filter) sortKey(p Product) []string {
+	values := make([]string, 0, len(f.Sort))
+	for _, s := range f.Sort {
+		switch s.Field {
+		case "id":
+			values = append(values, strconv.Itoa(p.ID))
+		case "name":
+			values = append(values, p.Name)
+		case "price":
+			values = append(values, p.Price.Amount.String())
+		case "stock":
+			values = append(values, strconv.Itoa(p.StockQuantity))
+		case "created_at":
+			values = append(values, p.Created_at.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return values
+}
+
+// boundary відновлює з курсора межовий продукт, за яким порівнюються продукти сторінки
+func (f Filter) boundary(c pagination.Cursor) (Product, bool) {
+	if len(c.Values) != len(f.Sort) {
+		return Product{}, false
+	}
+	p := Product{ID: c.ID}
+	for i, s := range f.Sort {
+		var err error
+		value := c.Values[i]
+		switch s.Field {
+		case "id":
+			_, err = strconv.Atoi(value)
+		case "name":
+			p.Name = value
+		case "price":
+			p.Price.Amount, err = decimal.NewFromString(value)
+		case "stock":
+			p.StockQuantity, err = strconv.Atoi(value)
+		case "created_at":
+			p.Created_at, err = time.Parse(time.RFC3339Nano, value)
+		}
+		if err != nil {
+			return Product{}, false
+		}
+	}
+	return p, true
+}
+
+// cursor повертає курсор, межею якого є рядок списку
+func (f Filter) cursor(item ProductWithCategoryWithoutDates) pagination.Cursor {
+	p := Product{
+		ID:            item.ProductID,
+		Name:          item.ProductName,
+		Price:         item.ProductPrice,
+		StockQuantity: item.StockQuantity,
+		Created_at:    item.ProductCreatedAt,
+	}
+	return pagination.Cursor{ID: p.ID, Values: f.sortKey(p)}
+}
+
+// sortSignature повертає порядок сортування у вигляді параметра sort
+func (f Filter) sortSignature() string {
+	fields := make([]string, 0, len(f.Sort))
+	for _, s := range f.Sort {
+		if s.Desc {
+			fields = append(fields, "-"+s.Field)
+		} else {
+			fields = append(fields, s.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// ParseFilter розбирає параметри пошуку списку продуктів:
+// q, category_id (можна повторювати або перелічити через кому), min_price,
+// max_price, in_stock, created_from, created_to, sort (наприклад
+// sort=price,-created_at) та параметри сторінки limit, cursor і total.
+func ParseFilter(query url.Values) (Filter, error) {
+	f := Filter{Query: strings.TrimSpace(query.Get("q"))}
+	v := validate.New()
+	v.MaxLen("q", f.Query, 200)
+
+	for _, value := range query["category_id"] {
+		for _, part := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			v.Check(err == nil && id > 0, "category_id", "must be a list of positive integers")
+			f.CategoryIDs = append(f.CategoryIDs, id)
+		}
+	}
+
+	var ok bool
+	f.MinPrice, ok = parsePrice(query.Get("min_price"))
+	v.Check(ok, "min_price", "must be a non-negative number")
+	f.MaxPrice, ok = parsePrice(query.Get("max_price"))
+	v.Check(ok, "max_price", "must be a non-negative number")
+	if f.MinPrice != nil && f.MaxPrice != nil {
+		v.Check(f.MinPrice.LessThanOrEqual(*f.MaxPrice), "max_price", "must not be less than min_price")
+	}
+
+	if value := query.Get("in_stock"); value != "" {
+		inStock, err := strconv.ParseBool(value)
+		v.Check(err == nil, "in_stock", "must be a boolean")
+		f.InStock = inStock
+	}
+
+	var err error
+	f.From, err = parseDate(query.Get("created_from"))
+	v.Check(err == nil, "created_from", "must be a date (2006-01-02) or RFC 3339 timestamp")
+	f.To, err = parseDate(query.Get("created_to"))
+	v.Check(err == nil, "created_to", "must be a date (2006-01-02) or RFC 3339 timestamp")
+
+	if value := query.Get("sort"); value != "" {
+		seen := make(map[string]bool)
+		for _, part := range strings.Split(value, ",") {
+			s := Sort{Field: strings.TrimSpace(part)}
+			if strings.HasPrefix(s.Field, "-") {
+				s.Field, s.Desc = s.Field[1:], true
+			}
+			known := false
+			for _, field := range SortFields {
+				known = known || field == s.Field
+			}
+			v.Check(known, "sort", "fields must be one of "+strings.Join(SortFields, ", ")+", optionally prefixed with '-'")
+			v.Check(!seen[s.Field], "sort", "must not repeat fields")
+			seen[s.Field] = true
+			f.Sort = append(f.Sort, s)
+		}
+	}
+
+	f.Page = pagination.Parse(query, v, f.sortSignature())
+	if f.Page.Cursor != nil && v.Valid() {
+		_, ok := f.boundary(*f.Page.Cursor)
+		v.Check(ok, "cursor", "is malformed")
+	}
+
+	if err := v.QueryErr(); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
@@ -0,0 +1,121 @@
+package products_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// seedCatalog створює дві категорії та п'ять продуктів
+func seedCatalog(t *testing.T, cats categories.CategoryRepository, repo products.ProductRepository) {
+	ctx := context.Background()
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+	assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Lamps"}))
+	for _, p := range []products.Product{
+		{Name: "Go Programming", Description: "Learn Go", Price: money.MustParse("450", "UAH"), StockQuantity: 3, CategoryID: 1},
+		{Name: "The C Language", Description: "A classic 100% guide", Price: money.MustParse("120.50", "UAH"), CategoryID: 1},
+		{Name: "Desk lamp", Description: "LED, good for programming at night", Price: money.MustParse("999.99", "UAH"), StockQuantity: 10, CategoryID: 2},
+		{Name: "Floor Lamp", Description: "Tall", Price: money.MustParse("5000", "UAH"), StockQuantity: 1, CategoryID: 2},
+		{Name: "Notebook", Description: "For notes_and_ideas", Price: money.MustParse("99.99", "UAH"), StockQuantity: 7, CategoryID: 1},
+	} {
+		assert.NoError(t, repo.Create(ctx, &p))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	memCats := categories.NewMemoryRepository()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	for name, repos := range map[string]struct {
+		cats categories.CategoryRepository
+		repo products.ProductRepository
+	}{
+		"memory": {memCats, products.NewMemoryRepository(memCats)},
+		"sqlite": {sqlCats, products.NewSQLRepository(db, d)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			seedCatalog(t, repos.cats, repos.repo)
+			r := newRouter(&products.ProductService{Repo: repos.repo})
+
+			search := func(query string) products.ProductList {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest("GET", "/products?"+query, nil))
+				assert.Equal(t, http.StatusOK, rr.Code, query)
+				var list products.ProductList
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+				return list
+			}
+			ids := func(query string) []int {
+				got := []int{}
+				for _, p := range search(query).Data {
+					got = append(got, p.ProductID)
+				}
+				return got
+			}
+
+			assert.Equal(t, []int{1, 3}, ids("q=PROGRAMMING"))
+			// Символи шаблону LIKE у запиті шукаються буквально
+			assert.Equal(t, []int{5}, ids("q=_"))
+			assert.Equal(t, []int{2}, ids("q=100%25"))
+			assert.Equal(t, []int{4, 3}, ids("category_id=2&in_stock=true&sort=-price"))
+			// Ціна порівнюється як число, а не як рядок
+			assert.Equal(t, []int{2, 1, 3, 4}, ids("min_price=100&sort=price"))
+			assert.Equal(t, []int{2, 1}, ids("min_price=100&max_price=450&sort=stock"))
+
+			// Курсори гортають сторінки в обидва боки в межах того самого порядку
+			first := search("limit=2&sort=-price&total=true")
+			assert.Equal(t, 5, *first.Page.Total)
+			assert.Empty(t, first.Page.Prev)
+			second := search("limit=2&sort=-price&cursor=" + first.Page.Next)
+			assert.Equal(t, []int{1, 2}, []int{second.Data[0].ProductID, second.Data[1].ProductID})
+			assert.Equal(t, []int{5}, ids("limit=2&sort=-price&cursor="+second.Page.Next))
+			assert.Equal(t, []int{4, 3}, ids("limit=2&sort=-price&cursor="+second.Page.Prev))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest("GET", "/products?limit=2&sort=price&cursor="+first.Page.Next, nil))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, []int{1, 2, 3, 4, 5}, ids("created_from=2000-01-01"))
+			assert.Equal(t, []int{}, ids("created_to=2000-01-01"))
+
+			facets := search("").Facets
+			assert.Equal(t, []products.CategoryFacet{{CategoryID: 1, Name: "Books", Count: 3}, {CategoryID: 2, Name: "Lamps", Count: 2}}, facets.Categories)
+			var counts []int
+			for _, bucket := range facets.Prices {
+				counts = append(counts, bucket.Count)
+			}
+			assert.Equal(t, []int{1, 2, 1, 0, 1}, counts)
+			assert.Nil(t, facets.Prices[4].Max)
+			assert.Equal(t, "5000", facets.Prices[4].Min.String())
+
+			// Фасети враховують поточний фільтр
+			facets = search("q=lamp").Facets
+			assert.Equal(t, []products.CategoryFacet{{CategoryID: 2, Name: "Lamps", Count: 2}}, facets.Categories)
+
+			rr = httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest("GET", "/products?sort=price,bogus&min_price=-1&category_id=x&in_stock=maybe", nil))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			for _, field := range []string{"sort", "min_price", "category_id", "in_stock"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+		})
+	}
+}
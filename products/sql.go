@@ -0,0 +1,866 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// SQLRepository зберігає продукти у таблиці products SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+// priceColumn приводить ціну до числа: SQLite зберігає її як TEXT, і без
+// приведення порівняння та сортування були б рядковими
+const priceColumn = "CAST(products.price AS DECIMAL(19, 4))"
+
+// sortColumns відповідає полям SortFields
+var sortColumns = map[string]string{
+	"id":         "products.id",
+	"name":       "products.name",
+	"price":      priceColumn,
+	"stock":      "products.stock_quantity",
+	"created_at": "products.created_at",
+}
+
+// likeEscaper екранує символи шаблону LIKE; '!' однаково працює як ESCAPE в усіх діалектах
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// where повертає умову WHERE для фільтра разом з її аргументами
+func (m *SQLRepository) where(f Filter) (string, []interface{}) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	for _, term := range f.terms() {
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		where = append(where, "(LOWER(products.name) LIKE ? ESCAPE '!' OR LOWER(products.description) LIKE ? ESCAPE '!')")
+		args = append(args, pattern, pattern)
+	}
+	if len(f.CategoryIDs) > 0 {
+		where = append(where, "products.category_id IN (?"+strings.Repeat(", ?", len(f.CategoryIDs)-1)+")")
+		for _, id := range f.CategoryIDs {
+			args = append(args, id)
+		}
+	}
+	if f.MinPrice != nil {
+		where = append(where, priceColumn+" >= CAST(? AS DECIMAL(19, 4))")
+		args = append(args, f.MinPrice.String())
+	}
+	if f.MaxPrice != nil {
+		where = append(where, priceColumn+" <= CAST(? AS DECIMAL(19, 4))")
+		args = append(args, f.MaxPrice.String())
+	}
+	if f.InStock {
+		where = append(where, "products.stock_quantity > 0")
+	}
+	if !f.From.IsZero() {
+		where = append(where, "products.created_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		where = append(where, "products.created_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	if len(where) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// keyValues повертає аргументи ключа сортування межового продукту: поля f.Sort та ID
+func (m *SQLRepository) keyValues(f Filter, boundary Product) []interface{} {
+	values := make([]interface{}, 0, len(f.Sort)+1)
+	for _, s := range f.Sort {
+		switch s.Field {
+		case "id":
+			values = append(values, boundary.ID)
+		case "name":
+			values = append(values, boundary.Name)
+		case "price":
+			values = append(values, boundary.Price.Amount.String())
+		case "stock":
+			values = append(values, boundary.StockQuantity)
+		case "created_at":
+			values = append(values, boundary.Created_at.UTC())
+		}
+	}
+	return append(values, boundary.ID)
+}
+
+func (m *SQLRepository) List(ctx context.Context, f Filter) ([]ProductWithCategoryWithoutDates, error) {
+	query := `
+		SELECT products.id AS product_id, products.name AS product_name,
+			   products.description AS product_description, products.price AS product_price,
+			   products.currency AS product_currency,
+			   products.stock_quantity AS product_stockQuantity, products.category_id AS product_category_id,
+			   COALESCE(categories.id, 0) AS category_id, COALESCE(categories.name, '') AS category_name,
+			   COALESCE(categories.description, '') AS category_description, products.created_at AS product_created_at
+		FROM products
+		LEFT JOIN categories ON products.category_id = categories.id
+	`
+	where, args := m.where(f)
+
+	key := make([]pagination.Column, 0, len(f.Sort)+1)
+	for _, s := range f.Sort {
+		c := pagination.Column{Expr: sortColumns[s.Field], Desc: s.Desc}
+		if s.Field == "price" {
+			c.Param = "CAST(? AS DECIMAL(19, 4))"
+		}
+		key = append(key, c)
+	}
+	key = append(key, pagination.Column{Expr: "products.id"})
+
+	if f.Page.Cursor != nil {
+		boundary, ok := f.boundary(*f.Page.Cursor)
+		if !ok {
+			return nil, errors.New("products: malformed cursor")
+		}
+		keyset, keyArgs := pagination.Keyset(key, m.keyValues(f, boundary), f.Page.Backward())
+		if where == "" {
+			where = " WHERE " + keyset
+		} else {
+			where += " AND " + keyset
+		}
+		args = append(args, keyArgs...)
+	}
+	query += where + " ORDER BY " + pagination.OrderBy(key, f.Page.Backward()) + " LIMIT ?"
+	args = append(args, f.Page.Limit+1)
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []ProductWithCategoryWithoutDates{}
+	for rows.Next() {
+		var p ProductWithCategoryWithoutDates
+		if err := rows.Scan(
+			&p.ProductID,
+			&p.ProductName,
+			&p.ProductDescription,
+			&p.ProductPrice.Amount,
+			&p.ProductPrice.Currency,
+			&p.StockQuantity,
+			&p.ProductCategoryID,
+			&p.CategoryID,
+			&p.CategoryName,
+			&p.CategoryDescription,
+			&p.ProductCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (m *SQLRepository) Facets(ctx context.Context, f Filter, bounds []decimal.Decimal) (Facets, error) {
+	where, args := m.where(f)
+	facets := Facets{Categories: []CategoryFacet{}, Prices: newPriceFacets(bounds)}
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(`
+		SELECT products.category_id, COALESCE(categories.name, ''), COUNT(*)
+		FROM products
+		LEFT JOIN categories ON products.category_id = categories.id`+where+`
+		GROUP BY products.category_id, categories.name
+		ORDER BY products.category_id`), args...)
+	if err != nil {
+		return Facets{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c CategoryFacet
+		if err := rows.Scan(&c.CategoryID, &c.Name, &c.Count); err != nil {
+			return Facets{}, err
+		}
+		facets.Categories = append(facets.Categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return Facets{}, err
+	}
+	rows.Close()
+
+	// Номер діапазону обчислюється в базі: bucket = i, якщо ціна менша за bounds[i]
+	bucket := "CASE"
+	var bucketArgs []interface{}
+	for i, bound := range bounds {
+		bucket += " WHEN " + priceColumn + " < CAST(? AS DECIMAL(19, 4)) THEN " + strconv.Itoa(i)
+		bucketArgs = append(bucketArgs, bound.String())
+	}
+	bucket += " ELSE " + strconv.Itoa(len(bounds)) + " END"
+
+	rows, err = m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT "+bucket+" AS bucket, COUNT(*) FROM products"+where+" GROUP BY bucket"),
+		append(bucketArgs, args...)...)
+	if err != nil {
+		return Facets{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i, count int
+		if err := rows.Scan(&i, &count); err != nil {
+			return Facets{}, err
+		}
+		if i >= 0 && i < len(facets.Prices) {
+			facets.Prices[i].Count = count
+		}
+	}
+	return facets, rows.Err()
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (Product, error) {
+	var product Product
+	query := `
+		SELECT id, name, description, price, currency, stock_quantity, category_id, weight, length, width, height, created_at, updated_at
+		FROM products
+		WHERE id = ?
+	`
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind(query), id).Scan(&product.ID, &product.Name, &product.Description, &product.Price.Amount, &product.Price.Currency, &product.StockQuantity, &product.CategoryID,
+		&product.Weight, &product.Length, &product.Width, &product.Height, &product.Created_at, &product.Updated_at)
+	if err == sql.ErrNoRows {
+		return Product{}, ErrNotFound
+	}
+	return product, err
+}
+
+func (m *SQLRepository) Create(ctx context.Context, p *Product) error {
+	query := `
+		INSERT INTO products (name, description, price, currency, stock_quantity, category_id, weight, length, width, height, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now().UTC()
+	productID, err := m.Dialect.InsertID(ctx, m.DB, query, p.Name, p.Description, p.Price.Amount, p.Price.Currency, p.StockQuantity, p.CategoryID,
+		p.Weight, p.Length, p.Width, p.Height, now, now)
+	if err != nil {
+		return err
+	}
+	p.ID = int(productID)
+	p.Created_at = now
+	p.Updated_at = now
+	return nil
+}
+
+func (m *SQLRepository) Update(ctx context.Context, id int, p *Product) error {
+	query := `
+		UPDATE products
+		SET
+			name = ?,
+			description = ?,
+			price = ?,
+			currency = ?,
+			stock_quantity = ?,
+			category_id = ?,
+			weight = ?,
+			length = ?,
+			width = ?,
+			height = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+	now := time.Now().UTC()
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind(query),
+		p.Name,
+		p.Description,
+		p.Price.Amount,
+		p.Price.Currency,
+		p.StockQuantity,
+		p.CategoryID,
+		p.Weight,
+		p.Length,
+		p.Width,
+		p.Height,
+		now,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	// Перечитуємо рядок, щоб відповідь містила збережений created_at
+	stored, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	*p = stored
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM products WHERE id=?"), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if err := m.deleteOptions(ctx, tx, id); err != nil {
+		return err
+	}
+	for _, query := range []string{
+		"DELETE FROM product_variant_options WHERE variant_id IN (SELECT id FROM product_variants WHERE product_id = ?)",
+		"DELETE FROM product_variants WHERE product_id = ?",
+		"DELETE FROM product_image_thumbnails WHERE image_id IN (SELECT id FROM product_images WHERE product_id = ?)",
+		"DELETE FROM product_images WHERE product_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind(query), id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *SQLRepository) AdjustStock(ctx context.Context, id, delta int) error {
+	// Умова в WHERE виконується атомарно разом з оновленням, тож одночасні
+	// покупці не можуть списати більше, ніж є на складі
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE products SET stock_quantity = stock_quantity + ?, updated_at = ? WHERE id = ? AND stock_quantity + ? >= 0"),
+		delta, time.Now().UTC(), id, delta)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		if _, err := m.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func (m *SQLRepository) Options(ctx context.Context, productID int) ([]Option, error) {
+	query := `
+		SELECT product_options.id, product_options.name, product_option_values.value
+		FROM product_options
+		JOIN product_option_values ON product_option_values.option_id = product_options.id
+		WHERE product_options.product_id = ?
+		ORDER BY product_options.position, product_option_values.position
+	`
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := []Option{}
+	lastID := 0
+	for rows.Next() {
+		var (
+			id          int
+			name, value string
+		)
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return nil, err
+		}
+		if id != lastID {
+			options = append(options, Option{Name: name})
+			lastID = id
+		}
+		last := &options[len(options)-1]
+		last.Values = append(last.Values, value)
+	}
+	return options, rows.Err()
+}
+
+// deleteOptions видаляє типи опцій продукту та їхні значення в межах транзакції
+func (m *SQLRepository) deleteOptions(ctx context.Context, tx *sql.Tx, productID int) error {
+	for _, query := range []string{
+		"DELETE FROM product_option_values WHERE option_id IN (SELECT id FROM product_options WHERE product_id = ?)",
+		"DELETE FROM product_options WHERE product_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind(query), productID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SQLRepository) SetOptions(ctx context.Context, productID int, options []Option) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.deleteOptions(ctx, tx, productID); err != nil {
+		return err
+	}
+	for i, o := range options {
+		optionID, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO product_options (product_id, name, position) VALUES (?, ?, ?)", productID, o.Name, i)
+		if err != nil {
+			return err
+		}
+		for j, value := range o.Values {
+			if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO product_option_values (option_id, value, position) VALUES (?, ?, ?)"), optionID, value, j); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+const variantColumns = "id, product_id, sku, barcode, price, currency, stock_quantity, created_at, updated_at"
+
+// scanVariant читає рядок product_variants без значень опцій
+func scanVariant(row interface{ Scan(...interface{}) error }) (Variant, error) {
+	var (
+		v        Variant
+		price    decimal.NullDecimal
+		currency sql.NullString
+	)
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Barcode, &price, &currency, &v.StockQuantity, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return Variant{}, err
+	}
+	if price.Valid {
+		p := money.New(price.Decimal, currency.String)
+		v.Price = &p
+	}
+	v.Options = map[string]string{}
+	return v, nil
+}
+
+// variantOptions повертає значення опцій варіантів, відібраних підзапитом ID
+func (m *SQLRepository) variantOptions(ctx context.Context, ids string, args ...interface{}) (map[int]map[string]string, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT variant_id, name, value FROM product_variant_options WHERE variant_id IN ("+ids+")"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byVariant := make(map[int]map[string]string)
+	for rows.Next() {
+		var (
+			id          int
+			name, value string
+		)
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return nil, err
+		}
+		if byVariant[id] == nil {
+			byVariant[id] = make(map[string]string)
+		}
+		byVariant[id][name] = value
+	}
+	return byVariant, rows.Err()
+}
+
+func (m *SQLRepository) Variants(ctx context.Context, productID int) ([]Variant, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT "+variantColumns+" FROM product_variants WHERE product_id = ? ORDER BY id"), productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []Variant{}
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	byVariant, err := m.variantOptions(ctx, "SELECT id FROM product_variants WHERE product_id = ?", productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		if options, ok := byVariant[variants[i].ID]; ok {
+			variants[i].Options = options
+		}
+	}
+	return variants, nil
+}
+
+func (m *SQLRepository) GetVariant(ctx context.Context, productID, id int) (Variant, error) {
+	v, err := scanVariant(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+variantColumns+" FROM product_variants WHERE id = ? AND product_id = ?"), id, productID))
+	if err == sql.ErrNoRows {
+		return Variant{}, ErrVariantNotFound
+	}
+	if err != nil {
+		return Variant{}, err
+	}
+
+	byVariant, err := m.variantOptions(ctx, "?", id)
+	if err != nil {
+		return Variant{}, err
+	}
+	if options, ok := byVariant[id]; ok {
+		v.Options = options
+	}
+	return v, nil
+}
+
+// skuTaken повідомляє, чи зайнятий артикул іншим варіантом
+func (m *SQLRepository) skuTaken(ctx context.Context, sku string, exceptID int) (bool, error) {
+	var n int
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM product_variants WHERE sku = ? AND id <> ?"), sku, exceptID).Scan(&n)
+	return n > 0, err
+}
+
+// variantPrice повертає ціну та валюту варіанта для запису в базу
+func variantPrice(v *Variant) (decimal.NullDecimal, sql.NullString) {
+	if v.Price == nil {
+		return decimal.NullDecimal{}, sql.NullString{}
+	}
+	return decimal.NullDecimal{Decimal: v.Price.Amount, Valid: true}, sql.NullString{String: v.Price.Currency, Valid: true}
+}
+
+// insertVariantOptions зберігає значення опцій варіанта в межах транзакції
+func (m *SQLRepository) insertVariantOptions(ctx context.Context, tx *sql.Tx, variantID int, options map[string]string) error {
+	for name, value := range options {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO product_variant_options (variant_id, name, value) VALUES (?, ?, ?)"), variantID, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SQLRepository) CreateVariant(ctx context.Context, v *Variant) error {
+	if taken, err := m.skuTaken(ctx, v.SKU, 0); err != nil || taken {
+		if taken {
+			return ErrDuplicateSKU
+		}
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	price, currency := variantPrice(v)
+	id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO product_variants (product_id, sku, barcode, price, currency, stock_quantity, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		v.ProductID, v.SKU, v.Barcode, price, currency, v.StockQuantity, now, now)
+	if err != nil {
+		return err
+	}
+	if err := m.insertVariantOptions(ctx, tx, int(id), v.Options); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	v.ID = int(id)
+	v.CreatedAt = now
+	v.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) UpdateVariant(ctx context.Context, id int, v *Variant) error {
+	existing, err := m.GetVariant(ctx, v.ProductID, id)
+	if err != nil {
+		return err
+	}
+	if taken, err := m.skuTaken(ctx, v.SKU, id); err != nil || taken {
+		if taken {
+			return ErrDuplicateSKU
+		}
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	price, currency := variantPrice(v)
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE product_variants SET sku = ?, barcode = ?, price = ?, currency = ?, stock_quantity = ?, updated_at = ? WHERE id = ? AND product_id = ?"),
+		v.SKU, v.Barcode, price, currency, v.StockQuantity, now, id, v.ProductID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrVariantNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM product_variant_options WHERE variant_id = ?"), id); err != nil {
+		return err
+	}
+	if err := m.insertVariantOptions(ctx, tx, id, v.Options); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	v.ID = id
+	v.CreatedAt = existing.CreatedAt
+	v.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) DeleteVariant(ctx context.Context, productID, id int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM product_variants WHERE id = ? AND product_id = ?"), id, productID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrVariantNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM product_variant_options WHERE variant_id = ?"), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const imageColumns = "id, product_id, position, is_primary, content_type, width, height, size, storage_key, created_at"
+
+// queryImages виконує запит до product_images і додає до зображень їхні мініатюри
+func (m *SQLRepository) queryImages(ctx context.Context, where string, args ...interface{}) ([]Image, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT "+imageColumns+" FROM product_images WHERE "+where+" ORDER BY position, id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []Image{}
+	for rows.Next() {
+		var img Image
+		if err := rows.Scan(&img.ID, &img.ProductID, &img.Position, &img.Primary, &img.ContentType, &img.Width, &img.Height, &img.Size, &img.Key, &img.CreatedAt); err != nil {
+			return nil, err
+		}
+		img.Thumbnails = []Thumbnail{}
+		images = append(images, img)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return images, nil
+	}
+
+	thumbRows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind("SELECT image_id, name, content_type, width, height, storage_key FROM product_image_thumbnails WHERE image_id IN (SELECT id FROM product_images WHERE "+where+") ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer thumbRows.Close()
+
+	byImage := make(map[int][]Thumbnail)
+	for thumbRows.Next() {
+		var (
+			imageID int
+			t       Thumbnail
+		)
+		if err := thumbRows.Scan(&imageID, &t.Name, &t.ContentType, &t.Width, &t.Height, &t.Key); err != nil {
+			return nil, err
+		}
+		byImage[imageID] = append(byImage[imageID], t)
+	}
+	if err := thumbRows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range images {
+		images[i].Thumbnails = append(images[i].Thumbnails, byImage[images[i].ID]...)
+	}
+	return images, nil
+}
+
+func (m *SQLRepository) Images(ctx context.Context, productID int) ([]Image, error) {
+	return m.queryImages(ctx, "product_id = ?", productID)
+}
+
+func (m *SQLRepository) PrimaryImages(ctx context.Context, productIDs []int) (map[int]Image, error) {
+	primary := make(map[int]Image)
+	if len(productIDs) == 0 {
+		return primary, nil
+	}
+	args := make([]interface{}, 0, len(productIDs)+1)
+	for _, id := range productIDs {
+		args = append(args, id)
+	}
+	args = append(args, true)
+	images, err := m.queryImages(ctx, "product_id IN (?"+strings.Repeat(", ?", len(productIDs)-1)+") AND is_primary = ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		primary[img.ProductID] = img
+	}
+	return primary, nil
+}
+
+func (m *SQLRepository) GetImage(ctx context.Context, productID, id int) (Image, error) {
+	images, err := m.queryImages(ctx, "id = ? AND product_id = ?", id, productID)
+	if err != nil {
+		return Image{}, err
+	}
+	if len(images) == 0 {
+		return Image{}, ErrImageNotFound
+	}
+	return images[0], nil
+}
+
+func (m *SQLRepository) CreateImage(ctx context.Context, img *Image) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		count    int
+		position sql.NullInt64
+	)
+	err = tx.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*), MAX(position) FROM product_images WHERE product_id = ?"), img.ProductID).Scan(&count, &position)
+	if err != nil {
+		return err
+	}
+	img.Position = 0
+	if position.Valid {
+		img.Position = int(position.Int64) + 1
+	}
+	img.Primary = count == 0
+
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO product_images (product_id, position, is_primary, content_type, width, height, size, storage_key, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		img.ProductID, img.Position, img.Primary, img.ContentType, img.Width, img.Height, img.Size, img.Key, now)
+	if err != nil {
+		return err
+	}
+	for _, t := range img.Thumbnails {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO product_image_thumbnails (image_id, name, content_type, width, height, storage_key) VALUES (?, ?, ?, ?, ?, ?)"),
+			id, t.Name, t.ContentType, t.Width, t.Height, t.Key); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	img.ID = int(id)
+	img.CreatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) ReorderImages(ctx context.Context, productID int, ids []int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for position, id := range ids {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE product_images SET position = ? WHERE id = ? AND product_id = ?"), position, id, productID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *SQLRepository) SetPrimaryImage(ctx context.Context, productID, id int) error {
+	if _, err := m.GetImage(ctx, productID, id); err != nil {
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE product_images SET is_primary = ? WHERE product_id = ?"), false, productID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE product_images SET is_primary = ? WHERE id = ?"), true, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *SQLRepository) DeleteImage(ctx context.Context, productID, id int) error {
+	img, err := m.GetImage(ctx, productID, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM product_images WHERE id = ? AND product_id = ?"), id, productID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrImageNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM product_image_thumbnails WHERE image_id = ?"), id); err != nil {
+		return err
+	}
+	if img.Primary {
+		var next int
+		err := tx.QueryRowContext(ctx, m.Dialect.Rebind("SELECT id FROM product_images WHERE product_id = ? ORDER BY position, id LIMIT 1"), productID).Scan(&next)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE product_images SET is_primary = ? WHERE id = ?"), true, next)
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	return tx.Commit()
+}
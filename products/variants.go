@@ -0,0 +1,447 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// MaxOptions — найбільша кількість типів опцій продукту
+const MaxOptions = 3
+
+// Option — тип опції продукту (наприклад, розмір чи колір) з допустимими значеннями
+type Option struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// Variant — варіант продукту з власним артикулом, ціною та залишком
+type Variant struct {
+	ID        int    `json:"id"`
+	ProductID int    `json:"productID"`
+	SKU       string `json:"sku"`
+	// Barcode — штрихкод GTIN (EAN-8, UPC-A, EAN-13 або GTIN-14); порожній — немає
+	Barcode string `json:"barcode"`
+	// Price замінює ціну продукту; nil — діє ціна продукту
+	Price         *money.Money `json:"price"`
+	StockQuantity int          `json:"stockQuantity"`
+	// Options — значення кожного типу опцій продукту за його назвою
+	Options   map[string]string `json:"options"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// EffectivePrice повертає ціну варіанта з урахуванням ціни продукту
+func (v Variant) EffectivePrice(p Product) money.Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return p.Price
+}
+
+// ProductDetail — продукт разом з типами опцій, варіантами та зображеннями
+type ProductDetail struct {
+	Product
+	Options  []Option  `json:"options"`
+	Variants []Variant `json:"variants"`
+	Images   []Image   `json:"images"`
+}
+
+var barcodePattern = regexp.MustCompile(`^(\d{8}|\d{12,14})$`)
+
+// checkOptions додає до v правила для типів опцій продукту
+func checkOptions(v *validate.Validator, options []Option) {
+	v.Check(len(options) <= MaxOptions, "options", "must contain at most "+strconv.Itoa(MaxOptions)+" options")
+	names := make(map[string]bool)
+	for i, o := range options {
+		field := "options[" + strconv.Itoa(i) + "]"
+		v.Required(field+".name", o.Name)
+		v.MaxLen(field+".name", o.Name, 64)
+		v.Check(!names[strings.ToLower(o.Name)], field+".name", "must be unique")
+		names[strings.ToLower(o.Name)] = true
+
+		v.Check(len(o.Values) > 0, field+".values", "must contain at least one value")
+		values := make(map[string]bool)
+		for j, value := range o.Values {
+			valueField := field + ".values[" + strconv.Itoa(j) + "]"
+			v.Required(valueField, value)
+			v.MaxLen(valueField, value, 64)
+			v.Check(!values[strings.ToLower(value)], valueField, "must be unique")
+			values[strings.ToLower(value)] = true
+		}
+	}
+}
+
+// check додає до v правила для полів варіанта продукту p з типами опцій options
+func (vr Variant) check(v *validate.Validator, p Product, options []Option) {
+	v.Required("sku", vr.SKU)
+	v.MaxLen("sku", vr.SKU, 64)
+	v.Check(vr.Barcode == "" || barcodePattern.MatchString(vr.Barcode), "barcode", "must be a GTIN of 8, 12, 13 or 14 digits")
+	if vr.Price != nil {
+		v.Check(!vr.Price.IsNegative(), "price", "must not be negative")
+		v.Check(vr.Price.Exact(), "price", fmt.Sprintf("must have at most %d decimal places", money.Exponent(vr.Price.Currency)))
+		v.Check(vr.Price.Currency == p.Price.Currency, "price.currency", "must match the product currency "+p.Price.Currency)
+	}
+	v.NonNegative("stockQuantity", float64(vr.StockQuantity))
+
+	for _, o := range options {
+		value, ok := vr.Options[o.Name]
+		field := "options." + o.Name
+		if !ok {
+			v.Check(false, field, "is required")
+			continue
+		}
+		allowed := false
+		for _, a := range o.Values {
+			allowed = allowed || a == value
+		}
+		v.Check(allowed, field, "must be one of "+strings.Join(o.Values, ", "))
+	}
+	unknown := []string{}
+	for name := range vr.Options {
+		known := false
+		for _, o := range options {
+			known = known || o.Name == name
+		}
+		if !known {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		v.Check(false, "options."+name, "is not an option of the product")
+	}
+}
+
+// combination повертає ключ набору значень опцій варіанта в порядку options
+func (vr Variant) combination(options []Option) string {
+	values := make([]string, 0, len(options))
+	for _, o := range options {
+		values = append(values, vr.Options[o.Name])
+	}
+	return strings.Join(values, "\x00")
+}
+
+// validVariant повідомляє, чи відповідає варіант типам опцій options
+func validVariant(vr Variant, options []Option) bool {
+	if len(vr.Options) != len(options) {
+		return false
+	}
+	for _, o := range options {
+		allowed := false
+		for _, a := range o.Values {
+			allowed = allowed || a == vr.Options[o.Name]
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	errInvalidVariantID = problem.New(http.StatusBadRequest, "variantID must be a positive integer")
+	errDuplicateSKU     = problem.New(http.StatusConflict, "a variant with this sku already exists")
+	errDuplicateOptions = problem.New(http.StatusConflict, "a variant with these option values already exists")
+	errOptionsInUse     = problem.New(http.StatusConflict, "options must keep every existing variant valid; update or delete the variants first")
+)
+
+// productOf повертає продукт з URL-параметра {id}; у разі помилки відповідь уже надіслано
+func (s *ProductService) productOf(w http.ResponseWriter, r *http.Request) (Product, bool) {
+	productID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return Product{}, false
+	}
+	product, err := s.Repo.Get(r.Context(), productID)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "product %d not found", productID))
+		} else {
+			log.Println("Error querying product:", err)
+			problem.Error(w, r, err)
+		}
+		return Product{}, false
+	}
+	return product, true
+}
+
+// getURLParamVariantID повертає числовий ID з URL-параметра {variantID}
+func getURLParamVariantID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "variantID"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// detail доповнює продукт типами опцій, варіантами та зображеннями
+func (s *ProductService) detail(ctx context.Context, p Product) (ProductDetail, error) {
+	options, err := s.Repo.Options(ctx, p.ID)
+	if err != nil {
+		return ProductDetail{}, err
+	}
+	variants, err := s.Repo.Variants(ctx, p.ID)
+	if err != nil {
+		return ProductDetail{}, err
+	}
+	images, err := s.images(ctx, p.ID)
+	if err != nil {
+		return ProductDetail{}, err
+	}
+	return ProductDetail{Product: p, Options: options, Variants: variants, Images: images}, nil
+}
+
+// validateVariant нормалізує та перевіряє варіант продукту p, а також
+// унікальність його набору значень опцій серед інших варіантів продукту
+func (s *ProductService) validateVariant(ctx context.Context, p Product, vr *Variant, exceptID int) error {
+	vr.SKU = strings.TrimSpace(vr.SKU)
+	vr.Barcode = strings.TrimSpace(vr.Barcode)
+	if vr.Price != nil && vr.Price.Currency == "" {
+		vr.Price.Currency = p.Price.Currency
+	}
+	if vr.Options == nil {
+		vr.Options = map[string]string{}
+	}
+
+	options, err := s.Repo.Options(ctx, p.ID)
+	if err != nil {
+		log.Println("Error querying product options:", err)
+		return err
+	}
+	v := validate.New()
+	vr.check(v, p, options)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	variants, err := s.Repo.Variants(ctx, p.ID)
+	if err != nil {
+		log.Println("Error querying product variants:", err)
+		return err
+	}
+	for _, other := range variants {
+		if other.ID != exceptID && other.combination(options) == vr.combination(options) {
+			return errDuplicateOptions
+		}
+	}
+	return nil
+}
+
+// GetOptions повертає типи опцій продукту
+// GET /products/{id}/options
+func (s *ProductService) GetOptions(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	options, err := s.Repo.Options(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product options:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, options)
+}
+
+// SetOptions замінює типи опцій продукту. Зміна не повинна робити наявні
+// варіанти недійсними: можна додавати значення, але не прибирати використані
+// значення чи додавати нові типи, доки існують варіанти.
+// PUT /products/{id}/options
+func (s *ProductService) SetOptions(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+
+	var options []Option
+	if err := validate.DecodeJSON(r, &options); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if options == nil {
+		options = []Option{}
+	}
+	for i := range options {
+		options[i].Name = strings.TrimSpace(options[i].Name)
+		for j := range options[i].Values {
+			options[i].Values[j] = strings.TrimSpace(options[i].Values[j])
+		}
+	}
+	v := validate.New()
+	checkOptions(v, options)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	variants, err := s.Repo.Variants(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product variants:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	for _, vr := range variants {
+		if !validVariant(vr, options) {
+			problem.Write(w, r, errOptionsInUse)
+			return
+		}
+	}
+
+	if err := s.Repo.SetOptions(r.Context(), product.ID, options); err != nil {
+		log.Println("Error updating product options:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, options)
+}
+
+// GetVariants повертає варіанти продукту
+// GET /products/{id}/variants
+func (s *ProductService) GetVariants(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	variants, err := s.Repo.Variants(r.Context(), product.ID)
+	if err != nil {
+		log.Println("Error querying product variants:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, variants)
+}
+
+// GetVariant повертає варіант продукту за ID
+// GET /products/{id}/variants/{variantID}
+func (s *ProductService) GetVariant(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	variantID, ok := getURLParamVariantID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidVariantID)
+		return
+	}
+
+	variant, err := s.Repo.GetVariant(r.Context(), product.ID, variantID)
+	if err != nil {
+		if err == ErrVariantNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "variant %d of product %d not found", variantID, product.ID))
+		} else {
+			log.Println("Error querying product variant:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, variant)
+}
+
+// CreateVariant додає варіант продукту
+// POST /products/{id}/variants
+func (s *ProductService) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+
+	var variant Variant
+	if err := validate.DecodeJSON(r, &variant); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateVariant(r.Context(), product, &variant, 0); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	variant.ProductID = product.ID
+	if err := s.Repo.CreateVariant(r.Context(), &variant); err != nil {
+		if err == ErrDuplicateSKU {
+			problem.Write(w, r, errDuplicateSKU)
+		} else {
+			log.Println("Error inserting product variant:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, variant)
+}
+
+// UpdateVariant замінює варіант продукту за ID
+// PUT /products/{id}/variants/{variantID}
+func (s *ProductService) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	variantID, ok := getURLParamVariantID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidVariantID)
+		return
+	}
+
+	var variant Variant
+	if err := validate.DecodeJSON(r, &variant); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateVariant(r.Context(), product, &variant, variantID); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	variant.ProductID = product.ID
+	if err := s.Repo.UpdateVariant(r.Context(), variantID, &variant); err != nil {
+		switch err {
+		case ErrVariantNotFound:
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "variant %d of product %d not found", variantID, product.ID))
+		case ErrDuplicateSKU:
+			problem.Write(w, r, errDuplicateSKU)
+		default:
+			log.Println("Error updating product variant:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, variant)
+}
+
+// DeleteVariant видаляє варіант продукту за ID
+// DELETE /products/{id}/variants/{variantID}
+func (s *ProductService) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	product, ok := s.productOf(w, r)
+	if !ok {
+		return
+	}
+	variantID, ok := getURLParamVariantID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidVariantID)
+		return
+	}
+
+	if err := s.Repo.DeleteVariant(r.Context(), product.ID, variantID); err != nil {
+		if err == ErrVariantNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "variant %d of product %d not found", variantID, product.ID))
+		} else {
+			log.Println("Error deleting product variant:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,131 @@
+package products_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+func TestVariants(t *testing.T) {
+	memCats := categories.NewMemoryRepository()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	for name, repos := range map[string]struct {
+		cats categories.CategoryRepository
+		repo products.ProductRepository
+	}{
+		"memory": {memCats, products.NewMemoryRepository(memCats)},
+		"sqlite": {sqlCats, products.NewSQLRepository(db, d)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, repos.cats.Create(ctx, &categories.Category{Name: "Clothes"}))
+			for _, p := range []products.Product{
+				{Name: "T-shirt", Price: money.MustParse("300", "UAH"), CategoryID: 1},
+				{Name: "Cap", Price: money.MustParse("150", "UAH"), CategoryID: 1},
+			} {
+				assert.NoError(t, repos.repo.Create(ctx, &p))
+			}
+			r := newRouter(&products.ProductService{Repo: repos.repo})
+
+			do := func(method, path, body string) *httptest.ResponseRecorder {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+				return rr
+			}
+
+			rr := do("PUT", "/products/1/options", `[{"name":"size","values":["S","M","L"]},{"name":"colour","values":["black","white"]}]`)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			rr = do("PUT", "/products/1/options", `[{"name":"size","values":["S","S"]},{"name":"Size","values":[]}]`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			for _, field := range []string{"options[0].values[1]", "options[1].name", "options[1].values"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+
+			rr = do("POST", "/products/1/variants", `{"sku":"TS-M-BLK","barcode":"4820000000016","stockQuantity":5,"options":{"size":"M","colour":"black"}}`)
+			assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+			var variant products.Variant
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &variant))
+			assert.Equal(t, 1, variant.ProductID)
+			assert.Nil(t, variant.Price)
+
+			rr = do("POST", "/products/1/variants", `{"sku":"TS-L-WHT","price":{"amount":"320","currency":"UAH"},"stockQuantity":2,"options":{"size":"L","colour":"white"}}`)
+			assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+			// Некоректні значення опцій, штрихкод та валюта відхиляються з переліком полів
+			rr = do("POST", "/products/1/variants", `{"sku":"","barcode":"123","price":{"amount":"1","currency":"USD"},"options":{"size":"XXL","fit":"slim"}}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			for _, field := range []string{"sku", "barcode", "price.currency", "options.size", "options.colour", "options.fit"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+			rr = do("POST", "/products/1/variants", `{"sku":"TS-S-WHT","price":{"amount":"1.005","currency":"UAH"},"options":{"size":"S","colour":"white"}}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `{"field":"price","message":"must have at most 2 decimal places"}`)
+
+			// Артикул унікальний серед усіх продуктів, набір опцій — серед варіантів продукту
+			rr = do("POST", "/products/1/variants", `{"sku":"TS-M-BLK","options":{"size":"S","colour":"black"}}`)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			rr = do("POST", "/products/1/variants", `{"sku":"TS-M-BLK-2","options":{"size":"M","colour":"black"}}`)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			rr = do("POST", "/products/2/variants", `{"sku":"TS-M-BLK"}`)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+
+			rr = do("PUT", "/products/1/variants/1", `{"sku":"TS-M-BLK","stockQuantity":7,"options":{"size":"S","colour":"black"}}`)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			rr = do("PUT", "/products/2/variants/1", `{"sku":"TS-M-BLK"}`)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Зміна опцій не може зробити наявні варіанти недійсними
+			rr = do("PUT", "/products/1/options", `[{"name":"size","values":["M","L"]},{"name":"colour","values":["black","white"]}]`)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			rr = do("PUT", "/products/1/options", `[{"name":"size","values":["S","M","L","XL"]},{"name":"colour","values":["black","white"]}]`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			rr = do("GET", "/products/1", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var detail products.ProductDetail
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &detail))
+			assert.Equal(t, "T-shirt", detail.Name)
+			assert.Equal(t, []products.Option{{Name: "size", Values: []string{"S", "M", "L", "XL"}}, {Name: "colour", Values: []string{"black", "white"}}}, detail.Options)
+			assert.Len(t, detail.Variants, 2)
+			assert.Equal(t, map[string]string{"size": "S", "colour": "black"}, detail.Variants[0].Options)
+			assert.Equal(t, 7, detail.Variants[0].StockQuantity)
+			assert.Equal(t, "320.00 UAH", detail.Variants[1].EffectivePrice(detail.Product).String())
+			assert.Equal(t, "300.00 UAH", detail.Variants[0].EffectivePrice(detail.Product).String())
+
+			rr = do("DELETE", "/products/1/variants/2", "")
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			rr = do("GET", "/products/1/variants/2", "")
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Видалення продукту прибирає його варіанти, тож артикул знову вільний
+			rr = do("DELETE", "/products/1", "")
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			rr = do("GET", "/products/1/variants", "")
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			rr = do("POST", "/products/2/variants", `{"sku":"TS-M-BLK"}`)
+			assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore зберігає ролі у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	roles     map[string][]string
+	userRoles map[int]map[string]bool
+}
+
+// NewMemoryStore створює сховище з ролями DefaultRoles
+func NewMemoryStore() *MemoryStore {
+	roles := make(map[string][]string, len(DefaultRoles))
+	for role, perms := range DefaultRoles {
+		roles[role] = append([]string(nil), perms...)
+	}
+	return &MemoryStore{roles: roles, userRoles: make(map[int]map[string]bool)}
+}
+
+func (m *MemoryStore) UserRoles(ctx context.Context, userID int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var roles []string
+	for role := range m.userRoles[userID] {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+func (m *MemoryStore) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string(nil), m.roles[role]...), nil
+}
+
+func (m *MemoryStore) Assign(ctx context.Context, userID int, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.roles[role]; !ok {
+		return ErrUnknownRole
+	}
+	if m.userRoles[userID] == nil {
+		m.userRoles[userID] = make(map[string]bool)
+	}
+	m.userRoles[userID][role] = true
+	return nil
+}
+
+func (m *MemoryStore) Unassign(ctx context.Context, userID int, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.roles[role]; !ok {
+		return ErrUnknownRole
+	}
+	delete(m.userRoles[userID], role)
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package rbac перевіряє права користувачів на основі ролей, що зберігаються в базі даних.
+package rbac
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+// Ролі
+const (
+	RoleAdmin          = "admin"
+	RoleCatalogManager = "catalog_manager"
+	RoleMarketing      = "marketing"
+	RoleCustomer       = "customer"
+)
+
+// Права
+const (
+	PermProductsWrite   = "products:write"
+	PermCategoriesWrite = "categories:write"
+	PermUsersList       = "users:list"
+	PermUsersRead       = "users:read"
+	PermUsersUpdate     = "users:update"
+	PermUsersDelete     = "users:delete"
+	PermOrdersRead      = "orders:read"
+	PermOrdersManage    = "orders:manage"
+	PermDiscountsManage = "discounts:manage"
+	PermTaxManage       = "tax:manage"
+	PermShippingManage  = "shipping:manage"
+)
+
+// DefaultRole отримують користувачі, яким не призначено жодної ролі
+const DefaultRole = RoleCustomer
+
+// DefaultRoles — ролі та права, що створюють міграції
+var DefaultRoles = map[string][]string{
+	RoleAdmin:          {PermUsersList, PermUsersRead, PermUsersUpdate, PermUsersDelete, PermOrdersRead, PermOrdersManage, PermDiscountsManage, PermTaxManage, PermShippingManage},
+	RoleCatalogManager: {PermProductsWrite, PermCategoriesWrite},
+	RoleMarketing:      {PermDiscountsManage},
+	RoleCustomer:       {},
+}
+
+// Principal — автентифікований користувач разом з його ролями та правами
+type Principal struct {
+	UserID      int
+	Roles       []string
+	Permissions map[string]bool
+}
+
+// Can повідомляє, чи має користувач право
+func (p Principal) Can(permission string) bool {
+	return p.Permissions[permission]
+}
+
+// Policy завантажує ролі користувачів та перевіряє права на маршрутах
+type Policy struct {
+	Store Store
+}
+
+// Principal повертає ролі та права користувача
+func (p *Policy) Principal(ctx context.Context, userID int) (Principal, error) {
+	roles, err := p.Store.UserRoles(ctx, userID)
+	if err != nil {
+		return Principal{}, err
+	}
+	if len(roles) == 0 {
+		roles = []string{DefaultRole}
+	}
+	sort.Strings(roles)
+
+	permissions := make(map[string]bool)
+	for _, role := range roles {
+		perms, err := p.Store.RolePermissions(ctx, role)
+		if err != nil {
+			return Principal{}, err
+		}
+		for _, perm := range perms {
+			permissions[perm] = true
+		}
+	}
+	return Principal{UserID: userID, Roles: roles, Permissions: permissions}, nil
+}
+
+// Middleware додає до контексту ролі та права користувача, якого
+// автентифікував auth.Middleware. Анонімні запити пропускаються без змін.
+func (p *Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := auth.UserFrom(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, err := p.Principal(r.Context(), u.ID)
+		if err != nil {
+			log.Println("Error loading user roles:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Require пропускає лише користувачів з правом permission.
+// Анонімні запити отримують 401, користувачі без права — 403.
+func Require(permission string) func(http.Handler) http.Handler {
+	return authorize(permission, func(*http.Request, Principal) bool { return false })
+}
+
+// RequireSelfOr пропускає користувача до його власного ресурсу, ID якого
+// міститься в URL-параметрі param, а до чужих — лише з правом permission
+func RequireSelfOr(param, permission string) func(http.Handler) http.Handler {
+	return authorize(permission, func(r *http.Request, p Principal) bool {
+		id, err := strconv.Atoi(chi.URLParam(r, param))
+		return err == nil && id == p.UserID
+	})
+}
+
+func authorize(permission string, allow func(*http.Request, Principal) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := FromContext(r.Context())
+			if !ok {
+				auth.Unauthorized(w, r)
+				return
+			}
+			if !principal.Can(permission) && !allow(r, principal) {
+				problem.Write(w, r, problem.Newf(http.StatusForbidden, "permission %q is required", permission))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type contextKey struct{}
+
+// WithPrincipal повертає контекст з ролями та правами користувача
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext повертає ролі та права користувача запиту
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,118 @@
+package rbac_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/auth"
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/migrations"
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/user"
+)
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// newRouter імітує auth.Middleware: користувач береться із заголовка X-User
+func newRouter(store rbac.Store) chi.Router {
+	policy := &rbac.Policy{Store: store}
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id, err := strconv.Atoi(r.Header.Get("X-User")); err == nil {
+				r = r.WithContext(auth.WithUser(r.Context(), user.User{ID: id}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Use(policy.Middleware)
+	r.With(rbac.Require(rbac.PermProductsWrite)).Post("/products", ok)
+	r.With(rbac.Require(rbac.PermUsersList)).Get("/users", ok)
+	r.With(rbac.RequireSelfOr("id", rbac.PermUsersRead)).Get("/users/{id}", ok)
+	return r
+}
+
+func TestPolicyMemory(t *testing.T) {
+	testPolicy(t, rbac.NewMemoryStore())
+}
+
+func TestPolicySQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	d := dialect.SQLite()
+	migrator, err := migrations.NewMigrator(db, d)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up())
+
+	store := rbac.NewSQLStore(db, d)
+
+	// Ролі з міграції збігаються з DefaultRoles
+	for role, perms := range rbac.DefaultRoles {
+		got, err := store.RolePermissions(context.Background(), role)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, perms, got, role)
+	}
+
+	testPolicy(t, store)
+}
+
+func testPolicy(t *testing.T, store rbac.Store) {
+	ctx := context.Background()
+	// 1 — адміністратор, 2 — менеджер каталогу, 3 — покупець без призначених ролей
+	assert.NoError(t, store.Assign(ctx, 1, rbac.RoleAdmin))
+	assert.NoError(t, store.Assign(ctx, 2, rbac.RoleCatalogManager))
+	assert.NoError(t, store.Assign(ctx, 2, rbac.RoleCatalogManager))
+	assert.Equal(t, rbac.ErrUnknownRole, store.Assign(ctx, 3, "root"))
+
+	r := newRouter(store)
+	cases := []struct {
+		method, path, user string
+		status             int
+	}{
+		{"POST", "/products", "", http.StatusUnauthorized},
+		{"POST", "/products", "1", http.StatusForbidden},
+		{"POST", "/products", "2", http.StatusOK},
+		{"POST", "/products", "3", http.StatusForbidden},
+		{"GET", "/users", "1", http.StatusOK},
+		{"GET", "/users", "2", http.StatusForbidden},
+		{"GET", "/users", "3", http.StatusForbidden},
+		{"GET", "/users/3", "3", http.StatusOK},
+		{"GET", "/users/2", "3", http.StatusForbidden},
+		{"GET", "/users/2", "1", http.StatusOK},
+		{"GET", "/users/2", "", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("X-User", c.user)
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, c.status, rr.Code, "%s %s as %q", c.method, c.path, c.user)
+	}
+
+	// Без ролей користувач отримує роль за замовчуванням
+	principal, err := (&rbac.Policy{Store: store}).Principal(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{rbac.DefaultRole}, principal.Roles)
+
+	// Після зняття ролі право зникає
+	assert.NoError(t, store.Unassign(ctx, 2, rbac.RoleCatalogManager))
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/products", nil)
+	req.Header.Set("X-User", "2")
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), rbac.PermProductsWrite)
+}
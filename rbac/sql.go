@@ -0,0 +1,78 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+// SQLStore зберігає ролі у таблицях roles, permissions, role_permissions та user_roles
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLStore створює сховище поверх відкритого пулу з'єднань
+func NewSQLStore(db *sql.DB, d dialect.Dialect) *SQLStore {
+	return &SQLStore{DB: db, Dialect: d}
+}
+
+func (m *SQLStore) UserRoles(ctx context.Context, userID int) ([]string, error) {
+	return m.names(ctx, "SELECT r.name FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id=? ORDER BY r.name", userID)
+}
+
+func (m *SQLStore) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	return m.names(ctx, "SELECT p.name FROM role_permissions rp JOIN roles r ON r.id = rp.role_id JOIN permissions p ON p.id = rp.permission_id WHERE r.name=? ORDER BY p.name", role)
+}
+
+func (m *SQLStore) names(ctx context.Context, query string, arg interface{}) ([]string, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (m *SQLStore) roleID(ctx context.Context, role string) (int, error) {
+	var id int
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT id FROM roles WHERE name=?"), role).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrUnknownRole
+	}
+	return id, err
+}
+
+func (m *SQLStore) Assign(ctx context.Context, userID int, role string) error {
+	roleID, err := m.roleID(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	err = m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM user_roles WHERE user_id=? AND role_id=?"), userID, roleID).Scan(&n)
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = m.DB.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)"), userID, roleID)
+	return err
+}
+
+func (m *SQLStore) Unassign(ctx context.Context, userID int, role string) error {
+	roleID, err := m.roleID(ctx, role)
+	if err != nil {
+		return err
+	}
+	_, err = m.DB.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM user_roles WHERE user_id=? AND role_id=?"), userID, roleID)
+	return err
+}
@@ -0,0 +1,21 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnknownRole повертається, якщо ролі з такою назвою не існує
+var ErrUnknownRole = errors.New("rbac: unknown role")
+
+// Store описує сховище ролей і прав
+type Store interface {
+	// UserRoles повертає назви ролей, призначених користувачеві
+	UserRoles(ctx context.Context, userID int) ([]string, error)
+	// RolePermissions повертає права ролі
+	RolePermissions(ctx context.Context, role string) ([]string, error)
+	// Assign призначає роль користувачеві; повторне призначення не є помилкою
+	Assign(ctx context.Context, userID int, role string) error
+	// Unassign знімає роль з користувача
+	Unassign(ctx context.Context, userID int, role string) error
+}
@@ -0,0 +1,25 @@
+// Package render надсилає успішні JSON-відповіді.
+package render
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+// JSON кодує v і лише після успішного кодування надсилає заголовки зі статусом,
+// тож помилка кодування перетворюється на коректну відповідь 500
+func JSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Println("Error encoding JSON:", err)
+		problem.Write(w, r, problem.New(http.StatusInternalServerError, ""))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chitawebui131/shop_go/rbac"
+	"github.com/chitawebui131/shop_go/user"
+)
+
+const rolesUsage = "usage: shop_go roles grant|revoke EMAIL ROLE | roles list EMAIL"
+
+// runRoles виконує підкоманду roles: призначає, знімає та показує ролі користувача
+func runRoles(users user.UserRepository, store rbac.Store, args []string) error {
+	if len(args) < 2 {
+		return errors.New(rolesUsage)
+	}
+
+	ctx := context.Background()
+	u, err := users.GetByEmail(ctx, args[1])
+	if err == user.ErrNotFound {
+		return fmt.Errorf("user %q not found", args[1])
+	}
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "grant", "revoke":
+		if len(args) != 3 {
+			return errors.New(rolesUsage)
+		}
+		if args[0] == "grant" {
+			err = store.Assign(ctx, u.ID, args[2])
+		} else {
+			err = store.Unassign(ctx, u.ID, args[2])
+		}
+		if err == rbac.ErrUnknownRole {
+			return fmt.Errorf("unknown role %q", args[2])
+		}
+		if err != nil {
+			return err
+		}
+	case "list":
+		if len(args) != 2 {
+			return errors.New(rolesUsage)
+		}
+	default:
+		return errors.New(rolesUsage)
+	}
+
+	principal, err := (&rbac.Policy{Store: store}).Principal(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", u.Email, strings.Join(principal.Roles, ", "))
+	return nil
+}
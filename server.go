@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/chitawebui131/shop_go/config"
+	"github.com/chitawebui131/shop_go/lifecycle"
+)
+
+// serve реєструє HTTP-сервер у lc, запускає всі хуки та блокується, доки не
+// скасовано ctx або сервер не впав. Після цього запити, що виконуються,
+// отримують до cfg.ShutdownTimeout на завершення, а хуки зупиняються у зворотному порядку.
+func serve(ctx context.Context, cfg config.ServerConfig, handler http.Handler, lc *lifecycle.Lifecycle) error {
+	server := &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	lc.Append(lifecycle.Hook{
+		Name: "http",
+		Start: func(context.Context) error {
+			// Прослуховування порту відкривається синхронно, щоб помилка
+			// зайнятого порту повернулася з Start
+			ln, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Server is running on %s...\n", ln.Addr())
+			go func() {
+				if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+					serveErr <- err
+				}
+			}()
+			return nil
+		},
+		Stop: server.Shutdown,
+	})
+
+	if err := lc.Start(ctx); err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down, draining in-flight requests")
+	case runErr = <-serveErr:
+		log.Println("Server failed:", runErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(runErr, lc.Stop(shutdownCtx))
+}
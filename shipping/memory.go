@@ -0,0 +1,212 @@
+package shipping
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository зберігає зони та методи доставки у пам'яті; безпечний для
+// одночасного використання. Призначений для тестів та локальної розробки.
+type MemoryRepository struct {
+	mu           sync.Mutex
+	zones        map[int]Zone
+	methods      map[int]Method
+	nextZoneID   int
+	nextMethodID int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{zones: make(map[int]Zone), methods: make(map[int]Method), nextZoneID: 1, nextMethodID: 1}
+}
+
+// copyZone повертає зону з власною копією місць, щоб виклики не ділили пам'ять
+func copyZone(z Zone) Zone {
+	z.Locations = append([]Location{}, z.Locations...)
+	return z
+}
+
+// copyMethod повертає метод з власними копіями тарифів та порогу
+func copyMethod(m Method) Method {
+	if m.Rates != nil {
+		m.Rates = append([]WeightRate{}, m.Rates...)
+	}
+	if m.Threshold != nil {
+		threshold := *m.Threshold
+		m.Threshold = &threshold
+	}
+	return m
+}
+
+func (m *MemoryRepository) ListZones(ctx context.Context) ([]Zone, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zones := make([]Zone, 0, len(m.zones))
+	for _, z := range m.zones {
+		zones = append(zones, copyZone(z))
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ID < zones[j].ID })
+	return zones, nil
+}
+
+func (m *MemoryRepository) GetZone(ctx context.Context, id int) (Zone, error) {
+	if err := ctx.Err(); err != nil {
+		return Zone{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	z, ok := m.zones[id]
+	if !ok {
+		return Zone{}, ErrNotFound
+	}
+	return copyZone(z), nil
+}
+
+func (m *MemoryRepository) CreateZone(ctx context.Context, z *Zone) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	z.ID = m.nextZoneID
+	m.nextZoneID++
+	z.CreatedAt = now
+	z.UpdatedAt = now
+	m.zones[z.ID] = copyZone(*z)
+	return nil
+}
+
+func (m *MemoryRepository) UpdateZone(ctx context.Context, id int, z *Zone) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.zones[id]
+	if !ok {
+		return ErrNotFound
+	}
+	z.ID = id
+	z.CreatedAt = existing.CreatedAt
+	z.UpdatedAt = time.Now()
+	m.zones[id] = copyZone(*z)
+	return nil
+}
+
+func (m *MemoryRepository) DeleteZone(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.zones[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.zones, id)
+	for methodID, method := range m.methods {
+		if method.ZoneID == id {
+			delete(m.methods, methodID)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) ListMethods(ctx context.Context, zoneID int) ([]Method, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	methods := []Method{}
+	for _, method := range m.methods {
+		if zoneID == 0 || method.ZoneID == zoneID {
+			methods = append(methods, copyMethod(method))
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].ID < methods[j].ID })
+	return methods, nil
+}
+
+func (m *MemoryRepository) GetMethod(ctx context.Context, id int) (Method, error) {
+	if err := ctx.Err(); err != nil {
+		return Method{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	method, ok := m.methods[id]
+	if !ok {
+		return Method{}, ErrNotFound
+	}
+	return copyMethod(method), nil
+}
+
+func (m *MemoryRepository) CreateMethod(ctx context.Context, method *Method) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	method.ID = m.nextMethodID
+	m.nextMethodID++
+	method.CreatedAt = now
+	method.UpdatedAt = now
+	m.methods[method.ID] = copyMethod(*method)
+	return nil
+}
+
+func (m *MemoryRepository) UpdateMethod(ctx context.Context, id int, method *Method) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.methods[id]
+	if !ok {
+		return ErrNotFound
+	}
+	method.ID = id
+	method.CreatedAt = existing.CreatedAt
+	method.UpdatedAt = time.Now()
+	m.methods[id] = copyMethod(*method)
+	return nil
+}
+
+func (m *MemoryRepository) DeleteMethod(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.methods[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.methods, id)
+	return nil
+}
@@ -0,0 +1,160 @@
+package shipping
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// VolumetricDivisor перетворює об'єм упаковки в мм³ на об'ємну вагу в грамах
+// (стандарт перевізників 5000 см³ на кілограм)
+const VolumetricDivisor = 5000
+
+// Address — адреса доставки, для якої підбираються способи
+type Address struct {
+	Country  string `json:"country"`
+	Postcode string `json:"postcode"`
+}
+
+// Line — позиція відправлення: кількість, ціна та габарити одиниці продукту
+type Line struct {
+	ProductID int
+	Quantity  int
+	UnitPrice money.Money
+	// Weight у грамах; Length, Width та Height у міліметрах
+	Weight, Length, Width, Height int
+}
+
+// BillableWeight повертає вагу позиції для тарифікації: більшу з фактичної та
+// об'ємної ваги одиниці, помножену на кількість
+func BillableWeight(l Line) int {
+	volumetric := (l.Length*l.Width*l.Height + VolumetricDivisor - 1) / VolumetricDivisor
+	if volumetric > l.Weight {
+		return volumetric * l.Quantity
+	}
+	return l.Weight * l.Quantity
+}
+
+// Parcel — підсумок відправлення, від якого залежить вартість доставки
+type Parcel struct {
+	// Weight — тарифікована вага в грамах
+	Weight   int         `json:"weight"`
+	Subtotal money.Money `json:"subtotal"`
+}
+
+// NewParcel підсумовує позиції; усі ціни мають бути в одній валюті
+func NewParcel(lines []Line) (Parcel, error) {
+	var p Parcel
+	for i, l := range lines {
+		total := l.UnitPrice.Mul(int64(l.Quantity))
+		if i == 0 {
+			p.Subtotal = total
+		} else {
+			sum, err := p.Subtotal.Add(total)
+			if err != nil {
+				return Parcel{}, err
+			}
+			p.Subtotal = sum
+		}
+		p.Weight += BillableWeight(l)
+	}
+	return p, nil
+}
+
+// normalizePostcode прибирає пробіли з індексу та приводить його до верхнього регістру
+func normalizePostcode(postcode string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(postcode), ""))
+}
+
+// MatchZone повертає зону для адреси. Перемагає місце з найдовшим збігом
+// префікса індексу; за однакового збігу — зона з меншим ID. Повертає false,
+// якщо жодна зона не охоплює адресу.
+func MatchZone(zones []Zone, addr Address) (Zone, bool) {
+	var (
+		best      Zone
+		bestScore = -1
+	)
+	for _, z := range zones {
+		for _, l := range z.Locations {
+			if l.Country != addr.Country || !strings.HasPrefix(addr.Postcode, l.Postcode) {
+				continue
+			}
+			score := len(l.Postcode)
+			if score > bestScore || (score == bestScore && z.ID < best.ID) {
+				best, bestScore = z, score
+			}
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// Quote повертає вартість доставки відправлення методом m; false означає, що
+// метод недоступний (неактивний, інша валюта або вага понад найбільший тариф)
+func (m Method) Quote(p Parcel) (money.Money, bool) {
+	if !m.Active || m.Price.Currency != p.Subtotal.Currency {
+		return money.Money{}, false
+	}
+
+	switch m.Kind {
+	case KindFlatRate, KindLocalPickup:
+		return m.Price, true
+	case KindFreeOver:
+		if cmp, err := p.Subtotal.Cmp(*m.Threshold); err == nil && cmp >= 0 {
+			return money.Zero(m.Price.Currency), true
+		}
+		return m.Price, true
+	case KindWeightBased:
+		for _, rate := range m.Rates {
+			if p.Weight <= rate.MaxWeight {
+				price, err := m.Price.Add(rate.Price)
+				return price, err == nil
+			}
+		}
+	}
+	return money.Money{}, false
+}
+
+// Option — доступний спосіб доставки з ціною
+type Option struct {
+	MethodID int         `json:"methodID"`
+	Name     string      `json:"name"`
+	Kind     string      `json:"kind"`
+	Price    money.Money `json:"price"`
+}
+
+// ZoneRef — коротке посилання на зону у відповіді
+type ZoneRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Rates — результат підбору способів доставки
+type Rates struct {
+	Address Address `json:"address"`
+	// Zone — nil, якщо на адресу не доставляють
+	Zone    *ZoneRef `json:"zone"`
+	Parcel  Parcel   `json:"parcel"`
+	Methods []Option `json:"methods"`
+}
+
+// Quote підбирає способи доставки відправлення на адресу з методів зони,
+// від найдешевшого до найдорожчого
+func Quote(zone Zone, methods []Method, parcel Parcel) []Option {
+	options := []Option{}
+	for _, m := range methods {
+		if m.ZoneID != zone.ID {
+			continue
+		}
+		if price, ok := m.Quote(parcel); ok {
+			options = append(options, Option{MethodID: m.ID, Name: m.Name, Kind: m.Kind, Price: price.Round()})
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		if c := options[i].Price.Amount.Cmp(options[j].Price.Amount); c != 0 {
+			return c < 0
+		}
+		return options[i].MethodID < options[j].MethodID
+	})
+	return options
+}
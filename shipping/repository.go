@@ -0,0 +1,41 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// ErrNotFound повертається репозиторієм, якщо зони або методу не існує
+var ErrNotFound = errors.New("shipping: not found")
+
+// ShippingRepository описує сховище зон і методів доставки, з яким працює ShippingService
+type ShippingRepository interface {
+	// ListZones повертає всі зони з їхніми місцями, впорядковані за ID
+	ListZones(ctx context.Context) ([]Zone, error)
+	// GetZone повертає зону або ErrNotFound
+	GetZone(ctx context.Context, id int) (Zone, error)
+	// CreateZone зберігає нову зону та заповнює ID і дати
+	CreateZone(ctx context.Context, z *Zone) error
+	// UpdateZone замінює зону та її місця за ID; повертає ErrNotFound
+	UpdateZone(ctx context.Context, id int, z *Zone) error
+	// DeleteZone видаляє зону разом з її методами або повертає ErrNotFound
+	DeleteZone(ctx context.Context, id int) error
+
+	// ListMethods повертає методи зони, впорядковані за ID; zoneID = 0 — методи всіх зон
+	ListMethods(ctx context.Context, zoneID int) ([]Method, error)
+	// GetMethod повертає метод або ErrNotFound
+	GetMethod(ctx context.Context, id int) (Method, error)
+	// CreateMethod зберігає новий метод та заповнює ID і дати
+	CreateMethod(ctx context.Context, m *Method) error
+	// UpdateMethod замінює метод та його тарифи за ID; повертає ErrNotFound
+	UpdateMethod(ctx context.Context, id int, m *Method) error
+	// DeleteMethod видаляє метод або повертає ErrNotFound
+	DeleteMethod(ctx context.Context, id int) error
+}
+
+// ProductReader — частина products.ProductRepository, потрібна для розрахунку доставки
+type ProductReader interface {
+	Get(ctx context.Context, id int) (products.Product, error)
+}
@@ -0,0 +1,487 @@
+// Package shipping реалізує способи доставки: фіксовану ціну, тарифи за вагою,
+// безкоштовну доставку від порогу суми та самовивіз, згруповані за зонами
+// доставки (країни та префікси поштових індексів).
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Типи методів доставки
+const (
+	KindFlatRate    = "flat_rate"
+	KindWeightBased = "weight_based"
+	KindFreeOver    = "free_over"
+	KindLocalPickup = "local_pickup"
+)
+
+// Kinds — усі відомі типи методів доставки
+var Kinds = []string{KindFlatRate, KindWeightBased, KindFreeOver, KindLocalPickup}
+
+// Location — країна та, за потреби, префікс поштового індексу зони
+type Location struct {
+	Country string `json:"country"`
+	// Postcode — префікс індексу; порожній охоплює всю країну
+	Postcode string `json:"postcode"`
+}
+
+// Zone — група адрес, для яких діють однакові методи доставки
+type Zone struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Locations []Location `json:"locations"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WeightRate — ціна доставки відправлення вагою до MaxWeight грамів включно
+type WeightRate struct {
+	MaxWeight int         `json:"maxWeight"`
+	Price     money.Money `json:"price"`
+}
+
+// Method — спосіб доставки в зоні
+type Method struct {
+	ID     int    `json:"id"`
+	ZoneID int    `json:"zoneID"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	// Price — ціна для flat_rate та local_pickup, ціна нижче порогу для
+	// free_over і базова плата, що додається до тарифу, для weight_based
+	Price money.Money `json:"price"`
+	// Threshold — сума кошика, від якої free_over доставляє безкоштовно
+	Threshold *money.Money `json:"threshold,omitempty"`
+	// Rates — тарифи weight_based за зростанням ваги; важчі відправлення метод не приймає
+	Rates     []WeightRate `json:"rates,omitempty"`
+	Active    bool         `json:"active"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// normalize приводить коди країни та індекс до вигляду, в якому вони зберігаються
+func (a *Address) normalize() {
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Postcode = normalizePostcode(a.Postcode)
+}
+
+// check додає до v правила для полів зони
+func (z Zone) check(v *validate.Validator) {
+	v.Required("name", z.Name)
+	v.MaxLen("name", z.Name, 64)
+	v.Check(len(z.Locations) > 0, "locations", "must contain at least one location")
+	for i, l := range z.Locations {
+		field := "locations[" + strconv.Itoa(i) + "]"
+		v.Check(countryPattern.MatchString(l.Country), field+".country", "must be a two-letter ISO 3166-1 code")
+		v.MaxLen(field+".postcode", l.Postcode, 16)
+	}
+}
+
+// check додає до v правила для полів методу
+func (m Method) check(v *validate.Validator) {
+	v.Positive("zoneID", m.ZoneID)
+	v.Required("name", m.Name)
+	v.MaxLen("name", m.Name, 64)
+	v.Check(!m.Price.IsNegative(), "price", "must not be negative")
+	v.Check(money.ValidCurrency(m.Price.Currency), "price.currency", "must be a three-letter ISO 4217 code")
+
+	switch m.Kind {
+	case KindFreeOver:
+		v.Check(m.Threshold != nil && m.Threshold.Amount.IsPositive(), "threshold", "must be greater than zero")
+	case KindWeightBased:
+		v.Check(len(m.Rates) > 0, "rates", "must contain at least one rate")
+		for i, rate := range m.Rates {
+			field := "rates[" + strconv.Itoa(i) + "]"
+			v.Positive(field+".maxWeight", rate.MaxWeight)
+			v.Check(i == 0 || rate.MaxWeight > m.Rates[i-1].MaxWeight, field+".maxWeight", "must be unique")
+			v.Check(!rate.Price.IsNegative(), field+".price", "must not be negative")
+			v.Check(rate.Price.Currency == m.Price.Currency, field+".price.currency", "must match price.currency")
+		}
+	case KindFlatRate, KindLocalPickup:
+	default:
+		v.Check(false, "kind", "must be one of "+strings.Join(Kinds, ", "))
+	}
+	if m.Kind != KindFreeOver {
+		v.Check(m.Threshold == nil, "threshold", "is only allowed for free_over methods")
+	} else if m.Threshold != nil {
+		v.Check(m.Threshold.Currency == m.Price.Currency, "threshold.currency", "must match price.currency")
+	}
+	if m.Kind != KindWeightBased {
+		v.Check(len(m.Rates) == 0, "rates", "is only allowed for weight_based methods")
+	}
+}
+
+// ShippingService надає методи для керування зонами та методами доставки і
+// розрахунку її вартості
+type ShippingService struct {
+	Repo     ShippingRepository
+	Products ProductReader
+}
+
+// validateZone нормалізує та перевіряє поля зони
+func (s *ShippingService) validateZone(z *Zone) error {
+	z.Name = strings.TrimSpace(z.Name)
+	for i := range z.Locations {
+		addr := Address{Country: z.Locations[i].Country, Postcode: z.Locations[i].Postcode}
+		addr.normalize()
+		z.Locations[i] = Location{Country: addr.Country, Postcode: addr.Postcode}
+	}
+	v := validate.New()
+	z.check(v)
+	return v.Err()
+}
+
+// validateMethod впорядковує тарифи та перевіряє поля методу і існування його зони
+func (s *ShippingService) validateMethod(ctx context.Context, m *Method) error {
+	m.Name = strings.TrimSpace(m.Name)
+	sort.SliceStable(m.Rates, func(i, j int) bool { return m.Rates[i].MaxWeight < m.Rates[j].MaxWeight })
+
+	v := validate.New()
+	m.check(v)
+	if m.ZoneID > 0 {
+		_, err := s.Repo.GetZone(ctx, m.ZoneID)
+		if err == ErrNotFound {
+			v.Check(false, "zoneID", "zone does not exist")
+		} else if err != nil {
+			log.Println("Error querying shipping zone:", err)
+			return err
+		}
+	}
+	return v.Err()
+}
+
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
+
+// urlID повертає числовий ID з URL-параметра {id}
+func urlID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// GetZones повертає всі зони доставки
+// GET /shipping/zones
+func (s *ShippingService) GetZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := s.Repo.ListZones(r.Context())
+	if err != nil {
+		log.Println("Error querying shipping zones:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, zones)
+}
+
+// GetZone повертає зону доставки за ID
+// GET /shipping/zones/{id}
+func (s *ShippingService) GetZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	z, err := s.Repo.GetZone(r.Context(), id)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping zone %d not found", id))
+		} else {
+			log.Println("Error querying shipping zone:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, z)
+}
+
+// CreateZone додає нову зону доставки
+// POST /shipping/zones
+func (s *ShippingService) CreateZone(w http.ResponseWriter, r *http.Request) {
+	var z Zone
+	if err := validate.DecodeJSON(r, &z); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateZone(&z); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.CreateZone(r.Context(), &z); err != nil {
+		log.Println("Error inserting shipping zone:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, z)
+}
+
+// UpdateZone замінює зону доставки за ID
+// PUT /shipping/zones/{id}
+func (s *ShippingService) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var z Zone
+	if err := validate.DecodeJSON(r, &z); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateZone(&z); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.UpdateZone(r.Context(), id, &z); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping zone %d not found", id))
+		} else {
+			log.Println("Error updating shipping zone:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, z)
+}
+
+// DeleteZone видаляє зону доставки разом з її методами
+// DELETE /shipping/zones/{id}
+func (s *ShippingService) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := s.Repo.DeleteZone(r.Context(), id); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping zone %d not found", id))
+		} else {
+			log.Println("Error deleting shipping zone:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMethods повертає методи доставки, за потреби лише однієї зони
+// GET /shipping/methods?zoneID=1
+func (s *ShippingService) GetMethods(w http.ResponseWriter, r *http.Request) {
+	zoneID := 0
+	if raw := r.URL.Query().Get("zoneID"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		v := validate.New()
+		v.Check(err == nil && id > 0, "zoneID", "must be a positive integer")
+		if err := v.QueryErr(); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		zoneID = id
+	}
+
+	methods, err := s.Repo.ListMethods(r.Context(), zoneID)
+	if err != nil {
+		log.Println("Error querying shipping methods:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, methods)
+}
+
+// GetMethod повертає метод доставки за ID
+// GET /shipping/methods/{id}
+func (s *ShippingService) GetMethod(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	m, err := s.Repo.GetMethod(r.Context(), id)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping method %d not found", id))
+		} else {
+			log.Println("Error querying shipping method:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, m)
+}
+
+// CreateMethod додає новий метод доставки
+// POST /shipping/methods
+func (s *ShippingService) CreateMethod(w http.ResponseWriter, r *http.Request) {
+	var m Method
+	if err := validate.DecodeJSON(r, &m); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateMethod(r.Context(), &m); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.CreateMethod(r.Context(), &m); err != nil {
+		log.Println("Error inserting shipping method:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, m)
+}
+
+// UpdateMethod замінює метод доставки за ID
+// PUT /shipping/methods/{id}
+func (s *ShippingService) UpdateMethod(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var m Method
+	if err := validate.DecodeJSON(r, &m); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validateMethod(r.Context(), &m); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.UpdateMethod(r.Context(), id, &m); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping method %d not found", id))
+		} else {
+			log.Println("Error updating shipping method:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, m)
+}
+
+// DeleteMethod видаляє метод доставки за ID
+// DELETE /shipping/methods/{id}
+func (s *ShippingService) DeleteMethod(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := s.Repo.DeleteMethod(r.Context(), id); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "shipping method %d not found", id))
+		} else {
+			log.Println("Error deleting shipping method:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Item — продукт і кількість у запиті на розрахунок доставки
+type Item struct {
+	ProductID int `json:"productID"`
+	Quantity  int `json:"quantity"`
+}
+
+// RatesInput — тіло запиту на розрахунок доставки
+type RatesInput struct {
+	Items   []Item  `json:"items"`
+	Address Address `json:"address"`
+}
+
+// Rates повертає доступні способи доставки продуктів на адресу з цінами.
+// Якщо жодна зона не охоплює адресу, повертається порожній перелік методів.
+// POST /shipping/rates
+func (s *ShippingService) Rates(w http.ResponseWriter, r *http.Request) {
+	var input RatesInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	input.Address.normalize()
+
+	v := validate.New()
+	v.Check(len(input.Items) > 0, "items", "must contain at least one item")
+	for i, item := range input.Items {
+		field := "items[" + strconv.Itoa(i) + "]"
+		v.Positive(field+".productID", item.ProductID)
+		v.Positive(field+".quantity", item.Quantity)
+	}
+	v.Check(countryPattern.MatchString(input.Address.Country), "address.country", "must be a two-letter ISO 3166-1 code")
+	v.MaxLen("address.postcode", input.Address.Postcode, 16)
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	lines := make([]Line, 0, len(input.Items))
+	for i, item := range input.Items {
+		p, err := s.Products.Get(r.Context(), item.ProductID)
+		if err == products.ErrNotFound {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "product does not exist"}))
+			return
+		}
+		if err != nil {
+			log.Println("Error querying product:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		if len(lines) > 0 && p.Price.Currency != lines[0].UnitPrice.Currency {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "all products must be priced in the same currency"}))
+			return
+		}
+		lines = append(lines, Line{ProductID: p.ID, Quantity: item.Quantity, UnitPrice: p.Price,
+			Weight: p.Weight, Length: p.Length, Width: p.Width, Height: p.Height})
+	}
+	parcel, err := NewParcel(lines)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	zones, err := s.Repo.ListZones(r.Context())
+	if err != nil {
+		log.Println("Error querying shipping zones:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	rates := Rates{Address: input.Address, Parcel: parcel, Methods: []Option{}}
+	if zone, ok := MatchZone(zones, input.Address); ok {
+		methods, err := s.Repo.ListMethods(r.Context(), zone.ID)
+		if err != nil {
+			log.Println("Error querying shipping methods:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		rates.Zone = &ZoneRef{ID: zone.ID, Name: zone.Name}
+		rates.Methods = Quote(zone, methods, parcel)
+	}
+	render.JSON(w, r, http.StatusOK, rates)
+}
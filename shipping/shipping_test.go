@@ -0,0 +1,196 @@
+package shipping_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/apitest"
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/shipping"
+)
+
+func uah(amount string) money.Money { return money.MustParse(amount, "UAH") }
+
+func uahPtr(amount string) *money.Money {
+	m := uah(amount)
+	return &m
+}
+
+func TestBillableWeight(t *testing.T) {
+	// Фактична вага більша за об'ємну (100×100×100 мм = 200 г)
+	assert.Equal(t, 1000, shipping.BillableWeight(shipping.Line{Quantity: 2, Weight: 500, Length: 100, Width: 100, Height: 100}))
+	// Легка, але об'ємна упаковка тарифікується за об'ємом (300×200×100 мм = 1200 г)
+	assert.Equal(t, 1200, shipping.BillableWeight(shipping.Line{Quantity: 1, Weight: 100, Length: 300, Width: 200, Height: 100}))
+	assert.Equal(t, 0, shipping.BillableWeight(shipping.Line{Quantity: 3}))
+}
+
+func TestMatchZone(t *testing.T) {
+	zones := []shipping.Zone{
+		{ID: 1, Name: "Ukraine", Locations: []shipping.Location{{Country: "UA"}}},
+		{ID: 2, Name: "Kyiv", Locations: []shipping.Location{{Country: "UA", Postcode: "01"}, {Country: "UA", Postcode: "02"}}},
+		{ID: 3, Name: "Europe", Locations: []shipping.Location{{Country: "PL"}, {Country: "DE"}}},
+	}
+
+	zone, ok := shipping.MatchZone(zones, shipping.Address{Country: "UA", Postcode: "02100"})
+	assert.True(t, ok)
+	assert.Equal(t, 2, zone.ID)
+	zone, _ = shipping.MatchZone(zones, shipping.Address{Country: "UA", Postcode: "79000"})
+	assert.Equal(t, 1, zone.ID)
+	zone, _ = shipping.MatchZone(zones, shipping.Address{Country: "DE"})
+	assert.Equal(t, 3, zone.ID)
+	_, ok = shipping.MatchZone(zones, shipping.Address{Country: "US", Postcode: "01001"})
+	assert.False(t, ok)
+}
+
+func TestQuote(t *testing.T) {
+	zone := shipping.Zone{ID: 1}
+	methods := []shipping.Method{
+		{ID: 1, ZoneID: 1, Name: "Courier", Kind: shipping.KindFlatRate, Price: uah("60"), Active: true},
+		{ID: 2, ZoneID: 1, Name: "Post", Kind: shipping.KindWeightBased, Price: uah("10"), Active: true,
+			Rates: []shipping.WeightRate{{MaxWeight: 1000, Price: uah("30")}, {MaxWeight: 5000, Price: uah("70")}}},
+		{ID: 3, ZoneID: 1, Name: "Free over 100", Kind: shipping.KindFreeOver, Price: uah("50"), Threshold: uahPtr("100"), Active: true},
+		{ID: 4, ZoneID: 1, Name: "Pickup", Kind: shipping.KindLocalPickup, Price: uah("0"), Active: true},
+		{ID: 5, ZoneID: 1, Name: "Old", Kind: shipping.KindFlatRate, Price: uah("5")},
+		{ID: 6, ZoneID: 1, Name: "Euro", Kind: shipping.KindFlatRate, Price: money.MustParse("5", "EUR"), Active: true},
+	}
+
+	options := shipping.Quote(zone, methods, shipping.Parcel{Weight: 2200, Subtotal: uah("70")})
+	var got []string
+	for _, o := range options {
+		got = append(got, o.Name+" "+o.Price.String())
+	}
+	assert.Equal(t, []string{"Pickup 0.00 UAH", "Free over 100 50.00 UAH", "Courier 60.00 UAH", "Post 80.00 UAH"}, got)
+
+	// Від порогу доставка безкоштовна, а занадто важке відправлення не приймає пошта
+	options = shipping.Quote(zone, methods, shipping.Parcel{Weight: 6000, Subtotal: uah("100")})
+	got = got[:0]
+	for _, o := range options {
+		got = append(got, o.Name+" "+o.Price.String())
+	}
+	assert.Equal(t, []string{"Free over 100 0.00 UAH", "Pickup 0.00 UAH", "Courier 60.00 UAH"}, got)
+}
+
+// services повертає сервіси на репозиторіях у пам'яті та на SQLite з двома продуктами
+func services(t *testing.T) map[string]*shipping.ShippingService {
+	ctx := context.Background()
+	build := func(cats categories.CategoryRepository, p products.ProductRepository, repo shipping.ShippingRepository) *shipping.ShippingService {
+		assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+		assert.NoError(t, p.Create(ctx, &products.Product{Name: "Book", Price: uah("10.00"), StockQuantity: 5, CategoryID: 1,
+			Weight: 500, Length: 100, Width: 100, Height: 100}))
+		assert.NoError(t, p.Create(ctx, &products.Product{Name: "Lamp", Price: uah("50.00"), StockQuantity: 5, CategoryID: 1,
+			Weight: 100, Length: 300, Width: 200, Height: 100}))
+		return &shipping.ShippingService{Repo: repo, Products: p}
+	}
+
+	memCats := categories.NewMemoryRepository()
+
+	db, d := apitest.SQLite(t)
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	return map[string]*shipping.ShippingService{
+		"memory": build(memCats, products.NewMemoryRepository(memCats), shipping.NewMemoryRepository()),
+		"sqlite": build(sqlCats, products.NewSQLRepository(db, d), shipping.NewSQLRepository(db, d)),
+	}
+}
+
+func TestShippingAPI(t *testing.T) {
+	for name, svc := range services(t) {
+		t.Run(name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Post("/shipping/rates", svc.Rates)
+			r.Get("/shipping/zones/{id}", svc.GetZone)
+			r.Post("/shipping/zones", svc.CreateZone)
+			r.Put("/shipping/zones/{id}", svc.UpdateZone)
+			r.Delete("/shipping/zones/{id}", svc.DeleteZone)
+			r.Get("/shipping/methods", svc.GetMethods)
+			r.Get("/shipping/methods/{id}", svc.GetMethod)
+			r.Post("/shipping/methods", svc.CreateMethod)
+			r.Put("/shipping/methods/{id}", svc.UpdateMethod)
+
+			rr := apitest.Do(r, "POST", "/shipping/zones", `{"name":"Ukraine","locations":[{"country":"ua"}]}`)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			rr = apitest.Do(r, "POST", "/shipping/zones", `{"name":"Kyiv","locations":[{"country":"UA","postcode":"01"},{"country":"UA","postcode":"0 2"}]}`)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var kyiv shipping.Zone
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &kyiv))
+			assert.Equal(t, []shipping.Location{{Country: "UA", Postcode: "01"}, {Country: "UA", Postcode: "02"}}, kyiv.Locations)
+
+			rr = apitest.Do(r, "POST", "/shipping/zones", `{"name":"","locations":[{"country":"Ukraine"}]}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"locations[0].country"`)
+
+			kyivID := strconv.Itoa(kyiv.ID)
+			for _, body := range []string{
+				`{"zoneID":1,"name":"Nova Poshta","kind":"flat_rate","price":"90","active":true}`,
+				`{"zoneID":` + kyivID + `,"name":"Courier","kind":"flat_rate","price":"60","active":true}`,
+				`{"zoneID":` + kyivID + `,"name":"Post","kind":"weight_based","price":"10","active":true,"rates":[{"maxWeight":5000,"price":"70"},{"maxWeight":1000,"price":"30"}]}`,
+				`{"zoneID":` + kyivID + `,"name":"Free over 100","kind":"free_over","price":"50","threshold":"100","active":true}`,
+				`{"zoneID":` + kyivID + `,"name":"Pickup","kind":"local_pickup","price":"0","active":true}`,
+			} {
+				assert.Equal(t, http.StatusCreated, apitest.Do(r, "POST", "/shipping/methods", body).Code, body)
+			}
+
+			// Тарифи впорядковуються за вагою та зберігаються разом з методом
+			rr = apitest.Do(r, "GET", "/shipping/methods/3", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var post shipping.Method
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &post))
+			assert.Len(t, post.Rates, 2)
+			assert.Equal(t, 1000, post.Rates[0].MaxWeight)
+			assert.Equal(t, "30.00 UAH", post.Rates[0].Price.String())
+
+			rr = apitest.Do(r, "POST", "/shipping/methods", `{"zoneID":99,"name":"X","kind":"teleport","price":"1","threshold":"5","active":true}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			for _, field := range []string{"zoneID", "kind", "threshold"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+
+			rr = apitest.Do(r, "GET", "/shipping/methods?zoneID="+kyivID, "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var methods []shipping.Method
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &methods))
+			assert.Len(t, methods, 4)
+
+			items := `"items":[{"productID":1,"quantity":2},{"productID":2,"quantity":1}]`
+			rr = apitest.Do(r, "POST", "/shipping/rates", `{`+items+`,"address":{"country":"ua","postcode":"01 001"}}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var rates shipping.Rates
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rates))
+			assert.Equal(t, "Kyiv", rates.Zone.Name)
+			assert.Equal(t, 2200, rates.Parcel.Weight)
+			assert.Equal(t, "70.00 UAH", rates.Parcel.Subtotal.String())
+			var got []string
+			for _, o := range rates.Methods {
+				got = append(got, o.Name+" "+o.Price.String())
+			}
+			assert.Equal(t, []string{"Pickup 0.00 UAH", "Free over 100 50.00 UAH", "Courier 60.00 UAH", "Post 80.00 UAH"}, got)
+
+			rr = apitest.Do(r, "POST", "/shipping/rates", `{`+items+`,"address":{"country":"UA","postcode":"79000"}}`)
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rates))
+			assert.Equal(t, "Ukraine", rates.Zone.Name)
+			assert.Len(t, rates.Methods, 1)
+
+			// Без зони для адреси доставка недоступна, але це не помилка
+			rr = apitest.Do(r, "POST", "/shipping/rates", `{`+items+`,"address":{"country":"PL"}}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"zone":null`)
+			assert.Contains(t, rr.Body.String(), `"methods":[]`)
+
+			rr = apitest.Do(r, "POST", "/shipping/rates", `{"items":[{"productID":9,"quantity":1}],"address":{"country":"UA"}}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+			// Видалення зони прибирає і її методи
+			assert.Equal(t, http.StatusNoContent, apitest.Do(r, "DELETE", "/shipping/zones/"+kyivID, "").Code)
+			assert.Equal(t, http.StatusNotFound, apitest.Do(r, "GET", "/shipping/zones/"+kyivID, "").Code)
+			assert.Equal(t, http.StatusNotFound, apitest.Do(r, "GET", "/shipping/methods/3", "").Code)
+		})
+	}
+}
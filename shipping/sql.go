@@ -0,0 +1,424 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// SQLRepository зберігає зони у таблицях shipping_zones та shipping_zone_locations,
+// а методи — у shipping_methods та shipping_weight_rates SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+// locations повертає місця зон, згруповані за ID зони; zoneID = 0 — усіх зон
+func (m *SQLRepository) locations(ctx context.Context, zoneID int) (map[int][]Location, error) {
+	query := "SELECT zone_id, country, postcode_prefix FROM shipping_zone_locations"
+	var args []interface{}
+	if zoneID != 0 {
+		query += " WHERE zone_id = ?"
+		args = append(args, zoneID)
+	}
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byZone := make(map[int][]Location)
+	for rows.Next() {
+		var (
+			id int
+			l  Location
+		)
+		if err := rows.Scan(&id, &l.Country, &l.Postcode); err != nil {
+			return nil, err
+		}
+		byZone[id] = append(byZone[id], l)
+	}
+	return byZone, rows.Err()
+}
+
+func (m *SQLRepository) ListZones(ctx context.Context) ([]Zone, error) {
+	rows, err := m.DB.QueryContext(ctx, "SELECT id, name, created_at, updated_at FROM shipping_zones ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []Zone{}
+	for rows.Next() {
+		var z Zone
+		if err := rows.Scan(&z.ID, &z.Name, &z.CreatedAt, &z.UpdatedAt); err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	byZone, err := m.locations(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zones {
+		zones[i].Locations = append([]Location{}, byZone[zones[i].ID]...)
+	}
+	return zones, nil
+}
+
+func (m *SQLRepository) GetZone(ctx context.Context, id int) (Zone, error) {
+	var z Zone
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT id, name, created_at, updated_at FROM shipping_zones WHERE id = ?"), id).
+		Scan(&z.ID, &z.Name, &z.CreatedAt, &z.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return Zone{}, ErrNotFound
+	}
+	if err != nil {
+		return Zone{}, err
+	}
+
+	byZone, err := m.locations(ctx, id)
+	if err != nil {
+		return Zone{}, err
+	}
+	z.Locations = append([]Location{}, byZone[id]...)
+	return z, nil
+}
+
+// insertLocations записує місця зони в межах транзакції
+func (m *SQLRepository) insertLocations(ctx context.Context, tx *sql.Tx, zoneID int, locations []Location) error {
+	for _, l := range locations {
+		_, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO shipping_zone_locations (zone_id, country, postcode_prefix) VALUES (?, ?, ?)"),
+			zoneID, l.Country, l.Postcode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SQLRepository) CreateZone(ctx context.Context, z *Zone) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO shipping_zones (name, created_at, updated_at) VALUES (?, ?, ?)", z.Name, now, now)
+	if err != nil {
+		return err
+	}
+	if err := m.insertLocations(ctx, tx, int(id), z.Locations); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	z.ID = int(id)
+	z.CreatedAt = now
+	z.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) UpdateZone(ctx context.Context, id int, z *Zone) error {
+	existing, err := m.GetZone(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE shipping_zones SET name = ?, updated_at = ? WHERE id = ?"), z.Name, now, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM shipping_zone_locations WHERE zone_id = ?"), id); err != nil {
+		return err
+	}
+	if err := m.insertLocations(ctx, tx, id, z.Locations); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	z.ID = id
+	z.CreatedAt = existing.CreatedAt
+	z.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) DeleteZone(ctx context.Context, id int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM shipping_zones WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	for _, query := range []string{
+		"DELETE FROM shipping_zone_locations WHERE zone_id = ?",
+		"DELETE FROM shipping_weight_rates WHERE method_id IN (SELECT id FROM shipping_methods WHERE zone_id = ?)",
+		"DELETE FROM shipping_methods WHERE zone_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, m.Dialect.Rebind(query), id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const methodColumns = "id, zone_id, name, kind, price, threshold, currency, active, created_at, updated_at"
+
+func scanMethod(row interface{ Scan(...interface{}) error }) (Method, error) {
+	var (
+		method    Method
+		threshold decimal.NullDecimal
+	)
+	err := row.Scan(&method.ID, &method.ZoneID, &method.Name, &method.Kind, &method.Price.Amount, &threshold,
+		&method.Price.Currency, &method.Active, &method.CreatedAt, &method.UpdatedAt)
+	if err != nil {
+		return Method{}, err
+	}
+	if threshold.Valid {
+		t := money.New(threshold.Decimal, method.Price.Currency)
+		method.Threshold = &t
+	}
+	return method, nil
+}
+
+// attachRates заповнює тарифи методів за вагою; where — умова на стовпці
+// shipping_methods, якою вибрано methods
+func (m *SQLRepository) attachRates(ctx context.Context, methods []Method, where string, args ...interface{}) error {
+	index := make(map[int]*Method, len(methods))
+	for i := range methods {
+		index[methods[i].ID] = &methods[i]
+	}
+	query := `
+		SELECT shipping_weight_rates.method_id, shipping_weight_rates.max_weight, shipping_weight_rates.price
+		FROM shipping_weight_rates
+		JOIN shipping_methods ON shipping_methods.id = shipping_weight_rates.method_id
+	`
+	if where != "" {
+		query += " WHERE " + where
+	}
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query+" ORDER BY shipping_weight_rates.method_id, shipping_weight_rates.max_weight"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int
+			rate WeightRate
+		)
+		if err := rows.Scan(&id, &rate.MaxWeight, &rate.Price.Amount); err != nil {
+			return err
+		}
+		if method, ok := index[id]; ok {
+			rate.Price.Currency = method.Price.Currency
+			method.Rates = append(method.Rates, rate)
+		}
+	}
+	return rows.Err()
+}
+
+func (m *SQLRepository) ListMethods(ctx context.Context, zoneID int) ([]Method, error) {
+	var (
+		where string
+		args  []interface{}
+	)
+	if zoneID != 0 {
+		where = "shipping_methods.zone_id = ?"
+		args = append(args, zoneID)
+	}
+	query := "SELECT " + methodColumns + " FROM shipping_methods"
+	if where != "" {
+		query += " WHERE " + where
+	}
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []Method{}
+	for rows.Next() {
+		method, err := scanMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := m.attachRates(ctx, methods, where, args...); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+func (m *SQLRepository) GetMethod(ctx context.Context, id int) (Method, error) {
+	method, err := scanMethod(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+methodColumns+" FROM shipping_methods WHERE id = ?"), id))
+	if err == sql.ErrNoRows {
+		return Method{}, ErrNotFound
+	}
+	if err != nil {
+		return Method{}, err
+	}
+
+	methods := []Method{method}
+	if err := m.attachRates(ctx, methods, "shipping_methods.id = ?", id); err != nil {
+		return Method{}, err
+	}
+	return methods[0], nil
+}
+
+// insertRates записує тарифи методу в межах транзакції
+func (m *SQLRepository) insertRates(ctx context.Context, tx *sql.Tx, methodID int, rates []WeightRate) error {
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO shipping_weight_rates (method_id, max_weight, price) VALUES (?, ?, ?)"),
+			methodID, rate.MaxWeight, rate.Price.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// threshold повертає поріг безкоштовної доставки для запису в базу
+func threshold(method *Method) decimal.NullDecimal {
+	if method.Threshold == nil {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NullDecimal{Decimal: method.Threshold.Amount, Valid: true}
+}
+
+func (m *SQLRepository) CreateMethod(ctx context.Context, method *Method) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, tx, "INSERT INTO shipping_methods (zone_id, name, kind, price, threshold, currency, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		method.ZoneID, method.Name, method.Kind, method.Price.Amount, threshold(method), method.Price.Currency, method.Active, now, now)
+	if err != nil {
+		return err
+	}
+	if err := m.insertRates(ctx, tx, int(id), method.Rates); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	method.ID = int(id)
+	method.CreatedAt = now
+	method.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) UpdateMethod(ctx context.Context, id int, method *Method) error {
+	existing, err := m.GetMethod(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("UPDATE shipping_methods SET zone_id = ?, name = ?, kind = ?, price = ?, threshold = ?, currency = ?, active = ?, updated_at = ? WHERE id = ?"),
+		method.ZoneID, method.Name, method.Kind, method.Price.Amount, threshold(method), method.Price.Currency, method.Active, now, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM shipping_weight_rates WHERE method_id = ?"), id); err != nil {
+		return err
+	}
+	if err := m.insertRates(ctx, tx, id, method.Rates); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	method.ID = id
+	method.CreatedAt = existing.CreatedAt
+	method.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) DeleteMethod(ctx context.Context, id int) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM shipping_methods WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM shipping_weight_rates WHERE method_id = ?"), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
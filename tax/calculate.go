@@ -0,0 +1,151 @@
+package tax
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/money"
+)
+
+// Address — адреса доставки, від якої залежить ставка податку
+type Address struct {
+	// Country — код країни ISO 3166-1 alpha-2, наприклад "UA"
+	Country string `json:"country"`
+	// Region — область або штат; порожній, якщо не важливий
+	Region string `json:"region"`
+}
+
+// Line — позиція з ціною та категорією продукту
+type Line struct {
+	ProductID  int
+	CategoryID int
+	Quantity   int
+	UnitPrice  money.Money
+}
+
+// LineTax — податок на позицію
+type LineTax struct {
+	ProductID int             `json:"productID"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice money.Money     `json:"unitPrice"`
+	Net       money.Money     `json:"net"`
+	Tax       money.Money     `json:"tax"`
+	Gross     money.Money     `json:"gross"`
+	Rate      decimal.Decimal `json:"rate"`
+	// RuleID та TaxName описують застосоване правило; відсутні, якщо податку немає
+	RuleID  int    `json:"ruleID,omitempty"`
+	TaxName string `json:"taxName,omitempty"`
+}
+
+// Breakdown — сума податку за однією назвою та ставкою
+type Breakdown struct {
+	Name string          `json:"name"`
+	Rate decimal.Decimal `json:"rate"`
+	Net  money.Money     `json:"net"`
+	Tax  money.Money     `json:"tax"`
+}
+
+// Result — результат розрахунку податку
+type Result struct {
+	PricesIncludeTax bool        `json:"pricesIncludeTax"`
+	Address          Address     `json:"address"`
+	Lines            []LineTax   `json:"lines"`
+	Net              money.Money `json:"net"`
+	Tax              money.Money `json:"tax"`
+	Gross            money.Money `json:"gross"`
+	Breakdown        []Breakdown `json:"breakdown"`
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Calculate розраховує податок для позицій однієї валюти. Для кожної позиції
+// обирається найконкретніше правило (див. Match). Податок рахується точною
+// десятковою арифметикою та округлюється банківським округленням окремо для
+// кожної позиції; підсумки — суми округлених позицій.
+//
+// Якщо pricesIncludeTax, ціни каталогу вже містять податок і він виділяється
+// з них: net = gross / (1 + rate/100). Інакше податок додається до ціни.
+func Calculate(lines []Line, rules []Rule, addr Address, pricesIncludeTax bool) Result {
+	currency := money.DefaultCurrency
+	if len(lines) > 0 {
+		currency = lines[0].UnitPrice.Currency
+	}
+	zero := money.Zero(currency)
+
+	res := Result{
+		PricesIncludeTax: pricesIncludeTax,
+		Address:          addr,
+		Lines:            make([]LineTax, len(lines)),
+		Net:              zero,
+		Tax:              zero,
+		Gross:            zero,
+		Breakdown:        []Breakdown{},
+	}
+	for i, l := range lines {
+		lt := LineTax{ProductID: l.ProductID, Quantity: l.Quantity, UnitPrice: l.UnitPrice, Rate: decimal.Zero}
+		amount := l.UnitPrice.Mul(int64(l.Quantity))
+
+		rule, ok := Match(rules, addr, l.CategoryID)
+		if ok {
+			lt.Rate, lt.RuleID, lt.TaxName = rule.Rate, rule.ID, rule.Name
+		}
+		if pricesIncludeTax {
+			lt.Gross = amount
+			lt.Net = amount.MulDecimal(hundred.Div(hundred.Add(lt.Rate))).Round()
+			lt.Tax = money.Money{Amount: lt.Gross.Amount.Sub(lt.Net.Amount), Currency: currency}
+		} else {
+			lt.Net = amount
+			lt.Tax = amount.Percent(lt.Rate)
+			lt.Gross = money.Money{Amount: lt.Net.Amount.Add(lt.Tax.Amount), Currency: currency}
+		}
+		res.Lines[i] = lt
+
+		res.Net.Amount = res.Net.Amount.Add(lt.Net.Amount)
+		res.Tax.Amount = res.Tax.Amount.Add(lt.Tax.Amount)
+		res.Gross.Amount = res.Gross.Amount.Add(lt.Gross.Amount)
+		if ok {
+			res.addBreakdown(lt)
+		}
+	}
+	return res
+}
+
+// addBreakdown додає позицію до підсумку за назвою та ставкою податку
+func (res *Result) addBreakdown(lt LineTax) {
+	for i := range res.Breakdown {
+		b := &res.Breakdown[i]
+		if b.Name == lt.TaxName && b.Rate.Equal(lt.Rate) {
+			b.Net.Amount = b.Net.Amount.Add(lt.Net.Amount)
+			b.Tax.Amount = b.Tax.Amount.Add(lt.Tax.Amount)
+			return
+		}
+	}
+	res.Breakdown = append(res.Breakdown, Breakdown{Name: lt.TaxName, Rate: lt.Rate, Net: lt.Net, Tax: lt.Tax})
+}
+
+// Match повертає найконкретніше правило для адреси та категорії: правило
+// області важливіше за правило категорії, а правило з обома умовами — за
+// будь-яке з них. Повертає false, якщо для країни немає жодного правила.
+func Match(rules []Rule, addr Address, categoryID int) (Rule, bool) {
+	var (
+		best  Rule
+		score = -1
+	)
+	for _, r := range rules {
+		if r.Country != addr.Country ||
+			r.Region != "" && r.Region != addr.Region ||
+			r.CategoryID != nil && *r.CategoryID != categoryID {
+			continue
+		}
+		s := 0
+		if r.Region != "" {
+			s += 2
+		}
+		if r.CategoryID != nil {
+			s++
+		}
+		if s > score {
+			best, score = r, s
+		}
+	}
+	return best, score >= 0
+}
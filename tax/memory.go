@@ -0,0 +1,154 @@
+package tax
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository зберігає податкові правила у пам'яті; безпечний для
+// одночасного використання. Призначений для тестів та локальної розробки.
+type MemoryRepository struct {
+	mu     sync.Mutex
+	rules  map[int]Rule
+	nextID int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{rules: make(map[int]Rule), nextID: 1}
+}
+
+// categoryKey повертає категорію правила як число; 0 означає всі категорії
+func categoryKey(r Rule) int {
+	if r.CategoryID == nil {
+		return 0
+	}
+	return *r.CategoryID
+}
+
+// sorted повертає правила, що задовольняють keep, у порядку List; викликається під m.mu
+func (m *MemoryRepository) sorted(keep func(Rule) bool) []Rule {
+	rules := []Rule{}
+	for _, r := range m.rules {
+		if keep(r) {
+			rules = append(rules, r)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return categoryKey(a) < categoryKey(b)
+	})
+	return rules
+}
+
+func (m *MemoryRepository) List(ctx context.Context) ([]Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sorted(func(Rule) bool { return true }), nil
+}
+
+func (m *MemoryRepository) ListByCountry(ctx context.Context, country string) ([]Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sorted(func(r Rule) bool { return r.Country == country }), nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return Rule{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.rules[id]
+	if !ok {
+		return Rule{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// duplicate повідомляє, чи існує інше правило з тими ж умовами; викликається під m.mu
+func (m *MemoryRepository) duplicate(r *Rule, exceptID int) bool {
+	for id, existing := range m.rules {
+		if id != exceptID && existing.Country == r.Country && existing.Region == r.Region && categoryKey(existing) == categoryKey(*r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, r *Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.duplicate(r, 0) {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	r.ID = m.nextID
+	m.nextID++
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	m.rules[r.ID] = *r
+	return nil
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, id int, r *Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.rules[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.duplicate(r, id) {
+		return ErrDuplicate
+	}
+	r.ID = id
+	r.CreatedAt = existing.CreatedAt
+	r.UpdatedAt = time.Now()
+	m.rules[id] = *r
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.rules, id)
+	return nil
+}
@@ -0,0 +1,42 @@
+package tax
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/products"
+)
+
+// ErrNotFound повертається репозиторієм, якщо правила не існує
+var ErrNotFound = errors.New("tax: not found")
+
+// ErrDuplicate повертається репозиторієм, якщо правило для тієї ж країни,
+// області та категорії вже існує
+var ErrDuplicate = errors.New("tax: rule for this country, region and category already exists")
+
+// RuleRepository описує сховище податкових правил, з яким працює TaxService
+type RuleRepository interface {
+	// List повертає всі правила, впорядковані за країною, областю та категорією
+	List(ctx context.Context) ([]Rule, error)
+	// ListByCountry повертає правила країни
+	ListByCountry(ctx context.Context, country string) ([]Rule, error)
+	// Get повертає правило або ErrNotFound
+	Get(ctx context.Context, id int) (Rule, error)
+	// Create зберігає нове правило та заповнює ID і дати; повертає ErrDuplicate
+	Create(ctx context.Context, r *Rule) error
+	// Update замінює правило за ID; повертає ErrNotFound або ErrDuplicate
+	Update(ctx context.Context, id int, r *Rule) error
+	// Delete видаляє правило або повертає ErrNotFound
+	Delete(ctx context.Context, id int) error
+}
+
+// ProductReader — частина products.ProductRepository, потрібна для розрахунку податку
+type ProductReader interface {
+	Get(ctx context.Context, id int) (products.Product, error)
+}
+
+// CategoryReader — частина categories.CategoryRepository, потрібна для перевірки правил
+type CategoryReader interface {
+	Get(ctx context.Context, id int) (categories.Category, error)
+}
@@ -0,0 +1,139 @@
+package tax
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+)
+
+// SQLRepository зберігає податкові правила у таблиці tax_rules SQL-бази даних.
+// Правило для всіх категорій зберігається з category_id = 0, щоб унікальний
+// індекс (country, region, category_id) однаково працював в усіх діалектах.
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+const ruleColumns = "id, name, country, region, category_id, rate, created_at, updated_at"
+
+func scanRule(row interface{ Scan(...interface{}) error }) (Rule, error) {
+	var (
+		r          Rule
+		categoryID int
+	)
+	if err := row.Scan(&r.ID, &r.Name, &r.Country, &r.Region, &categoryID, &r.Rate, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return Rule{}, err
+	}
+	if categoryID != 0 {
+		r.CategoryID = &categoryID
+	}
+	return r, nil
+}
+
+func (m *SQLRepository) query(ctx context.Context, query string, args ...interface{}) ([]Rule, error) {
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []Rule{}
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+func (m *SQLRepository) List(ctx context.Context) ([]Rule, error) {
+	return m.query(ctx, "SELECT "+ruleColumns+" FROM tax_rules ORDER BY country, region, category_id")
+}
+
+func (m *SQLRepository) ListByCountry(ctx context.Context, country string) ([]Rule, error) {
+	return m.query(ctx, "SELECT "+ruleColumns+" FROM tax_rules WHERE country = ? ORDER BY region, category_id", country)
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (Rule, error) {
+	r, err := scanRule(m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT "+ruleColumns+" FROM tax_rules WHERE id = ?"), id))
+	if err == sql.ErrNoRows {
+		return Rule{}, ErrNotFound
+	}
+	return r, err
+}
+
+// duplicate повідомляє, чи існує інше правило з тими ж умовами
+func (m *SQLRepository) duplicate(ctx context.Context, r *Rule, exceptID int) (bool, error) {
+	var n int
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT COUNT(*) FROM tax_rules WHERE country = ? AND region = ? AND category_id = ? AND id <> ?"),
+		r.Country, r.Region, categoryKey(*r), exceptID).Scan(&n)
+	return n > 0, err
+}
+
+func (m *SQLRepository) Create(ctx context.Context, r *Rule) error {
+	if dup, err := m.duplicate(ctx, r, 0); err != nil || dup {
+		if dup {
+			return ErrDuplicate
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	id, err := m.Dialect.InsertID(ctx, m.DB, "INSERT INTO tax_rules (name, country, region, category_id, rate, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		r.Name, r.Country, r.Region, categoryKey(*r), r.Rate, now, now)
+	if err != nil {
+		return err
+	}
+	r.ID = int(id)
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Update(ctx context.Context, id int, r *Rule) error {
+	existing, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if dup, err := m.duplicate(ctx, r, id); err != nil || dup {
+		if dup {
+			return ErrDuplicate
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE tax_rules SET name = ?, country = ?, region = ?, category_id = ?, rate = ?, updated_at = ? WHERE id = ?"),
+		r.Name, r.Country, r.Region, categoryKey(*r), r.Rate, now, id)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	r.CreatedAt = existing.CreatedAt
+	r.UpdatedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id int) error {
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM tax_rules WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+// Package tax розраховує податки (ПДВ) за правилами, що залежать від
+// категорії продукту та країни і області доставки.
+package tax
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/shopspring/decimal"
+
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
+)
+
+// Rule — ставка податку для країни, за потреби уточнена областю та категорією
+type Rule struct {
+	ID int `json:"id"`
+	// Name — назва податку в розрахунку, наприклад "VAT"
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	// Region порожній для правила всієї країни
+	Region string `json:"region"`
+	// CategoryID обмежує правило категорією; nil — усі категорії
+	CategoryID *int `json:"categoryID,omitempty"`
+	// Rate — ставка у відсотках, наприклад 20
+	Rate      decimal.Decimal `json:"rate"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// normalize приводить коди країни та області до верхнього регістру
+func (a *Address) normalize() {
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Region = strings.ToUpper(strings.TrimSpace(a.Region))
+}
+
+// check додає до v правила для адреси; prefix — префікс назв полів
+func (a Address) check(v *validate.Validator, prefix string) {
+	v.Check(countryPattern.MatchString(a.Country), prefix+"country", "must be a two-letter ISO 3166-1 code")
+	v.MaxLen(prefix+"region", a.Region, 64)
+}
+
+// check додає до v правила для полів правила
+func (r Rule) check(v *validate.Validator) {
+	v.Required("name", r.Name)
+	v.MaxLen("name", r.Name, 64)
+	Address{Country: r.Country, Region: r.Region}.check(v, "")
+	v.Check(!r.Rate.IsNegative() && r.Rate.LessThanOrEqual(hundred), "rate", "must be between 0 and 100")
+	if r.CategoryID != nil {
+		v.Positive("categoryID", *r.CategoryID)
+	}
+}
+
+// TaxService надає методи для керування податковими правилами та розрахунку податку
+type TaxService struct {
+	Repo     RuleRepository
+	Products ProductReader
+	// Categories використовується для перевірки існування категорії; nil вимикає перевірку
+	Categories CategoryReader
+	// PricesIncludeTax — ціни каталогу вже містять податок
+	PricesIncludeTax bool
+}
+
+// validate нормалізує коди та перевіряє поля правила і існування його категорії
+func (s *TaxService) validate(ctx context.Context, r *Rule) error {
+	addr := Address{Country: r.Country, Region: r.Region}
+	addr.normalize()
+	r.Country, r.Region = addr.Country, addr.Region
+	r.Name = strings.TrimSpace(r.Name)
+
+	v := validate.New()
+	r.check(v)
+	if s.Categories != nil && r.CategoryID != nil && *r.CategoryID > 0 {
+		_, err := s.Categories.Get(ctx, *r.CategoryID)
+		if err == categories.ErrNotFound {
+			v.Check(false, "categoryID", "category does not exist")
+		} else if err != nil {
+			log.Println("Error querying category:", err)
+			return err
+		}
+	}
+	return v.Err()
+}
+
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
+
+var errDuplicate = validate.Failed(problem.FieldError{Field: "country", Message: "rule for this country, region and category already exists"})
+
+// ruleID повертає числовий ID з URL-параметра {id}
+func ruleID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// GetRules повертає всі податкові правила
+// GET /tax/rules
+func (s *TaxService) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.Repo.List(r.Context())
+	if err != nil {
+		log.Println("Error querying tax rules:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, rules)
+}
+
+// GetRule повертає податкове правило за ID
+// GET /tax/rules/{id}
+func (s *TaxService) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	rule, err := s.Repo.Get(r.Context(), id)
+	if err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "tax rule %d not found", id))
+		} else {
+			log.Println("Error querying tax rule:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, rule)
+}
+
+// CreateRule додає нове податкове правило
+// POST /tax/rules
+func (s *TaxService) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var rule Rule
+	if err := validate.DecodeJSON(r, &rule); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), &rule); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.Create(r.Context(), &rule); err != nil {
+		if err == ErrDuplicate {
+			problem.Write(w, r, errDuplicate)
+		} else {
+			log.Println("Error inserting tax rule:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusCreated, rule)
+}
+
+// UpdateRule замінює податкове правило за ID
+// PUT /tax/rules/{id}
+func (s *TaxService) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	var rule Rule
+	if err := validate.DecodeJSON(r, &rule); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := s.validate(r.Context(), &rule); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := s.Repo.Update(r.Context(), id, &rule); err != nil {
+		switch err {
+		case ErrNotFound:
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "tax rule %d not found", id))
+		case ErrDuplicate:
+			problem.Write(w, r, errDuplicate)
+		default:
+			log.Println("Error updating tax rule:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	render.JSON(w, r, http.StatusOK, rule)
+}
+
+// DeleteRule видаляє податкове правило за ID
+// DELETE /tax/rules/{id}
+func (s *TaxService) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
+		return
+	}
+
+	if err := s.Repo.Delete(r.Context(), id); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "tax rule %d not found", id))
+		} else {
+			log.Println("Error deleting tax rule:", err)
+			problem.Error(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Item — продукт і кількість у запиті на розрахунок податку
+type Item struct {
+	ProductID int `json:"productID"`
+	Quantity  int `json:"quantity"`
+}
+
+// CalculateInput — тіло запиту на розрахунок податку
+type CalculateInput struct {
+	Items   []Item  `json:"items"`
+	Address Address `json:"address"`
+}
+
+// Calculate розраховує податок для продуктів з доставкою на адресу
+// POST /tax/calculate
+func (s *TaxService) Calculate(w http.ResponseWriter, r *http.Request) {
+	var input CalculateInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	input.Address.normalize()
+
+	v := validate.New()
+	v.Check(len(input.Items) > 0, "items", "must contain at least one item")
+	for i, item := range input.Items {
+		field := "items[" + strconv.Itoa(i) + "]"
+		v.Positive(field+".productID", item.ProductID)
+		v.Positive(field+".quantity", item.Quantity)
+	}
+	input.Address.check(v, "address.")
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	lines := make([]Line, 0, len(input.Items))
+	for i, item := range input.Items {
+		p, err := s.Products.Get(r.Context(), item.ProductID)
+		if err == products.ErrNotFound {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "product does not exist"}))
+			return
+		}
+		if err != nil {
+			log.Println("Error querying product:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		if len(lines) > 0 && p.Price.Currency != lines[0].UnitPrice.Currency {
+			problem.Write(w, r, validate.Failed(problem.FieldError{Field: fmt.Sprintf("items[%d].productID", i), Message: "all products must be priced in the same currency"}))
+			return
+		}
+		lines = append(lines, Line{ProductID: p.ID, CategoryID: p.CategoryID, Quantity: item.Quantity, UnitPrice: p.Price})
+	}
+
+	rules, err := s.Repo.ListByCountry(r.Context(), input.Address.Country)
+	if err != nil {
+		log.Println("Error querying tax rules:", err)
+		problem.Error(w, r, err)
+		return
+	}
+	render.JSON(w, r, http.StatusOK, Calculate(lines, rules, input.Address, s.PricesIncludeTax))
+}
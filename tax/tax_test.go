@@ -0,0 +1,165 @@
+package tax_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/apitest"
+	"github.com/chitawebui131/shop_go/categories"
+	"github.com/chitawebui131/shop_go/money"
+	"github.com/chitawebui131/shop_go/products"
+	"github.com/chitawebui131/shop_go/tax"
+)
+
+func intPtr(n int) *int { return &n }
+
+var (
+	lines = []tax.Line{
+		{ProductID: 1, CategoryID: 1, Quantity: 3, UnitPrice: money.MustParse("10.00", "UAH")},
+		{ProductID: 2, CategoryID: 2, Quantity: 1, UnitPrice: money.MustParse("33.33", "UAH")},
+	}
+	rules = []tax.Rule{
+		{ID: 1, Name: "VAT", Country: "UA", Rate: decimal.NewFromInt(20)},
+		{ID: 2, Name: "VAT", Country: "UA", CategoryID: intPtr(2), Rate: decimal.NewFromInt(7)},
+		{ID: 3, Name: "City VAT", Country: "UA", Region: "KYIV", Rate: decimal.NewFromInt(10)},
+		{ID: 4, Name: "City VAT", Country: "UA", Region: "KYIV", CategoryID: intPtr(1), Rate: decimal.NewFromInt(5)},
+	}
+)
+
+func TestCalculateExclusive(t *testing.T) {
+	res := tax.Calculate(lines, rules, tax.Address{Country: "UA"}, false)
+
+	assert.Equal(t, "30.00 UAH", res.Lines[0].Net.String())
+	assert.Equal(t, "6.00 UAH", res.Lines[0].Tax.String())
+	assert.Equal(t, 1, res.Lines[0].RuleID)
+	// 7% від 33.33 = 2.3331, округлюється до копійок
+	assert.Equal(t, "2.33 UAH", res.Lines[1].Tax.String())
+	assert.Equal(t, "35.66 UAH", res.Lines[1].Gross.String())
+	assert.Equal(t, 2, res.Lines[1].RuleID)
+
+	assert.Equal(t, "63.33 UAH", res.Net.String())
+	assert.Equal(t, "8.33 UAH", res.Tax.String())
+	assert.Equal(t, "71.66 UAH", res.Gross.String())
+	assert.Len(t, res.Breakdown, 2)
+	assert.Equal(t, "6.00 UAH", res.Breakdown[0].Tax.String())
+}
+
+func TestCalculateInclusive(t *testing.T) {
+	res := tax.Calculate(lines, rules, tax.Address{Country: "UA"}, true)
+
+	assert.Equal(t, "30.00 UAH", res.Lines[0].Gross.String())
+	assert.Equal(t, "25.00 UAH", res.Lines[0].Net.String())
+	assert.Equal(t, "5.00 UAH", res.Lines[0].Tax.String())
+	// 33.33 / 1.07 = 31.1495..., податок — різниця, тож net + tax завжди дорівнює ціні
+	assert.Equal(t, "31.15 UAH", res.Lines[1].Net.String())
+	assert.Equal(t, "2.18 UAH", res.Lines[1].Tax.String())
+	assert.Equal(t, "63.33 UAH", res.Gross.String())
+	assert.Equal(t, "7.18 UAH", res.Tax.String())
+}
+
+func TestMatch(t *testing.T) {
+	kyiv := tax.Address{Country: "UA", Region: "KYIV"}
+	rule, ok := tax.Match(rules, kyiv, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 4, rule.ID)
+	// Правило області важливіше за правило категорії
+	rule, _ = tax.Match(rules, kyiv, 2)
+	assert.Equal(t, 3, rule.ID)
+	rule, _ = tax.Match(rules, tax.Address{Country: "UA", Region: "LVIV"}, 3)
+	assert.Equal(t, 1, rule.ID)
+
+	_, ok = tax.Match(rules, tax.Address{Country: "DE"}, 1)
+	assert.False(t, ok)
+	res := tax.Calculate(lines, rules, tax.Address{Country: "DE"}, false)
+	assert.True(t, res.Tax.IsZero())
+	assert.Empty(t, res.Breakdown)
+}
+
+// services повертає сервіси на репозиторіях у пам'яті та на SQLite з двома продуктами
+func services(t *testing.T) map[string]*tax.TaxService {
+	ctx := context.Background()
+	build := func(cats categories.CategoryRepository, p products.ProductRepository, repo tax.RuleRepository) *tax.TaxService {
+		assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Electronics"}))
+		assert.NoError(t, cats.Create(ctx, &categories.Category{Name: "Books"}))
+		for _, l := range lines {
+			assert.NoError(t, p.Create(ctx, &products.Product{Name: "P", Price: l.UnitPrice, StockQuantity: 5, CategoryID: l.CategoryID}))
+		}
+		return &tax.TaxService{Repo: repo, Products: p, Categories: cats}
+	}
+
+	memCats := categories.NewMemoryRepository()
+
+	db, d := apitest.SQLite(t)
+	sqlCats := categories.NewSQLRepository(db, d)
+
+	return map[string]*tax.TaxService{
+		"memory": build(memCats, products.NewMemoryRepository(memCats), tax.NewMemoryRepository()),
+		"sqlite": build(sqlCats, products.NewSQLRepository(db, d), tax.NewSQLRepository(db, d)),
+	}
+}
+
+func TestTaxAPI(t *testing.T) {
+	for name, svc := range services(t) {
+		t.Run(name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Post("/tax/calculate", svc.Calculate)
+			r.Get("/tax/rules", svc.GetRules)
+			r.Get("/tax/rules/{id}", svc.GetRule)
+			r.Post("/tax/rules", svc.CreateRule)
+			r.Put("/tax/rules/{id}", svc.UpdateRule)
+			r.Delete("/tax/rules/{id}", svc.DeleteRule)
+
+			rr := apitest.Do(r, "POST", "/tax/rules", `{"name":"VAT","country":"ua","rate":"20"}`)
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			var vat tax.Rule
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vat))
+			assert.Equal(t, "UA", vat.Country)
+			assert.Equal(t, http.StatusCreated, apitest.Do(r, "POST", "/tax/rules", `{"name":"VAT","country":"UA","categoryID":2,"rate":"7"}`).Code)
+
+			// Ті самі умови вдруге не додаються; некоректні поля відхиляються разом
+			assert.Equal(t, http.StatusUnprocessableEntity, apitest.Do(r, "POST", "/tax/rules", `{"name":"VAT","country":"UA","rate":"25"}`).Code)
+			rr = apitest.Do(r, "POST", "/tax/rules", `{"name":"","country":"Ukraine","rate":"120","categoryID":9}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			for _, field := range []string{"name", "country", "rate", "categoryID"} {
+				assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+			}
+
+			rr = apitest.Do(r, "GET", "/tax/rules", "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var list []tax.Rule
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			assert.Len(t, list, 2)
+			assert.Nil(t, list[0].CategoryID)
+			assert.Equal(t, 2, *list[1].CategoryID)
+
+			rr = apitest.Do(r, "POST", "/tax/calculate", `{"items":[{"productID":1,"quantity":3},{"productID":2,"quantity":1}],"address":{"country":"ua"}}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var res tax.Result
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, "8.33 UAH", res.Tax.String())
+			assert.Equal(t, "71.66 UAH", res.Gross.String())
+
+			rr = apitest.Do(r, "POST", "/tax/calculate", `{"items":[{"productID":1,"quantity":0}],"address":{"country":""}}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"field":"address.country"`)
+			assert.Contains(t, rr.Body.String(), `"field":"items[0].quantity"`)
+
+			id := strconv.Itoa(vat.ID)
+			rr = apitest.Do(r, "PUT", "/tax/rules/"+id, `{"name":"VAT","country":"UA","rate":"21.5"}`)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			got, err := svc.Repo.Get(context.Background(), vat.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "21.5", got.Rate.String())
+
+			assert.Equal(t, http.StatusNoContent, apitest.Do(r, "DELETE", "/tax/rules/"+id, "").Code)
+			assert.Equal(t, http.StatusNotFound, apitest.Do(r, "GET", "/tax/rules/"+id, "").Code)
+		})
+	}
+}
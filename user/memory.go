@@ -0,0 +1,168 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// MemoryRepository зберігає користувачів у пам'яті; безпечний для одночасного використання.
+// Призначений для тестів та локальної розробки без бази даних.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	users  map[int]User
+	nextID int
+}
+
+// NewMemoryRepository створює порожній репозиторій у пам'яті
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{users: make(map[int]User), nextID: 1}
+}
+
+func (m *MemoryRepository) List(ctx context.Context, q pagination.Query) ([]User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]int, 0, len(m.users))
+	for id := range m.users {
+		ids = append(ids, id)
+	}
+
+	users := []User{}
+	for _, id := range pagination.SelectIDs(ids, q) {
+		users = append(users, m.users[id])
+	}
+	return users, nil
+}
+
+func (m *MemoryRepository) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.users), nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, u *User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.emailTaken(u.Email, 0) {
+		return ErrEmailTaken
+	}
+	now := time.Now()
+	u.ID = m.nextID
+	u.CreatedAt = now
+	u.ModifiedAt = now
+	m.users[u.ID] = *u
+	m.nextID++
+	return nil
+}
+
+// emailTaken повідомляє, чи належить email іншому користувачу; викликається під m.mu
+func (m *MemoryRepository) emailTaken(email string, exceptID int) bool {
+	for _, user := range m.users {
+		if user.Email == email && user.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, id int, u *User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.emailTaken(u.Email, id) {
+		return ErrEmailTaken
+	}
+	u.ID = id
+	u.CreatedAt = old.CreatedAt
+	u.ModifiedAt = time.Now()
+	m.users[id] = *u
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+func (m *MemoryRepository) GetByEmail(ctx context.Context, email string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (m *MemoryRepository) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.PasswordHash = hash
+	m.users[id] = user
+	return nil
+}
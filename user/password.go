@@ -0,0 +1,74 @@
+package user
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials повертається, якщо email або пароль не збігаються
+var ErrInvalidCredentials = errors.New("user: invalid credentials")
+
+// Hasher хешує паролі за допомогою bcrypt
+type Hasher struct {
+	// Cost — вартість bcrypt; 0 означає bcrypt.DefaultCost
+	Cost int
+}
+
+func (h Hasher) cost() int {
+	if h.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return h.Cost
+}
+
+// Hash повертає bcrypt-хеш пароля
+func (h Hasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify перевіряє пароль за збереженим значенням. needsRehash повідомляє, що
+// значення слід перехешувати: воно збережене відкритим текстом (до запровадження
+// хешування) або з вартістю, що відрізняється від поточної.
+func (h Hasher) Verify(stored, password string) (ok, needsRehash bool) {
+	if !IsHashed(stored) {
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err != nil || cost != h.cost()
+}
+
+// IsHashed повідомляє, чи є значення bcrypt-хешем, а не відкритим паролем
+func IsHashed(stored string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(stored, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash []byte
+)
+
+// dummyVerify витрачає стільки ж часу, скільки перевірка справжнього пароля
+func (h Hasher) dummyVerify(password string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), h.cost())
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// ErrNotFound повертається репозиторієм, якщо користувача з таким ID не існує
+var ErrNotFound = errors.New("user: not found")
+
+// ErrEmailTaken повертається репозиторієм, якщо email уже належить іншому користувачу
+var ErrEmailTaken = errors.New("user: email already taken")
+
+// UserRepository описує сховище користувачів, з яким працює UserService
+type UserRepository interface {
+	// List повертає до q.Limit+1 користувачів після межі курсора, впорядкованих
+	// за ID (для зворотного курсора — перед межею, за спаданням ID)
+	List(ctx context.Context, q pagination.Query) ([]User, error)
+	// Count повертає кількість усіх користувачів
+	Count(ctx context.Context) (int, error)
+	// Get повертає користувача за ID або ErrNotFound
+	Get(ctx context.Context, id int) (User, error)
+	// Create зберігає нового користувача та заповнює ID і дати; повертає ErrEmailTaken
+	Create(ctx context.Context, u *User) error
+	// Update перезаписує дані користувача за ID; повертає ErrNotFound або ErrEmailTaken
+	Update(ctx context.Context, id int, u *User) error
+	// Delete видаляє користувача за ID або повертає ErrNotFound
+	Delete(ctx context.Context, id int) error
+	// GetByEmail повертає користувача за email або ErrNotFound
+	GetByEmail(ctx context.Context, email string) (User, error)
+	// SetPasswordHash замінює збережений хеш пароля користувача
+	SetPasswordHash(ctx context.Context, id int, hash string) error
+}
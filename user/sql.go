@@ -0,0 +1,148 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/chitawebui131/shop_go/dialect"
+	"github.com/chitawebui131/shop_go/pagination"
+)
+
+// SQLRepository зберігає користувачів у таблиці users SQL-бази даних
+type SQLRepository struct {
+	DB      *sql.DB
+	Dialect dialect.Dialect
+}
+
+// NewSQLRepository створює репозиторій поверх відкритого пулу з'єднань
+func NewSQLRepository(db *sql.DB, d dialect.Dialect) *SQLRepository {
+	return &SQLRepository{DB: db, Dialect: d}
+}
+
+func (m *SQLRepository) List(ctx context.Context, q pagination.Query) ([]User, error) {
+	key := []pagination.Column{{Expr: "id"}}
+	query := "SELECT * FROM users"
+	var args []interface{}
+	if q.Cursor != nil {
+		var where string
+		where, args = pagination.Keyset(key, []interface{}{q.Cursor.ID}, q.Backward())
+		query += " WHERE " + where
+	}
+	query += " ORDER BY " + pagination.OrderBy(key, q.Backward()) + " LIMIT ?"
+	args = append(args, q.Limit+1)
+
+	rows, err := m.DB.QueryContext(ctx, m.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.ModifiedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (m *SQLRepository) Count(ctx context.Context) (int, error) {
+	var n int
+	err := m.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&n)
+	return n, err
+}
+
+func (m *SQLRepository) Get(ctx context.Context, id int) (User, error) {
+	var user User
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT * FROM users WHERE id=?"), id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.ModifiedAt)
+	if err == sql.ErrNoRows {
+		return User{}, ErrNotFound
+	}
+	return user, err
+}
+
+func (m *SQLRepository) Create(ctx context.Context, u *User) error {
+	now := time.Now()
+	userID, err := m.Dialect.InsertID(ctx, m.DB, "INSERT INTO users (first_name, last_name, email, password, created_at, modified_at) VALUES (?, ?, ?, ?, ?, ?)",
+		u.FirstName, u.LastName, u.Email, u.PasswordHash, now, now)
+	// Унікальність email гарантує індекс users_email_unique, тож одночасні
+	// реєстрації з однаковим email не проходять обидві
+	if m.Dialect.IsUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	// Отримання повнішої інформації про новоствореного користувача
+	created, err := m.Get(ctx, int(userID))
+	if err != nil {
+		return err
+	}
+	*u = created
+	return nil
+}
+
+func (m *SQLRepository) Update(ctx context.Context, id int, u *User) error {
+	// Перевірка, чи існує користувач за вказаним ID
+	if _, err := m.Get(ctx, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE users SET first_name=?, last_name=?, email=?, password=?, modified_at=? WHERE id=?"),
+		u.FirstName, u.LastName, u.Email, u.PasswordHash, now, id)
+	if m.Dialect.IsUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+	u.ID = id
+	u.ModifiedAt = now
+	return nil
+}
+
+func (m *SQLRepository) Delete(ctx context.Context, id int) error {
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("DELETE FROM users WHERE id=?"), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *SQLRepository) GetByEmail(ctx context.Context, email string) (User, error) {
+	var user User
+	err := m.DB.QueryRowContext(ctx, m.Dialect.Rebind("SELECT * FROM users WHERE email=?"), email).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.ModifiedAt)
+	if err == sql.ErrNoRows {
+		return User{}, ErrNotFound
+	}
+	return user, err
+}
+
+func (m *SQLRepository) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	result, err := m.DB.ExecContext(ctx, m.Dialect.Rebind("UPDATE users SET password=? WHERE id=?"), hash, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
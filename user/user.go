@@ -1,244 +1,392 @@
 package user
 
 import (
-	"database/sql"
-	"encoding/json"
+	"context"
 	"log"
 	"net/http"
-	"time"
 	s "strconv"
+	"time"
 
 	"github.com/go-chi/chi"
-	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/chitawebui131/shop_go/pagination"
+	"github.com/chitawebui131/shop_go/patch"
+	"github.com/chitawebui131/shop_go/problem"
+	"github.com/chitawebui131/shop_go/render"
+	"github.com/chitawebui131/shop_go/validate"
 )
 
-// User представляє структуру користувача
+// User представляє структуру користувача.
+// Хеш пароля ніколи не потрапляє у JSON-відповіді.
 type User struct {
 	ID           int       `json:"id"`
 	FirstName    string    `json:"first_name"`
 	LastName     string    `json:"last_name"`
 	Email        string    `json:"email"`
-	Password     string    `json:"password"`
+	PasswordHash string    `json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 	ModifiedAt   time.Time `json:"modified_at"`
 }
 
+// UserInput представляє дані користувача з тіла запиту на створення чи оновлення
+type UserInput struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+// Validate перевіряє поля користувача перед збереженням
+func (in UserInput) Validate() error {
+	v := validate.New()
+	in.checkProfile(v)
+	in.checkPassword(v)
+	return v.Err()
+}
+
+func (in UserInput) checkProfile(v *validate.Validator) {
+	v.Required("first_name", in.FirstName)
+	v.MaxLen("first_name", in.FirstName, 255)
+	v.Required("last_name", in.LastName)
+	v.MaxLen("last_name", in.LastName, 255)
+	v.Email("email", in.Email)
+	v.MaxLen("email", in.Email, 255)
+}
+
+func (in UserInput) checkPassword(v *validate.Validator) {
+	v.MinLen("password", in.Password, 8)
+	// bcrypt враховує лише перші 72 байти пароля
+	v.Check(len(in.Password) <= 72, "password", "must be at most 72 bytes")
+}
+
 // UserService надає методи для роботи з користувачами
 type UserService struct {
-	DB *sql.DB
+	Repo   UserRepository
+	Hasher Hasher
 }
 
-// GetUsers повертає список усіх користувачів з пагінацією
-func (s *UserService) GetUsers(w http.ResponseWriter, r *http.Request) {
-	// Отримання значень параметрів пагінації
-	page := getQueryParamInt(r, "page", 1)
-	limit := getQueryParamInt(r, "limit", 10)
-
-	// Розрахунок зсуву (offset) для пагінації
-	offset := (page - 1) * limit
+// toUser хешує пароль та повертає користувача для збереження
+func (s *UserService) toUser(in UserInput) (User, error) {
+	hash, err := s.Hasher.Hash(in.Password)
+	if err != nil {
+		return User{}, err
+	}
+	return User{
+		FirstName:    in.FirstName,
+		LastName:     in.LastName,
+		Email:        in.Email,
+		PasswordHash: hash,
+	}, nil
+}
 
-	// Вибірка користувачів з бази даних з пагінацією
-	rows, err := s.DB.Query("SELECT * FROM users LIMIT ? OFFSET ?", limit, offset)
+// Authenticate повертає користувача з email, якщо пароль правильний, або
+// ErrInvalidCredentials. Паролі, збережені відкритим текстом чи з застарілою
+// вартістю bcrypt, прозоро перехешовуються.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (User, error) {
+	user, err := s.Repo.GetByEmail(ctx, email)
+	if err == ErrNotFound {
+		// Хешування все одно виконується, щоб час відповіді не видавав наявність email
+		s.Hasher.dummyVerify(password)
+		return User{}, ErrInvalidCredentials
+	}
 	if err != nil {
-		log.Println("Error querying database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return User{}, err
 	}
-	defer rows.Close()
 
-	// Створення слайсу для зберігання результатів
-	var users []User
+	ok, needsRehash := s.Hasher.Verify(user.PasswordHash, password)
+	if !ok {
+		return User{}, ErrInvalidCredentials
+	}
+	if needsRehash {
+		if hash, err := s.Hasher.Hash(password); err != nil {
+			log.Println("Error rehashing password:", err)
+		} else if err := s.Repo.SetPasswordHash(ctx, user.ID, hash); err != nil {
+			log.Println("Error storing rehashed password:", err)
+		} else {
+			user.PasswordHash = hash
+		}
+	}
+	return user, nil
+}
 
-	// Зчитування результатів запиту
-	for rows.Next() {
-		var user User
-		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.CreatedAt, &user.ModifiedAt); err != nil {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+// HashPlaintextPasswords хешує паролі, що досі зберігаються відкритим текстом,
+// і повертає кількість оновлених користувачів
+func HashPlaintextPasswords(ctx context.Context, repo UserRepository, hasher Hasher) (int, error) {
+	const batch = 100
+	updated := 0
+	q := pagination.Query{Limit: batch}
+	for {
+		users, err := repo.List(ctx, q)
+		if err != nil {
+			return updated, err
 		}
-		users = append(users, user)
+		more := len(users) > batch
+		if more {
+			users = users[:batch]
+		}
+		for _, u := range users {
+			if IsHashed(u.PasswordHash) {
+				continue
+			}
+			hash, err := hasher.Hash(u.PasswordHash)
+			if err != nil {
+				return updated, err
+			}
+			if err := repo.SetPasswordHash(ctx, u.ID, hash); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		if !more {
+			return updated, nil
+		}
+		q.Cursor = &pagination.Cursor{ID: users[len(users)-1].ID}
 	}
+}
 
-	// Перевірка наявності помилок під час зчитування
-	if err := rows.Err(); err != nil {
-		log.Println("Error reading rows:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+// GetUsers повертає сторінку користувачів, впорядкованих за ID.
+// Сторінки гортаються курсорами page.next та page.prev, які також передаються
+// в заголовку Link; total=true додає загальну кількість користувачів.
+// GET /users?limit=10&cursor=...&total=true
+func (s *UserService) GetUsers(w http.ResponseWriter, r *http.Request) {
+	// Отримання значень параметрів пагінації
+	v := validate.New()
+	q := pagination.Parse(r.URL.Query(), v, "")
+	if err := v.QueryErr(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(users); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	// Вибірка користувачів зі сховища з пагінацією
+	users, err := s.Repo.List(r.Context(), q)
+	if err != nil {
+		log.Println("Error querying users:", err)
+		problem.Error(w, r, err)
 		return
 	}
+	var list pagination.List[User]
+	list.Data, list.Page = pagination.Build(users, q, func(u User) pagination.Cursor {
+		return pagination.Cursor{ID: u.ID}
+	})
+
+	if q.Total {
+		total, err := s.Repo.Count(r.Context())
+		if err != nil {
+			log.Println("Error counting users:", err)
+			problem.Error(w, r, err)
+			return
+		}
+		list.Page.Total = &total
+	}
+
+	// Відправлення відповіді у форматі JSON
+	pagination.SetLink(w, r, list.Page)
+	render.JSON(w, r, http.StatusOK, list)
 }
 
 // GetUser повертає інформацію про конкретного користувача за ID
 func (s *UserService) GetUser(w http.ResponseWriter, r *http.Request) {
 	// Отримання ID користувача з URL-параметра
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	userID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Вибірка конкретного користувача з бази даних за ID (з параметром)
-	row := s.DB.QueryRow("SELECT * FROM users WHERE id=?", userID)
-
-	// Створення змінної для зберігання результатів
-	var user User
-
-	// Зчитування результатів запиту
-	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.CreatedAt, &user.ModifiedAt)
+	// Вибірка конкретного користувача зі сховища за ID
+	user, err := s.Repo.Get(r.Context(), userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusNotFound)
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "user %d not found", userID))
 		} else {
-			log.Println("Error scanning row:", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("Error querying user:", err)
+			problem.Error(w, r, err)
 		}
 		return
 	}
 
 	// Відправлення відповіді у форматі JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Кодуємо та виводимо дані у відповідь
-	if err := json.NewEncoder(w).Encode(user); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	render.JSON(w, r, http.StatusOK, user)
 }
 
-// Create
+// CreateUser додає нового користувача
 func (s *UserService) CreateUser(w http.ResponseWriter, r *http.Request) {
-	var newUser User
-	if err := json.NewDecoder(r.Body).Decode(&newUser); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
+	var input UserInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-
-	// Додавання нового користувача до бази даних
-	result, err := s.DB.Exec("INSERT INTO users (first_name, last_name, email, password, created_at, modified_at) VALUES (?, ?, ?, ?, ?, ?)",
-		newUser.FirstName, newUser.LastName, newUser.Email, newUser.Password, time.Now(), time.Now())
-	if err != nil {
-		log.Println("Error inserting user into database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	if err := input.Validate(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Отримання ID новоствореного користувача
-	userID, err := result.LastInsertId()
+	newUser, err := s.toUser(input)
 	if err != nil {
-		log.Println("Error getting last insert ID:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error hashing password:", err)
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Отримання повнішої інформації про новоствореного користувача
-	newUser.ID = int(userID)
-	err = s.DB.QueryRow("SELECT * FROM users WHERE id=?", userID).Scan(&newUser.ID, &newUser.FirstName, &newUser.LastName, &newUser.Email, &newUser.Password, &newUser.CreatedAt, &newUser.ModifiedAt)
-	if err != nil {
-		log.Println("Error querying new user:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	// Додавання нового користувача до сховища
+	if err := s.Repo.Create(r.Context(), &newUser); err != nil {
+		if err == ErrEmailTaken {
+			problem.Write(w, r, errEmailTaken)
+		} else {
+			log.Println("Error inserting user:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
 	// Відправлення відповіді у форматі JSON з повною інформацією про нового користувача
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(newUser); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	render.JSON(w, r, http.StatusCreated, newUser)
 }
 
-// UpdateUser
+// UpdateUser оновлює інформацію про користувача за ID
 func (s *UserService) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// Отримання ID користувача з URL-параметра
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	userID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Отримання старої інформації про користувача
-	var oldUser User
-	err := s.DB.QueryRow("SELECT * FROM users WHERE id=?", userID).Scan(&oldUser.ID, &oldUser.FirstName, &oldUser.LastName, &oldUser.Email, &oldUser.Password, &oldUser.CreatedAt, &oldUser.ModifiedAt)
+	// Отримання нових даних про користувача з тіла запиту (JSON)
+	var input UserInput
+	if err := validate.DecodeJSON(r, &input); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	updatedUser, err := s.toUser(input)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusNotFound)
+		log.Println("Error hashing password:", err)
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Оновлення інформації про користувача у сховищі
+	if err := s.Repo.Update(r.Context(), userID, &updatedUser); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "user %d not found", userID))
+		} else if err == ErrEmailTaken {
+			problem.Write(w, r, errEmailTaken)
 		} else {
-			log.Println("Error querying user:", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("Error updating user:", err)
+			problem.Error(w, r, err)
 		}
 		return
 	}
 
-	// Отримання нових даних про користувача з тіла запиту (JSON)
-	var updatedUser User
-	if err := json.NewDecoder(r.Body).Decode(&updatedUser); err != nil {
-		log.Println("Error decoding JSON:", err)
-		w.WriteHeader(http.StatusBadRequest)
+	// Відправлення відповіді у форматі JSON з оновленою інформацією про користувача
+	render.JSON(w, r, http.StatusOK, updatedUser)
+}
+
+// profile — документ, до якого застосовується PATCH користувача. Пароль у ньому
+// відсутній: патч може лише задати новий пароль, який буде захешовано.
+type profile struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+// PatchUser частково оновлює користувача: тіло запиту (merge patch або JSON
+// Patch) застосовується до імені та email, тож поля, яких немає в патчі, не
+// змінюються, а пароль лишається попереднім, якщо новий не задано
+func (s *UserService) PatchUser(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID користувача з URL-параметра
+	userID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
 
-	// Оновлення інформації про користувача в базі даних
-	_, err = s.DB.Exec("UPDATE users SET first_name=?, last_name=?, email=?, password=?, modified_at=? WHERE id=?",
-		updatedUser.FirstName, updatedUser.LastName, updatedUser.Email, updatedUser.Password, time.Now(), userID)
+	current, err := s.Repo.Get(r.Context(), userID)
 	if err != nil {
-		log.Println("Error updating user in database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "user %d not found", userID))
+		} else {
+			log.Println("Error querying user:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
-	// Відправлення відповіді у форматі JSON з оновленою інформацією про користувача
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(updatedUser); err != nil {
-		log.Println("Error encoding JSON:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+	var input UserInput
+	doc := profile{FirstName: current.FirstName, LastName: current.LastName, Email: current.Email}
+	if err := patch.Decode(r, doc, &input); err != nil {
+		patch.Error(w, r, err)
+		return
+	}
+	v := validate.New()
+	input.checkProfile(v)
+	if input.Password != "" {
+		input.checkPassword(v)
+	}
+	if err := v.Err(); err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-}
 
-// DeleteUser видаляє користувача за ID
-func (s *UserService) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	// Отримання ID користувача з URL-параметра
-	userID := chi.URLParam(r, "id")
-	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	updatedUser := User{
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		Email:        input.Email,
+		PasswordHash: current.PasswordHash,
+	}
+	if input.Password != "" {
+		if updatedUser, err = s.toUser(input); err != nil {
+			log.Println("Error hashing password:", err)
+			problem.Error(w, r, err)
+			return
+		}
+	}
+
+	if err := s.Repo.Update(r.Context(), userID, &updatedUser); err != nil {
+		if err == ErrNotFound {
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "user %d not found", userID))
+		} else if err == ErrEmailTaken {
+			problem.Write(w, r, errEmailTaken)
+		} else {
+			log.Println("Error updating user:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
-	// Видалення користувача з бази даних за ID
-	result, err := s.DB.Exec("DELETE FROM users WHERE id=?", userID)
+	// Повертається збережений стан, а не декодований патч
+	stored, err := s.Repo.Get(r.Context(), userID)
 	if err != nil {
-		log.Println("Error deleting user from database:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("Error querying user:", err)
+		problem.Error(w, r, err)
 		return
 	}
+	render.JSON(w, r, http.StatusOK, stored)
+}
 
-	// Перевірка, чи існує користувач за вказаним ID
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		w.WriteHeader(http.StatusInternalServerError)
+// DeleteUser видаляє користувача за ID
+func (s *UserService) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	// Отримання ID користувача з URL-параметра
+	userID, ok := getURLParamID(r)
+	if !ok {
+		problem.Write(w, r, errInvalidID)
 		return
 	}
-	if rowsAffected == 0 {
-		// Якщо немає відповідного користувача, відправити HTTP статус 404 (Not Found)
-		w.WriteHeader(http.StatusNotFound)
+
+	// Видалення користувача зі сховища за ID
+	if err := s.Repo.Delete(r.Context(), userID); err != nil {
+		if err == ErrNotFound {
+			// Якщо немає відповідного користувача, відправити HTTP статус 404 (Not Found)
+			problem.Write(w, r, problem.Newf(http.StatusNotFound, "user %d not found", userID))
+		} else {
+			log.Println("Error deleting user:", err)
+			problem.Error(w, r, err)
+		}
 		return
 	}
 
@@ -246,18 +394,17 @@ func (s *UserService) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Введення допоміжної функції для отримання значення параметра запиту як ціле число
-func getQueryParamInt(r *http.Request, key string, defaultValue int) int {
-	value := r.URL.Query().Get(key)
-	if value == "" {
-		return defaultValue
-	}
+// errEmailTaken повертається, якщо email уже належить іншому користувачу
+var errEmailTaken = problem.New(http.StatusConflict, "user with this email already exists")
 
-	result, err := s.Atoi(value)
-	if err != nil {
-		return defaultValue
-	}
+// errInvalidID повертається, якщо {id} не є додатним цілим числом
+var errInvalidID = problem.New(http.StatusBadRequest, "id must be a positive integer")
 
-	return result
+// getURLParamID повертає числовий ID з URL-параметра {id}
+func getURLParamID(r *http.Request) (int, bool) {
+	id, err := s.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
 }
-
@@ -1,43 +1,30 @@
 package user_test
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
-	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 
 	// Імпорт вашого пакету user та інших необхідних залежностей
+	"github.com/chitawebui131/shop_go/apitest"
+	"github.com/chitawebui131/shop_go/pagination"
 	"github.com/chitawebui131/shop_go/user"
 )
 
-// DBQueryer описує метод Query бази даних
-type DBQueryer interface {
-	Query(query string, args ...interface{}) (*sql.Rows, error)
-}
-
-// MockDB - мок бази даних, що реалізує інтерфейс DBQueryer
-type MockDB struct {
-	mock.Mock
-}
-
-func (m *MockDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	// Мокання методу Query
-	argsMock := m.Called(query, args)
-	return argsMock.Get(0).(*sql.Rows), argsMock.Error(1)
-}
-
 func TestGetUsers(t *testing.T) {
-	// Створення інстанції мок-бази даних
-	mockDB := new(MockDB)
+	// Створення сховища в пам'яті з одним користувачем
+	repo := user.NewMemoryRepository()
+	assert.NoError(t, repo.Create(context.Background(), &user.User{FirstName: "John", LastName: "Doe", Email: "john@example.com", PasswordHash: "password"}))
 
-	// Створення інстанції UserService з мок-базою даних
-	userService := &user.UserService{DB: mockDB}
+	// Створення інстанції UserService зі сховищем у пам'яті
+	userService := &user.UserService{Repo: repo}
 
 	// Параметри тестового запиту
 	req, err := http.NewRequest("GET", "/users", nil)
@@ -46,11 +33,6 @@ func TestGetUsers(t *testing.T) {
 	// Використання httptest для створення запису відповіді
 	rr := httptest.NewRecorder()
 
-	// Мокання методу Query бази даних
-	rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "created_at", "modified_at"}).
-		AddRow(1, "John", "Doe", "john@example.com", "password", time.Now(), time.Now())
-	mockDB.On("Query", mock.Anything, mock.Anything).Return(rows, nil)
-
 	// Виклик функції обробки HTTP-запиту
 	userService.GetUsers(rr, req)
 
@@ -58,14 +40,189 @@ func TestGetUsers(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 
 	// Розкодування JSON та перевірка результатів
-	var users []user.User
-	err = json.Unmarshal(rr.Body.Bytes(), &users)
+	var list pagination.List[user.User]
+	err = json.Unmarshal(rr.Body.Bytes(), &list)
 	assert.NoError(t, err)
+	users := list.Data
 	assert.Len(t, users, 1)
+	assert.Empty(t, list.Page.Next)
 	assert.Equal(t, 1, users[0].ID)
 	assert.Equal(t, "John", users[0].FirstName)
-	// Додайте інші перевірки за необхідності
 
-	// Перевірка викликів моканого методу Query
-	mockDB.AssertExpectations(t)
+	// Пароль чи його хеш ніколи не повертаються
+	assert.NotContains(t, rr.Body.String(), "password")
+}
+
+func TestUserNotFound(t *testing.T) {
+	userService := &user.UserService{Repo: user.NewMemoryRepository()}
+
+	r := chi.NewRouter()
+	r.Get("/users/{id}", userService.GetUser)
+	r.Delete("/users/{id}", userService.DeleteUser)
+
+	for _, method := range []string{"GET", "DELETE"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, "/users/42", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, method)
+	}
+
+	// Нечисловий ID є помилкою клієнта
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/users/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetUsersDeadlineExceeded(t *testing.T) {
+	userService := &user.UserService{Repo: user.NewMemoryRepository()}
+
+	// Контекст, граничний час якого вже сплив
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/users", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	userService.GetUsers(rr, req)
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+}
+
+func TestCreateUserHashesPassword(t *testing.T) {
+	repo := user.NewMemoryRepository()
+	userService := &user.UserService{Repo: repo, Hasher: user.Hasher{Cost: bcrypt.MinCost}}
+
+	body := `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"s3cret-pass"}`
+	rr := httptest.NewRecorder()
+	userService.CreateUser(rr, httptest.NewRequest("POST", "/users", strings.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "s3cret-pass")
+	assert.NotContains(t, rr.Body.String(), "password")
+
+	stored, err := repo.GetByEmail(context.Background(), "jane@example.com")
+	assert.NoError(t, err)
+	assert.True(t, user.IsHashed(stored.PasswordHash))
+
+	_, err = userService.Authenticate(context.Background(), "jane@example.com", "s3cret-pass")
+	assert.NoError(t, err)
+	_, err = userService.Authenticate(context.Background(), "jane@example.com", "wrong")
+	assert.Equal(t, user.ErrInvalidCredentials, err)
+}
+
+func TestAuthenticateRehashes(t *testing.T) {
+	ctx := context.Background()
+	repo := user.NewMemoryRepository()
+	assert.NoError(t, repo.Create(ctx, &user.User{Email: "legacy@example.com", PasswordHash: "plaintext"}))
+
+	// Відкритий пароль хешується під час першого входу
+	userService := &user.UserService{Repo: repo, Hasher: user.Hasher{Cost: bcrypt.MinCost}}
+	_, err := userService.Authenticate(ctx, "legacy@example.com", "plaintext")
+	assert.NoError(t, err)
+	stored, _ := repo.GetByEmail(ctx, "legacy@example.com")
+	cost, err := bcrypt.Cost([]byte(stored.PasswordHash))
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, cost)
+
+	// Зміна вартості призводить до перехешування
+	userService.Hasher.Cost = bcrypt.MinCost + 1
+	_, err = userService.Authenticate(ctx, "legacy@example.com", "plaintext")
+	assert.NoError(t, err)
+	stored, _ = repo.GetByEmail(ctx, "legacy@example.com")
+	cost, _ = bcrypt.Cost([]byte(stored.PasswordHash))
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+}
+
+func TestHashPlaintextPasswords(t *testing.T) {
+	ctx := context.Background()
+	repo := user.NewMemoryRepository()
+	hasher := user.Hasher{Cost: bcrypt.MinCost}
+	hashed, _ := hasher.Hash("already")
+	assert.NoError(t, repo.Create(ctx, &user.User{Email: "a@example.com", PasswordHash: "plain"}))
+	assert.NoError(t, repo.Create(ctx, &user.User{Email: "b@example.com", PasswordHash: hashed}))
+
+	n, err := user.HashPlaintextPasswords(ctx, repo, hasher)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, _ := repo.GetByEmail(ctx, "a@example.com")
+	ok, _ := hasher.Verify(stored.PasswordHash, "plain")
+	assert.True(t, ok)
+}
+
+func TestPatchUser(t *testing.T) {
+	ctx := context.Background()
+	repo := user.NewMemoryRepository()
+	userService := &user.UserService{Repo: repo, Hasher: user.Hasher{Cost: bcrypt.MinCost}}
+	r := chi.NewRouter()
+	r.Post("/users", userService.CreateUser)
+	r.Patch("/users/{id}", userService.PatchUser)
+
+	do := func(body, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/users/1", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/users", strings.NewReader(`{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"s3cret-pass"}`)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	// Пароль не змінюється, якщо його немає в патчі
+	rr = do(`{"last_name":"Smith"}`, "application/merge-patch+json")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var patched user.User
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &patched))
+	assert.Equal(t, "Jane", patched.FirstName)
+	assert.Equal(t, "Smith", patched.LastName)
+	assert.NotContains(t, rr.Body.String(), "password")
+	_, err := userService.Authenticate(ctx, "jane@example.com", "s3cret-pass")
+	assert.NoError(t, err)
+
+	rr = do(`[{"op":"replace","path":"/email","value":"jane@example.org"},{"op":"add","path":"/password","value":"n3w-secret"}]`, "application/json-patch+json")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	_, err = userService.Authenticate(ctx, "jane@example.org", "n3w-secret")
+	assert.NoError(t, err)
+
+	rr = do(`{"password":"short","email":"nope"}`, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"password"`)
+	assert.Contains(t, rr.Body.String(), `"field":"email"`)
+	rr = do(`[{"op":"test","path":"/first_name","value":"John"}]`, "application/json-patch+json")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	stored, err := repo.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.org", stored.Email)
+}
+
+func TestDuplicateEmail(t *testing.T) {
+	db, d := apitest.SQLite(t)
+
+	for name, repo := range map[string]user.UserRepository{
+		"memory": user.NewMemoryRepository(),
+		"sqlite": user.NewSQLRepository(db, d),
+	} {
+		t.Run(name, func(t *testing.T) {
+			userService := &user.UserService{Repo: repo, Hasher: user.Hasher{Cost: bcrypt.MinCost}}
+			r := chi.NewRouter()
+			r.Post("/users", userService.CreateUser)
+			r.Put("/users/{id}", userService.UpdateUser)
+			r.Patch("/users/{id}", userService.PatchUser)
+
+			assert.Equal(t, http.StatusCreated, apitest.Do(r, "POST", "/users", `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"s3cret-pass"}`).Code)
+			assert.Equal(t, http.StatusCreated, apitest.Do(r, "POST", "/users", `{"first_name":"John","last_name":"Doe","email":"john@example.com","password":"s3cret-pass"}`).Code)
+
+			rr := apitest.Do(r, "POST", "/users", `{"first_name":"Jane","last_name":"Roe","email":"jane@example.com","password":"s3cret-pass"}`)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Contains(t, rr.Body.String(), "user with this email already exists")
+			assert.Equal(t, http.StatusConflict, apitest.Do(r, "PUT", "/users/2", `{"first_name":"John","last_name":"Doe","email":"jane@example.com","password":"s3cret-pass"}`).Code)
+
+			assert.Equal(t, http.StatusConflict, apitest.Do(r, "PATCH", "/users/2", `{"email":"jane@example.com"}`).Code)
+
+			// Власний email не вважається зайнятим
+			assert.Equal(t, http.StatusOK, apitest.Do(r, "PUT", "/users/1", `{"first_name":"Janet","last_name":"Doe","email":"jane@example.com","password":"s3cret-pass"}`).Code)
+			assert.Equal(t, http.StatusOK, apitest.Do(r, "PATCH", "/users/1", `{"email":"jane@example.com","first_name":"Jane"}`).Code)
+		})
+	}
 }
@@ -0,0 +1,183 @@
+// Package validate містить перевірку вхідних даних запитів: декодування JSON
+// з обмеженням розміру та відхиленням невідомих полів, а також Validator
+// для декларативного опису правил для полів.
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+// ValidationType — тип проблеми для помилок перевірки полів
+const ValidationType = "/problems/validation-error"
+
+// Validator накопичує помилки полів
+type Validator struct {
+	errors []problem.FieldError
+}
+
+// New створює порожній Validator
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check додає помилку message для поля field, якщо ok хибне
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.errors = append(v.errors, problem.FieldError{Field: field, Message: message})
+	}
+}
+
+// Required перевіряє, що рядок не порожній
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "must not be empty")
+}
+
+// MaxLen перевіряє, що рядок містить не більше max символів
+func (v *Validator) MaxLen(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// MinLen перевіряє, що рядок містить щонайменше min символів
+func (v *Validator) MinLen(field, value string, min int) {
+	v.Check(utf8.RuneCountInString(value) >= min, field, fmt.Sprintf("must be at least %d characters", min))
+}
+
+// NonNegative перевіряє, що число не від'ємне
+func (v *Validator) NonNegative(field string, value float64) {
+	v.Check(value >= 0, field, "must not be negative")
+}
+
+// Positive перевіряє, що ціле число більше нуля
+func (v *Validator) Positive(field string, value int) {
+	v.Check(value > 0, field, "must be greater than zero")
+}
+
+// Email перевіряє, що рядок є простою адресою електронної пошти без імені
+func (v *Validator) Email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	v.Check(err == nil && addr.Address == value, field, "must be a valid email address")
+}
+
+// Valid повідомляє, чи не знайдено помилок
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// Err повертає проблему 422 зі списком помилок полів або nil
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return Failed(v.errors...)
+}
+
+// QueryErr повертає проблему 400 зі списком помилок параметрів рядка запиту або nil
+func (v *Validator) QueryErr() error {
+	if v.Valid() {
+		return nil
+	}
+	p := problem.New(http.StatusBadRequest, "invalid query parameters")
+	p.Type = ValidationType
+	p.Errors = v.errors
+	return p
+}
+
+// Failed створює проблему 422 (Unprocessable Entity) для помилок полів
+func Failed(errs ...problem.FieldError) *problem.Problem {
+	p := problem.New(http.StatusUnprocessableEntity, "request body failed validation")
+	p.Type = ValidationType
+	p.Errors = errs
+	return p
+}
+
+// bodyKey — ключ контексту з необмеженим тілом запиту для OverrideBodyLimit
+type bodyKey struct{}
+
+// LimitBody обмежує розмір тіла запиту maxBytes байтами; DecodeJSON
+// перетворює перевищення на відповідь 413
+func LimitBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes > 0 && r.Body != nil {
+				r = r.WithContext(context.WithValue(r.Context(), bodyKey{}, r.Body))
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OverrideBodyLimit замінює загальне обмеження LimitBody для окремих маршрутів,
+// наприклад завантаження файлів. Тіло не повинно читатися до цього middleware.
+func OverrideBodyLimit(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if body, ok := r.Context().Value(bodyKey{}).(io.ReadCloser); ok {
+				r.Body = http.MaxBytesReader(w, body, maxBytes)
+			} else if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DecodeJSON декодує тіло запиту в dst, відхиляючи невідомі поля та зайві дані після
+// JSON-об'єкта. Повертає *problem.Problem: 400 для некоректного JSON, 413 для
+// завеликого тіла та 422 для невідомих полів або полів неправильного типу.
+func DecodeJSON(r *http.Request, dst interface{}) error {
+	return decodeFrom(r.Body, dst)
+}
+
+// Unmarshal декодує data в dst за тими ж правилами, що й DecodeJSON. Потрібен,
+// коли JSON-документ зібрано на сервері, наприклад після застосування PATCH.
+func Unmarshal(data []byte, dst interface{}) error {
+	return decodeFrom(bytes.NewReader(data), dst)
+}
+
+func decodeFrom(body io.Reader, dst interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeProblem(err)
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return problem.New(http.StatusBadRequest, "request body must contain a single JSON object")
+	}
+	return nil
+}
+
+func decodeProblem(err error) *problem.Problem {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return problem.Newf(http.StatusRequestEntityTooLarge, "request body must not exceed %d bytes", maxBytesErr.Limit)
+	case errors.As(err, &syntaxErr):
+		return problem.Newf(http.StatusBadRequest, "malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return problem.New(http.StatusBadRequest, "request body must be a JSON object")
+	case errors.As(err, &typeErr):
+		return Failed(problem.FieldError{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не має окремого типу для цієї помилки
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return Failed(problem.FieldError{Field: field, Message: "unknown field"})
+	default:
+		return problem.Newf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chitawebui131/shop_go/problem"
+)
+
+type payload struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+func decode(body string, limit int64) error {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	var got payload
+	var err error
+	LimitBody(limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = DecodeJSON(r, &got)
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	return err
+}
+
+func statusOf(t *testing.T, err error) int {
+	p, ok := err.(*problem.Problem)
+	assert.True(t, ok, "expected *problem.Problem, got %T", err)
+	return p.Status
+}
+
+func TestDecodeJSON(t *testing.T) {
+	assert.NoError(t, decode(`{"name":"Go","price":1}`, 1024))
+
+	assert.Equal(t, http.StatusBadRequest, statusOf(t, decode(`{"name":`, 1024)))
+	assert.Equal(t, http.StatusBadRequest, statusOf(t, decode(`{"name":"a"} {}`, 1024)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(t, decode(`{"name":"`+strings.Repeat("x", 100)+`"}`, 16)))
+
+	err := decode(`{"name":"Go","colour":"red"}`, 1024)
+	assert.Equal(t, http.StatusUnprocessableEntity, statusOf(t, err))
+	assert.Equal(t, "colour", err.(*problem.Problem).Errors[0].Field)
+
+	err = decode(`{"price":"ten"}`, 1024)
+	assert.Equal(t, http.StatusUnprocessableEntity, statusOf(t, err))
+	assert.Equal(t, "price", err.(*problem.Problem).Errors[0].Field)
+}
+
+func TestOverrideBodyLimit(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("x", 100) + `"}`
+	var err error
+	handler := LimitBody(16)(OverrideBodyLimit(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got payload
+		err = DecodeJSON(r, &got)
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	assert.NoError(t, err)
+
+	// Обмеження можна й зменшити
+	handler = LimitBody(1024)(OverrideBodyLimit(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got payload
+		err = DecodeJSON(r, &got)
+	})))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, statusOf(t, err))
+}
+
+func TestValidator(t *testing.T) {
+	v := New()
+	v.Required("name", " ")
+	v.NonNegative("price", -1)
+	v.Email("email", "John <john@example.com>")
+	v.Email("backup_email", "john@example.com")
+
+	err := v.Err()
+	assert.Equal(t, http.StatusUnprocessableEntity, statusOf(t, err))
+
+	var fields []string
+	for _, fe := range err.(*problem.Problem).Errors {
+		fields = append(fields, fe.Field)
+	}
+	assert.Equal(t, []string{"name", "price", "email"}, fields)
+}